@@ -0,0 +1,182 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-cinch/common/jwt"
+	"github.com/go-cinch/common/middleware/requestid"
+	"github.com/go-cinch/common/utils/diff"
+	"github.com/go-cinch/common/worker"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+// Action is one operation to be audited, Before/After can be any json object(nil for create/delete),
+// Entry.Changes is filled with field level diff if they are the same struct type
+type Action struct {
+	// Actor is jwt user code of ctx if empty
+	Actor      string
+	Resource   string
+	ResourceId string
+	Verb       string
+	Before     interface{}
+	After      interface{}
+	// Ip is client ip of ctx if empty
+	Ip       string
+	Metadata map[string]string
+}
+
+// Entry is the enriched audit log written to sink
+type Entry struct {
+	Id         uint64            `json:"id" gorm:"primaryKey;autoIncrement"`
+	Actor      string            `json:"actor" gorm:"size:100;index"`
+	Tenant     string            `json:"tenant" gorm:"size:100;index"`
+	Resource   string            `json:"resource" gorm:"size:100;index:idx_audit_resource"`
+	ResourceId string            `json:"resourceId" gorm:"size:100;index:idx_audit_resource"`
+	Verb       string            `json:"verb" gorm:"size:50"`
+	Before     string            `json:"before,omitempty" gorm:"type:text"`
+	After      string            `json:"after,omitempty" gorm:"type:text"`
+	Changes    string            `json:"changes,omitempty" gorm:"type:text"`
+	Ip         string            `json:"ip" gorm:"size:50"`
+	RequestId  string            `json:"requestId" gorm:"size:100"`
+	TraceId    string            `json:"traceId" gorm:"size:100"`
+	Metadata   map[string]string `json:"metadata,omitempty" gorm:"serializer:json;type:text"`
+	CreatedAt  time.Time         `json:"createdAt" gorm:"index"`
+}
+
+// Sink write audit entries to storage
+type Sink interface {
+	Write(ctx context.Context, entries ...Entry) error
+}
+
+// Audit enrich actions from ctx and write them by worker
+type Audit struct {
+	ops Options
+}
+
+func New(options ...func(*Options)) (a *Audit, err error) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.sink == nil {
+		err = ErrSinkNil
+		return
+	}
+	a = &Audit{
+		ops: *ops,
+	}
+	return
+}
+
+// Group is the worker task group of audit
+func (a *Audit) Group() string {
+	return a.ops.group
+}
+
+// Record enqueue action to worker, or write it to sink directly without worker
+func (a *Audit) Record(ctx context.Context, action Action) (err error) {
+	e, err := a.entry(ctx, action)
+	if err != nil {
+		return
+	}
+	if a.ops.worker == nil {
+		err = a.ops.sink.Write(ctx, e)
+		return
+	}
+	bs, _ := json.Marshal(e)
+	err = a.ops.worker.Once(
+		worker.WithRunUuid(uuid.NewString()),
+		worker.WithRunGroup(a.ops.group),
+		worker.WithRunPayload(string(bs)),
+		worker.WithRunNow(true),
+		worker.WithRunMaxRetry(a.ops.maxRetry),
+		worker.WithRunCtx(ctx),
+	)
+	return
+}
+
+// Process write the enqueued entry to sink, return error to retry
+func (a *Audit) Process(ctx context.Context, p worker.Payload) (err error) {
+	var e Entry
+	err = json.Unmarshal([]byte(p.Payload), &e)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	err = a.ops.sink.Write(ctx, e)
+	return
+}
+
+func (a *Audit) entry(ctx context.Context, action Action) (e Entry, err error) {
+	if action.Verb == "" {
+		err = ErrVerbNil
+		return
+	}
+	e = Entry{
+		Actor:      action.Actor,
+		Tenant:     a.ops.tenant(ctx),
+		Resource:   action.Resource,
+		ResourceId: action.ResourceId,
+		Verb:       action.Verb,
+		Ip:         action.Ip,
+		RequestId:  requestid.FromContext(ctx),
+		Metadata:   action.Metadata,
+		CreatedAt:  time.Now(),
+	}
+	if e.Actor == "" {
+		e.Actor = jwt.FromServerContext(ctx).Code
+	}
+	if e.Ip == "" {
+		e.Ip = a.ops.ip(ctx)
+	}
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		e.TraceId = span.TraceID().String()
+	}
+	e.Before, err = marshal(action.Before)
+	if err != nil {
+		return
+	}
+	e.After, err = marshal(action.After)
+	if err != nil {
+		return
+	}
+	e.Changes, err = changes(action.Before, action.After)
+	return
+}
+
+// changes get field level diff if Before/After are the same struct type
+func changes(before, after interface{}) (rp string, err error) {
+	if before == nil || after == nil {
+		return
+	}
+	list, e := diff.Compare(before, after)
+	if e != nil || len(list) == 0 {
+		// non-struct or different types
+		return
+	}
+	rp, err = marshal(list)
+	return
+}
+
+func marshal(v interface{}) (rp string, err error) {
+	switch vt := v.(type) {
+	case nil:
+		return
+	case string:
+		rp = vt
+		return
+	case []byte:
+		rp = string(vt)
+		return
+	}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	rp = string(bs)
+	return
+}
@@ -0,0 +1,146 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-cinch/common/jwt"
+	"github.com/go-cinch/common/middleware/requestid"
+	"github.com/go-cinch/common/page"
+	"github.com/go-cinch/common/tenant"
+	"github.com/go-cinch/common/worker"
+	"google.golang.org/grpc/peer"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+type memorySink struct {
+	list []Entry
+}
+
+func (m *memorySink) Write(ctx context.Context, entries ...Entry) error {
+	m.list = append(m.list, entries...)
+	return nil
+}
+
+func TestRecord(t *testing.T) {
+	sink := &memorySink{}
+	_, err := New()
+	if err != ErrSinkNil {
+		t.Fatalf("expect sink nil but got %v", err)
+	}
+	a, _ := New(WithSink(sink), WithTenant(func(ctx context.Context) string {
+		return "t1"
+	}))
+	ctx := requestid.NewContext(context.Background(), "req1")
+	ctx = jwt.NewServerContextByUser(ctx, jwt.User{Code: "admin"})
+	err = a.Record(ctx, Action{
+		Resource:   "user",
+		ResourceId: "1",
+		Verb:       "update",
+		Before:     user{Name: "a"},
+		After:      user{Name: "b"},
+		Ip:         "127.0.0.1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := sink.list[0]
+	if e.Actor != "admin" || e.Tenant != "t1" || e.RequestId != "req1" || e.Before != `{"name":"a"}` || e.After != `{"name":"b"}` {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+	if e.Changes != `[{"op":"replace","path":"/name","old":"a","value":"b"}]` {
+		t.Fatalf("unexpected changes %s", e.Changes)
+	}
+	if err = a.Record(ctx, Action{Resource: "user"}); err != ErrVerbNil {
+		t.Fatalf("expect verb nil but got %v", err)
+	}
+
+	// process worker payload
+	bs, _ := json.Marshal(e)
+	err = a.Process(context.Background(), worker.Payload{Group: a.Group(), Payload: string(bs)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sink.list) != 2 || sink.list[1].Actor != "admin" {
+		t.Fatalf("unexpected entries %+v", sink.list)
+	}
+}
+
+func TestRecordDefault(t *testing.T) {
+	sink := &memorySink{}
+	a, _ := New(WithSink(sink))
+	ctx := tenant.SetTenant(context.Background(), "t2")
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 9000}})
+	if err := a.Record(ctx, Action{Resource: "user", Verb: "delete"}); err != nil {
+		t.Fatal(err)
+	}
+	if e := sink.list[0]; e.Tenant != "t2" || e.Ip != "10.0.0.1" {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+}
+
+func TestGorm(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, _ := NewGorm(db)
+	if err = g.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	old := time.Now().Add(-48 * time.Hour)
+	err = g.Write(ctx,
+		Entry{Actor: "a", Resource: "user", ResourceId: "1", Verb: "create", CreatedAt: old},
+		Entry{Actor: "a", Resource: "user", ResourceId: "1", Verb: "update", Metadata: map[string]string{"k": "v"}},
+		Entry{Actor: "b", Resource: "role", ResourceId: "2", Verb: "delete"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := page.New()
+	list := g.Find(ctx, Filter{Resource: "user", ResourceId: "1"}, p)
+	if p.Total != 2 || len(list) != 2 || list[0].Verb != "update" || list[0].Metadata["k"] != "v" {
+		t.Fatalf("unexpected list %d %+v", p.Total, list)
+	}
+	count, err := g.Clean(ctx, 24*time.Hour)
+	if err != nil || count != 1 {
+		t.Fatalf("unexpected clean %d %v", count, err)
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var entries []Entry
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Audit-Signature")
+		_ = json.NewDecoder(r.Body).Decode(&entries)
+	}))
+	defer srv.Close()
+	_, err := NewWebhook()
+	if err != ErrUrlNil {
+		t.Fatalf("expect url nil but got %v", err)
+	}
+	w, _ := NewWebhook(WithWebhookUrl(srv.URL), WithWebhookSecret("secret"))
+	e := Entry{Actor: "a", Verb: "login"}
+	err = w.Write(context.Background(), e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, _ := json.Marshal([]Entry{e})
+	if len(entries) != 1 || entries[0].Verb != "login" || signature != Sign("secret", bs) {
+		t.Fatalf("unexpected webhook %+v %s", entries, signature)
+	}
+}
@@ -0,0 +1,11 @@
+package audit
+
+import "github.com/pkg/errors"
+
+var (
+	ErrSinkNil           = errors.New("sink is nil")
+	ErrDBNil             = errors.New("db is nil")
+	ErrUrlNil            = errors.New("webhook url is empty")
+	ErrVerbNil           = errors.New("action verb is empty")
+	ErrInvalidStatusCode = errors.New("invalid status code")
+)
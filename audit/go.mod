@@ -0,0 +1,65 @@
+module github.com/go-cinch/common/audit
+
+go 1.20
+
+replace (
+	github.com/go-cinch/common/constant => ../constant
+	github.com/go-cinch/common/cron => ../cron
+	github.com/go-cinch/common/jwt => ../jwt
+	github.com/go-cinch/common/log => ../log
+	github.com/go-cinch/common/middleware/ratelimit => ../middleware/ratelimit
+	github.com/go-cinch/common/middleware/requestid => ../middleware/requestid
+	github.com/go-cinch/common/nx => ../nx
+	github.com/go-cinch/common/page => ../page
+	github.com/go-cinch/common/tenant => ../tenant
+	github.com/go-cinch/common/utils => ../utils
+	github.com/go-cinch/common/worker => ../worker
+)
+
+require (
+	github.com/go-cinch/common/jwt v1.0.3
+	github.com/go-cinch/common/middleware/ratelimit v1.0.4
+	github.com/go-cinch/common/middleware/requestid v1.0.4
+	github.com/go-cinch/common/page v1.0.4
+	github.com/go-cinch/common/tenant v1.0.4
+	github.com/go-cinch/common/utils v1.0.4
+	github.com/go-cinch/common/worker v1.0.4
+	github.com/google/uuid v1.3.1
+	github.com/pkg/errors v0.9.1
+	go.opentelemetry.io/otel/trace v1.16.0
+	google.golang.org/grpc v1.56.1
+	gorm.io/driver/sqlite v1.5.2
+	gorm.io/gorm v1.25.2
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/go-cinch/common/constant v1.0.3 // indirect
+	github.com/go-cinch/common/cron v1.0.4 // indirect
+	github.com/go-cinch/common/log v1.0.4 // indirect
+	github.com/go-cinch/common/nx v1.0.4 // indirect
+	github.com/go-kratos/aegis v0.2.0 // indirect
+	github.com/go-kratos/kratos/v2 v2.7.0 // indirect
+	github.com/go-playground/form/v4 v4.2.1 // indirect
+	github.com/golang-jwt/jwt/v4 v4.5.0 // indirect
+	github.com/golang-module/carbon/v2 v2.2.8 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/gorhill/cronexpr v0.0.0-20180427100037-88b0669f7d75 // indirect
+	github.com/gorilla/mux v1.8.0 // indirect
+	github.com/hibiken/asynq v0.24.1 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/mattn/go-sqlite3 v1.14.22 // indirect
+	github.com/redis/go-redis/v9 v9.2.1 // indirect
+	github.com/robfig/cron/v3 v3.0.1 // indirect
+	github.com/spf13/cast v1.5.1 // indirect
+	go.opentelemetry.io/otel v1.16.0 // indirect
+	golang.org/x/sync v0.9.0 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+	golang.org/x/text v0.20.0 // indirect
+	golang.org/x/time v0.3.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230629202037-9506855d4529 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
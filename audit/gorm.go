@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"github.com/go-cinch/common/page"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Gorm write entries to database table
+type Gorm struct {
+	ops GormOptions
+	db  *gorm.DB
+}
+
+// Filter is the query condition, empty fields are ignored
+type Filter struct {
+	Actor      string
+	Tenant     string
+	Resource   string
+	ResourceId string
+	Verb       string
+	Start      *time.Time
+	End        *time.Time
+}
+
+func NewGorm(db *gorm.DB, options ...func(*GormOptions)) (g *Gorm, err error) {
+	ops := getGormOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if db == nil {
+		err = ErrDBNil
+		return
+	}
+	g = &Gorm{
+		ops: *ops,
+		db:  db,
+	}
+	return
+}
+
+// Migrate create or update audit table
+func (g *Gorm) Migrate() (err error) {
+	err = g.db.Table(g.ops.table).AutoMigrate(&Entry{})
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+func (g *Gorm) Write(ctx context.Context, entries ...Entry) (err error) {
+	if len(entries) == 0 {
+		return
+	}
+	err = g.db.WithContext(ctx).Table(g.ops.table).Create(&entries).Error
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+// Find query entries by filter, the latest first, p.Total is set after query
+func (g *Gorm) Find(ctx context.Context, filter Filter, p *page.Page) (rp []Entry) {
+	rp = make([]Entry, 0)
+	db := g.db.WithContext(ctx).Table(g.ops.table).Scopes(filter.scope).Order("id DESC")
+	p.WithContext(ctx).Query(db).Find(&rp)
+	return
+}
+
+// Clean delete entries created before retention, it can be called by worker cron
+func (g *Gorm) Clean(ctx context.Context, retention time.Duration) (count int64, err error) {
+	db := g.db.
+		WithContext(ctx).
+		Table(g.ops.table).
+		Where("created_at < ?", time.Now().Add(-retention)).
+		Delete(&Entry{})
+	err = db.Error
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	count = db.RowsAffected
+	return
+}
+
+func (f Filter) scope(db *gorm.DB) *gorm.DB {
+	if f.Actor != "" {
+		db = db.Where("actor = ?", f.Actor)
+	}
+	if f.Tenant != "" {
+		db = db.Where("tenant = ?", f.Tenant)
+	}
+	if f.Resource != "" {
+		db = db.Where("resource = ?", f.Resource)
+	}
+	if f.ResourceId != "" {
+		db = db.Where("resource_id = ?", f.ResourceId)
+	}
+	if f.Verb != "" {
+		db = db.Where("verb = ?", f.Verb)
+	}
+	if f.Start != nil {
+		db = db.Where("created_at >= ?", *f.Start)
+	}
+	if f.End != nil {
+		db = db.Where("created_at < ?", *f.End)
+	}
+	return db
+}
@@ -0,0 +1,154 @@
+package audit
+
+import (
+	"context"
+	"github.com/go-cinch/common/middleware/ratelimit"
+	"github.com/go-cinch/common/tenant"
+	"github.com/go-cinch/common/worker"
+	"net/http"
+	"time"
+)
+
+type Options struct {
+	sink     Sink
+	worker   *worker.Worker
+	group    string
+	maxRetry int
+	tenant   func(ctx context.Context) string
+	ip       func(ctx context.Context) string
+}
+
+func WithSink(s Sink) func(*Options) {
+	return func(options *Options) {
+		if s != nil {
+			getOptionsOrSetDefault(options).sink = s
+		}
+	}
+}
+
+// WithWorker write entries asynchronously, call Process in worker handler when payload group is Group()
+func WithWorker(wk *worker.Worker) func(*Options) {
+	return func(options *Options) {
+		if wk != nil {
+			getOptionsOrSetDefault(options).worker = wk
+		}
+	}
+}
+
+func WithGroup(group string) func(*Options) {
+	return func(options *Options) {
+		if group != "" {
+			getOptionsOrSetDefault(options).group = group
+		}
+	}
+}
+
+func WithMaxRetry(count int) func(*Options) {
+	return func(options *Options) {
+		if count >= 0 {
+			getOptionsOrSetDefault(options).maxRetry = count
+		}
+	}
+}
+
+// WithTenant get tenant id from ctx, default tenant.FromContext
+func WithTenant(f func(ctx context.Context) string) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).tenant = f
+		}
+	}
+}
+
+// WithIp get client ip from ctx, default ratelimit.ByIP() is the peer address,
+// use ratelimit.ByIP(trustedProxies...) to read forwarding headers behind proxy
+func WithIp(f func(ctx context.Context) string) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).ip = f
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			group:    "audit",
+			maxRetry: 5,
+			tenant:   tenant.FromContext,
+			ip:       ratelimit.ByIP(),
+		}
+	}
+	return options
+}
+
+type GormOptions struct {
+	table string
+}
+
+func WithGormTable(table string) func(*GormOptions) {
+	return func(options *GormOptions) {
+		if table != "" {
+			getGormOptionsOrSetDefault(options).table = table
+		}
+	}
+}
+
+func getGormOptionsOrSetDefault(options *GormOptions) *GormOptions {
+	if options == nil {
+		return &GormOptions{
+			table: "audit_log",
+		}
+	}
+	return options
+}
+
+type WebhookOptions struct {
+	url     string
+	secret  string
+	headers map[string]string
+	client  *http.Client
+}
+
+func WithWebhookUrl(url string) func(*WebhookOptions) {
+	return func(options *WebhookOptions) {
+		if url != "" {
+			getWebhookOptionsOrSetDefault(options).url = url
+		}
+	}
+}
+
+// WithWebhookSecret sign body by hmac-sha256, the hex signature is set to X-Audit-Signature header
+func WithWebhookSecret(secret string) func(*WebhookOptions) {
+	return func(options *WebhookOptions) {
+		getWebhookOptionsOrSetDefault(options).secret = secret
+	}
+}
+
+func WithWebhookHeader(key, value string) func(*WebhookOptions) {
+	return func(options *WebhookOptions) {
+		if key != "" {
+			getWebhookOptionsOrSetDefault(options).headers[key] = value
+		}
+	}
+}
+
+func WithWebhookClient(client *http.Client) func(*WebhookOptions) {
+	return func(options *WebhookOptions) {
+		if client != nil {
+			getWebhookOptionsOrSetDefault(options).client = client
+		}
+	}
+}
+
+func getWebhookOptionsOrSetDefault(options *WebhookOptions) *WebhookOptions {
+	if options == nil {
+		return &WebhookOptions{
+			headers: make(map[string]string),
+			client: &http.Client{
+				Timeout: 10 * time.Second,
+			},
+		}
+	}
+	return options
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+)
+
+// Webhook post entries as json array to url
+type Webhook struct {
+	ops WebhookOptions
+}
+
+func NewWebhook(options ...func(*WebhookOptions)) (w *Webhook, err error) {
+	ops := getWebhookOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.url == "" {
+		err = ErrUrlNil
+		return
+	}
+	w = &Webhook{
+		ops: *ops,
+	}
+	return
+}
+
+func (w *Webhook) Write(ctx context.Context, entries ...Entry) (err error) {
+	if len(entries) == 0 {
+		return
+	}
+	bs, _ := json.Marshal(entries)
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, w.ops.url, bytes.NewReader(bs))
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	r.Header.Set("Content-Type", "application/json")
+	for k, v := range w.ops.headers {
+		r.Header.Set(k, v)
+	}
+	if w.ops.secret != "" {
+		r.Header.Set("X-Audit-Signature", Sign(w.ops.secret, bs))
+	}
+	res, err := w.ops.client.Do(r)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		err = errors.Wrapf(ErrInvalidStatusCode, "%d %s", res.StatusCode, body)
+	}
+	return
+}
+
+// Sign hex hmac-sha256 of body, receiver can verify X-Audit-Signature with the same secret
+func Sign(secret string, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
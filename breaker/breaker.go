@@ -0,0 +1,270 @@
+package breaker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type State int8
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Stats is the metrics of one circuit, requests/failures/slow are counted in sliding window
+type Stats struct {
+	State    string `json:"state"`
+	Requests uint64 `json:"requests"`
+	Failures uint64 `json:"failures"`
+	Slow     uint64 `json:"slow"`
+	Rejected uint64 `json:"rejected"`
+}
+
+// Breaker manage circuit of each key, such as downstream service or operation
+type Breaker struct {
+	ops      Options
+	lock     sync.RWMutex
+	circuits map[string]*circuit
+}
+
+func New(options ...func(*Options)) (b *Breaker) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	b = &Breaker{
+		ops:      *ops,
+		circuits: make(map[string]*circuit),
+	}
+	return
+}
+
+// Do call fn if circuit of key allows, ErrOpen or ErrTooManyRequests is returned if rejected
+func (b *Breaker) Do(ctx context.Context, key string, fn func(ctx context.Context) error) (err error) {
+	c := b.circuit(key)
+	generation, err := c.allow(time.Now())
+	if err != nil {
+		return
+	}
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			c.record(generation, true, time.Since(start))
+			panic(r)
+		}
+	}()
+	err = fn(ctx)
+	c.record(generation, b.ops.isFailure(err), time.Since(start))
+	return
+}
+
+// State get current state of key
+func (b *Breaker) State(key string) State {
+	b.lock.RLock()
+	c, ok := b.circuits[key]
+	b.lock.RUnlock()
+	if !ok {
+		return StateClosed
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.state
+}
+
+// Stats get metrics of all keys, can be exported to prometheus or log
+func (b *Breaker) Stats() (rp map[string]Stats) {
+	b.lock.RLock()
+	list := make([]*circuit, 0, len(b.circuits))
+	for _, c := range b.circuits {
+		list = append(list, c)
+	}
+	b.lock.RUnlock()
+	rp = make(map[string]Stats, len(list))
+	now := time.Now()
+	for _, c := range list {
+		rp[c.key] = c.stats(now)
+	}
+	return
+}
+
+func (b *Breaker) circuit(key string) *circuit {
+	b.lock.RLock()
+	c, ok := b.circuits[key]
+	b.lock.RUnlock()
+	if ok {
+		return c
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if c, ok = b.circuits[key]; ok {
+		return c
+	}
+	c = &circuit{
+		key:     key,
+		ops:     &b.ops,
+		buckets: make([]bucket, b.ops.window),
+	}
+	b.circuits[key] = c
+	return c
+}
+
+type bucket struct {
+	second   int64
+	requests uint64
+	failures uint64
+	slow     uint64
+}
+
+type circuit struct {
+	key      string
+	ops      *Options
+	lock     sync.Mutex
+	state    State
+	openedAt time.Time
+	buckets  []bucket
+	// generation is increased on each state change, results of calls allowed in old state are ignored
+	generation uint64
+	probes     int
+	successes  int
+	rejected   uint64
+}
+
+func (c *circuit) allow(now time.Time) (generation uint64, err error) {
+	c.lock.Lock()
+	old := c.state
+	if c.state == StateOpen && now.Sub(c.openedAt) >= time.Duration(c.ops.openTimeout)*time.Second {
+		c.setState(StateHalfOpen, now)
+	}
+	switch c.state {
+	case StateOpen:
+		err = ErrOpen
+	case StateHalfOpen:
+		if c.probes >= c.ops.halfOpenRequests {
+			err = ErrTooManyRequests
+		} else {
+			c.probes++
+		}
+	}
+	if err != nil {
+		c.rejected++
+	}
+	generation = c.generation
+	state := c.state
+	c.lock.Unlock()
+	c.notify(old, state)
+	return
+}
+
+func (c *circuit) record(generation uint64, failure bool, elapsed time.Duration) {
+	now := time.Now()
+	slow := c.ops.slowCallDuration > 0 && elapsed >= c.ops.slowCallDuration
+	c.lock.Lock()
+	old := c.state
+	if generation == c.generation {
+		switch c.state {
+		case StateClosed:
+			c.add(now, failure, slow)
+			if c.tripped(now) {
+				c.setState(StateOpen, now)
+			}
+		case StateHalfOpen:
+			if failure || slow {
+				c.setState(StateOpen, now)
+			} else {
+				c.successes++
+				if c.successes >= c.ops.halfOpenRequests {
+					c.setState(StateClosed, now)
+				}
+			}
+		}
+	}
+	state := c.state
+	c.lock.Unlock()
+	c.notify(old, state)
+}
+
+func (c *circuit) add(now time.Time, failure, slow bool) {
+	second := now.Unix()
+	b := &c.buckets[int(second%int64(len(c.buckets)))]
+	if b.second != second {
+		*b = bucket{second: second}
+	}
+	b.requests++
+	if failure {
+		b.failures++
+	}
+	if slow {
+		b.slow++
+	}
+}
+
+func (c *circuit) sum(now time.Time) (rp bucket) {
+	second := now.Unix()
+	for _, item := range c.buckets {
+		if second-item.second < int64(len(c.buckets)) {
+			rp.requests += item.requests
+			rp.failures += item.failures
+			rp.slow += item.slow
+		}
+	}
+	return
+}
+
+func (c *circuit) tripped(now time.Time) bool {
+	total := c.sum(now)
+	if total.requests == 0 || total.requests < uint64(c.ops.minRequests) {
+		return false
+	}
+	if float64(total.failures)/float64(total.requests) >= c.ops.failureRate {
+		return true
+	}
+	return c.ops.slowCallDuration > 0 && float64(total.slow)/float64(total.requests) >= c.ops.slowCallRate
+}
+
+func (c *circuit) setState(state State, now time.Time) {
+	c.state = state
+	c.generation++
+	c.probes = 0
+	c.successes = 0
+	switch state {
+	case StateOpen:
+		c.openedAt = now
+	case StateClosed:
+		// start a new window
+		for i := range c.buckets {
+			c.buckets[i] = bucket{}
+		}
+	}
+}
+
+func (c *circuit) notify(from, to State) {
+	if from != to && c.ops.onStateChange != nil {
+		c.ops.onStateChange(c.key, from, to)
+	}
+}
+
+func (c *circuit) stats(now time.Time) (rp Stats) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	total := c.sum(now)
+	rp.State = c.state.String()
+	rp.Requests = total.requests
+	rp.Failures = total.failures
+	rp.Slow = total.slow
+	rp.Rejected = c.rejected
+	return
+}
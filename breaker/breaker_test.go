@@ -0,0 +1,167 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+	"testing"
+	"time"
+)
+
+var errDown = errors.New("downstream error")
+
+func TestBreaker(t *testing.T) {
+	changes := make([]string, 0)
+	b := New(
+		WithMinRequests(4),
+		WithFailureRate(0.5),
+		WithOpenTimeout(1),
+		WithHalfOpenRequests(2),
+		WithOnStateChange(func(key string, from, to State) {
+			changes = append(changes, key+":"+from.String()+"->"+to.String())
+		}),
+	)
+	ctx := context.Background()
+	success := func(ctx context.Context) error { return nil }
+	failure := func(ctx context.Context) error { return errDown }
+
+	_ = b.Do(ctx, "a", success)
+	_ = b.Do(ctx, "a", success)
+	_ = b.Do(ctx, "a", failure)
+	if b.State("a") != StateClosed {
+		t.Fatal("expect closed before min requests")
+	}
+	_ = b.Do(ctx, "a", failure)
+	if b.State("a") != StateOpen {
+		t.Fatal("expect open")
+	}
+	var called bool
+	err := b.Do(ctx, "a", func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+	if err != ErrOpen || called {
+		t.Fatalf("expect open but got %v", err)
+	}
+	// other key is not affected
+	if err = b.Do(ctx, "b", success); err != nil {
+		t.Fatal(err)
+	}
+
+	// half-open failed
+	b.circuits["a"].openedAt = time.Now().Add(-time.Second)
+	if err = b.Do(ctx, "a", failure); err != errDown {
+		t.Fatalf("expect downstream error but got %v", err)
+	}
+	if b.State("a") != StateOpen {
+		t.Fatal("expect open after probe failed")
+	}
+
+	// half-open succeeded
+	b.circuits["a"].openedAt = time.Now().Add(-time.Second)
+	_ = b.Do(ctx, "a", success)
+	if b.State("a") != StateHalfOpen {
+		t.Fatal("expect half-open")
+	}
+	_ = b.Do(ctx, "a", success)
+	if b.State("a") != StateClosed {
+		t.Fatal("expect closed")
+	}
+	expect := []string{"a:closed->open", "a:open->half-open", "a:half-open->open", "a:open->half-open", "a:half-open->closed"}
+	if len(changes) != len(expect) {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+	for i, item := range expect {
+		if changes[i] != item {
+			t.Fatalf("unexpected changes %v", changes)
+		}
+	}
+	stats := b.Stats()
+	if stats["a"].Rejected != 1 || stats["a"].State != "closed" || stats["b"].Requests != 1 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestBreakerHalfOpenLimit(t *testing.T) {
+	b := New(WithMinRequests(1), WithHalfOpenRequests(1))
+	ctx := context.Background()
+	_ = b.Do(ctx, "a", func(ctx context.Context) error { return errDown })
+	b.circuits["a"].openedAt = time.Now().Add(-time.Minute)
+	done := make(chan struct{})
+	started := make(chan struct{})
+	go func() {
+		_ = b.Do(ctx, "a", func(ctx context.Context) error {
+			close(started)
+			<-done
+			return nil
+		})
+	}()
+	<-started
+	if err := b.Do(ctx, "a", func(ctx context.Context) error { return nil }); err != ErrTooManyRequests {
+		t.Fatalf("expect too many requests but got %v", err)
+	}
+	close(done)
+}
+
+func TestBreakerSlowCall(t *testing.T) {
+	b := New(WithMinRequests(2), WithSlowCall(5*time.Millisecond, 0.5))
+	ctx := context.Background()
+	_ = b.Do(ctx, "a", func(ctx context.Context) error { return nil })
+	_ = b.Do(ctx, "a", func(ctx context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	if b.State("a") != StateOpen {
+		t.Fatal("expect open by slow call")
+	}
+}
+
+func TestBreakerIsFailure(t *testing.T) {
+	b := New(WithMinRequests(1))
+	ctx := context.Background()
+	// client errors do not open circuit
+	_ = b.Do(ctx, "a", func(ctx context.Context) error { return kerrors.BadRequest("bad", "bad request") })
+	_ = b.Do(ctx, "a", func(ctx context.Context) error { return context.Canceled })
+	if b.State("a") != StateClosed {
+		t.Fatal("expect closed")
+	}
+	for i := 0; i < 2; i++ {
+		_ = b.Do(ctx, "a", func(ctx context.Context) error { return kerrors.ServiceUnavailable("down", "down") })
+	}
+	if b.State("a") != StateOpen {
+		t.Fatal("expect open")
+	}
+}
+
+type testTransport struct {
+	operation string
+}
+
+func (t testTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (t testTransport) Endpoint() string                { return "discovery:///user" }
+func (t testTransport) Operation() string               { return t.operation }
+func (t testTransport) RequestHeader() transport.Header { return nil }
+func (t testTransport) ReplyHeader() transport.Header   { return nil }
+
+func TestClient(t *testing.T) {
+	b := New(WithMinRequests(1))
+	m := b.Middleware()
+	var count int
+	h := m(func(ctx context.Context, req interface{}) (interface{}, error) {
+		count++
+		return nil, errDown
+	})
+	ctx := transport.NewClientContext(context.Background(), testTransport{operation: "/user.v1.User/Info"})
+	_, err := h(ctx, nil)
+	if err != errDown {
+		t.Fatalf("expect downstream error but got %v", err)
+	}
+	_, err = h(ctx, nil)
+	if e := kerrors.FromError(err); e.Code != 503 || e.Metadata["breaker"] != "/user.v1.User/Info" {
+		t.Fatalf("expect 503 but got %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("unexpected call count %d", count)
+	}
+}
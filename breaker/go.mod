@@ -0,0 +1,21 @@
+module github.com/go-cinch/common/breaker
+
+go 1.20
+
+replace github.com/go-cinch/common/constant => ../constant
+
+require (
+	github.com/go-cinch/common/constant v1.0.3
+	github.com/go-kratos/kratos/v2 v2.7.0
+	github.com/pkg/errors v0.9.1
+)
+
+require (
+	github.com/go-playground/form/v4 v4.2.0 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230629202037-9506855d4529 // indirect
+	google.golang.org/grpc v1.56.1 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
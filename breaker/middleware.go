@@ -0,0 +1,56 @@
+package breaker
+
+import (
+	"context"
+	"github.com/go-cinch/common/constant"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// Client is kratos client middleware, rejected request will get 503 error without calling downstream
+func Client(options ...func(*Options)) middleware.Middleware {
+	return New(options...).Middleware()
+}
+
+// Middleware use the breaker as kratos client middleware, Stats can be read from b
+func (b *Breaker) Middleware() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (rp interface{}, err error) {
+			key := b.ops.key(ctx)
+			if key == "" {
+				return handler(ctx, req)
+			}
+			err = b.Do(ctx, key, func(ctx context.Context) (e error) {
+				rp, e = handler(ctx, req)
+				return
+			})
+			if errors.Is(err, ErrOpen) || errors.Is(err, ErrTooManyRequests) {
+				err = errors.New(503, constant.ServiceUnavailable, err.Error()).WithMetadata(map[string]string{
+					"breaker": key,
+				})
+			}
+			return
+		}
+	}
+}
+
+// ByOperation use client operation as key
+func ByOperation() func(ctx context.Context) string {
+	return func(ctx context.Context) (rp string) {
+		if tr, ok := transport.FromClientContext(ctx); ok {
+			rp = tr.Operation()
+		}
+		return
+	}
+}
+
+// ByEndpoint use downstream endpoint as key
+func ByEndpoint() func(ctx context.Context) string {
+	return func(ctx context.Context) (rp string) {
+		if tr, ok := transport.FromClientContext(ctx); ok {
+			rp = tr.Endpoint()
+		}
+		return
+	}
+}
@@ -0,0 +1,122 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"time"
+)
+
+type Options struct {
+	window           int
+	minRequests      int
+	failureRate      float64
+	slowCallDuration time.Duration
+	slowCallRate     float64
+	openTimeout      int
+	halfOpenRequests int
+	isFailure        func(err error) bool
+	onStateChange    func(key string, from, to State)
+	key              func(ctx context.Context) string
+}
+
+// WithWindow sliding window seconds of failure/slow rate
+func WithWindow(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).window = second
+		}
+	}
+}
+
+// WithMinRequests rates are not calculated until requests in window reach min requests
+func WithMinRequests(count int) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).minRequests = count
+		}
+	}
+}
+
+// WithFailureRate open circuit if failure rate >= rate
+func WithFailureRate(rate float64) func(*Options) {
+	return func(options *Options) {
+		if rate > 0 && rate <= 1 {
+			getOptionsOrSetDefault(options).failureRate = rate
+		}
+	}
+}
+
+// WithSlowCall call longer than duration is slow, open circuit if slow rate >= rate, disabled by default
+func WithSlowCall(duration time.Duration, rate float64) func(*Options) {
+	return func(options *Options) {
+		if duration > 0 && rate > 0 && rate <= 1 {
+			getOptionsOrSetDefault(options).slowCallDuration = duration
+			getOptionsOrSetDefault(options).slowCallRate = rate
+		}
+	}
+}
+
+// WithOpenTimeout seconds from open to half-open
+func WithOpenTimeout(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).openTimeout = second
+		}
+	}
+}
+
+// WithHalfOpenRequests permitted probe requests in half-open state, circuit is closed if all of them succeed
+func WithHalfOpenRequests(count int) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).halfOpenRequests = count
+		}
+	}
+}
+
+// WithIsFailure classify error, default context canceled and kratos errors with code < 500 are not failures
+func WithIsFailure(f func(err error) bool) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).isFailure = f
+		}
+	}
+}
+
+func WithOnStateChange(f func(key string, from, to State)) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).onStateChange = f
+		}
+	}
+}
+
+// WithKey circuit key of client middleware, default operation
+func WithKey(f func(ctx context.Context) string) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).key = f
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			window:           10,
+			minRequests:      20,
+			failureRate:      0.5,
+			openTimeout:      30,
+			halfOpenRequests: 5,
+			isFailure: func(err error) bool {
+				if err == nil || errors.Is(err, context.Canceled) {
+					return false
+				}
+				return kerrors.FromError(err).Code >= 500
+			},
+			key: ByOperation(),
+		}
+	}
+	return options
+}
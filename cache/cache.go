@@ -0,0 +1,227 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/go-cinch/common/log"
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// notFound is the negative cache value, json/proto data never start with \x00
+const notFound = "\x00cache.not.found"
+
+type Cache struct {
+	ops   Options
+	group singleflight.Group
+	local *lru
+	id    string
+	ps    *redis.PubSub
+	stats stats
+}
+
+func New(options ...func(*Options)) (c *Cache) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.channel == "" {
+		ops.channel = strings.Join([]string{ops.prefix, "invalidate"}, ".")
+	}
+	c = &Cache{
+		ops: *ops,
+	}
+	if ops.localSize > 0 {
+		c.local = newLru(ops.localSize, time.Duration(ops.localExpire)*time.Second)
+		if ops.redis != nil {
+			c.subscribe()
+		}
+	}
+	return
+}
+
+// Get get raw value, ErrNotFound will be returned when the key is missing or negative cached
+func (c *Cache) Get(ctx context.Context, key string) (data []byte, err error) {
+	data, err = c.get(ctx, key)
+	if errors.Is(err, errMiss) {
+		err = ErrNotFound
+	}
+	return
+}
+
+// get distinguish missing(errMiss) and negative cached(ErrNotFound)
+func (c *Cache) get(ctx context.Context, key string) (data []byte, err error) {
+	if c.local != nil {
+		var ok bool
+		data, ok = c.local.get(key)
+		if ok {
+			atomic.AddUint64(&c.stats.localHit, 1)
+			if string(data) == notFound {
+				data = nil
+				err = ErrNotFound
+			}
+			return
+		}
+		atomic.AddUint64(&c.stats.localMiss, 1)
+	}
+	if c.ops.redis == nil {
+		err = ErrNoRedis
+		return
+	}
+	data, err = c.ops.redis.Get(ctx, c.key(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		atomic.AddUint64(&c.stats.redisMiss, 1)
+		err = errMiss
+		return
+	}
+	if err != nil {
+		return
+	}
+	atomic.AddUint64(&c.stats.redisHit, 1)
+	if c.local != nil {
+		c.local.set(key, data)
+	}
+	if string(data) == notFound {
+		data = nil
+		err = ErrNotFound
+	}
+	return
+}
+
+// Set set raw value, ttl 0 means use default expire, jitter will be added
+func (c *Cache) Set(ctx context.Context, key string, data []byte, ttl time.Duration) (err error) {
+	if c.ops.redis == nil {
+		err = ErrNoRedis
+		return
+	}
+	if ttl <= 0 {
+		ttl = time.Duration(c.ops.expire) * time.Second
+	}
+	err = c.ops.redis.Set(ctx, c.key(key), data, c.jitter(ttl)).Err()
+	if err != nil {
+		return
+	}
+	if c.local != nil {
+		c.local.set(key, data)
+		c.publish(ctx, key)
+	}
+	return
+}
+
+// Del invalidate keys, call it after the source data changed, local tier of all instances will be invalidated too
+func (c *Cache) Del(ctx context.Context, keys ...string) (err error) {
+	if len(keys) == 0 {
+		return
+	}
+	if c.local != nil {
+		c.local.del(keys...)
+	}
+	if c.ops.redis == nil {
+		return
+	}
+	arr := make([]string, len(keys))
+	for i, key := range keys {
+		arr[i] = c.key(key)
+	}
+	err = c.ops.redis.Del(ctx, arr...).Err()
+	if c.local != nil {
+		c.publish(ctx, keys...)
+	}
+	return
+}
+
+// setNotFound negative cache, avoid cache penetration
+func (c *Cache) setNotFound(ctx context.Context, key string) {
+	if c.ops.redis == nil || c.ops.notFoundExpire == 0 {
+		return
+	}
+	err := c.ops.redis.Set(ctx, c.key(key), notFound, c.jitter(time.Duration(c.ops.notFoundExpire)*time.Second)).Err()
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("set not found cache failed, key: %s", key)
+		return
+	}
+	if c.local != nil {
+		c.local.set(key, []byte(notFound))
+		c.publish(ctx, key)
+	}
+}
+
+func (c *Cache) key(key string) string {
+	return strings.Join([]string{c.ops.prefix, key}, ".")
+}
+
+func (c *Cache) jitter(ttl time.Duration) time.Duration {
+	if c.ops.jitter <= 0 {
+		return ttl
+	}
+	n := int64(float64(ttl) * c.ops.jitter)
+	if n <= 0 {
+		return ttl
+	}
+	return ttl + time.Duration(rand.Int63n(n))
+}
+
+// Get get the value and unmarshal into T
+func Get[T any](ctx context.Context, c *Cache, key string) (v T, err error) {
+	data, err := c.Get(ctx, key)
+	if err != nil {
+		return
+	}
+	err = c.ops.codec.Unmarshal(data, &v)
+	return
+}
+
+// Set marshal T and set the value
+func Set[T any](ctx context.Context, c *Cache, key string, v T, ttl time.Duration) (err error) {
+	data, err := c.ops.codec.Marshal(v)
+	if err != nil {
+		return
+	}
+	err = c.Set(ctx, key, data, ttl)
+	return
+}
+
+// GetOrLoad get value from cache, if missing, the loader will be called only once for concurrent requests(singleflight),
+// ErrNotFound(or WithNotFound matched error) from loader will be negative cached,
+// when redis is unavailable, the loader will be called directly
+func GetOrLoad[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, loader func(ctx context.Context) (T, error)) (v T, err error) {
+	data, err := c.get(ctx, key)
+	if err == nil {
+		err = c.ops.codec.Unmarshal(data, &v)
+		if err == nil {
+			return
+		}
+		log.WithContext(ctx).WithError(err).Warn("invalid cache data, key: %s", key)
+		err = errMiss
+	}
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return
+	case err != nil && !errors.Is(err, errMiss) && !errors.Is(err, ErrNoRedis):
+		log.WithContext(ctx).WithError(err).Warn("get cache failed, key: %s", key)
+	}
+	rp, err, _ := c.group.Do(key, func() (interface{}, error) {
+		atomic.AddUint64(&c.stats.load, 1)
+		item, e := loader(ctx)
+		if e != nil {
+			atomic.AddUint64(&c.stats.loadError, 1)
+			if c.ops.notFound(e) {
+				c.setNotFound(ctx, key)
+				e = ErrNotFound
+			}
+			return item, e
+		}
+		if e = Set(ctx, c, key, item, ttl); e != nil && !errors.Is(e, ErrNoRedis) {
+			log.WithContext(ctx).WithError(e).Warn("set cache failed, key: %s", key)
+		}
+		return item, nil
+	})
+	if rp != nil {
+		v = rp.(T)
+	}
+	return
+}
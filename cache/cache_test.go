@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type user struct {
+	Id   uint64 `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestGetOrLoad(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	c := New(WithRedis(client))
+	ctx := context.Background()
+
+	var count int32
+	loader := func(ctx context.Context) (*user, error) {
+		atomic.AddInt32(&count, 1)
+		time.Sleep(50 * time.Millisecond)
+		return &user{Id: 1, Name: "cinch"}, nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := GetOrLoad(ctx, c, "user.1", time.Minute, loader)
+			if err != nil || v.Name != "cinch" {
+				t.Errorf("GetOrLoad() = %v, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if count != 1 {
+		t.Fatalf("loader called %d times, want 1", count)
+	}
+	v, err := Get[user](ctx, c, "user.1")
+	if err != nil || v.Id != 1 {
+		t.Fatalf("Get() = %v, %v", v, err)
+	}
+	if ttl := s.TTL("cache.user.1"); ttl < time.Minute || ttl > time.Minute+6*time.Second {
+		t.Fatalf("ttl = %v", ttl)
+	}
+
+	// invalidation
+	if err = c.Del(ctx, "user.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.Get(ctx, "user.1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() after Del err = %v", err)
+	}
+}
+
+func TestGetOrLoad_NotFound(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	errRecordNotFound := errors.New("record not found")
+	c := New(
+		WithRedis(client),
+		WithNotFound(func(err error) bool {
+			return errors.Is(err, errRecordNotFound)
+		}),
+	)
+	ctx := context.Background()
+
+	var count int
+	loader := func(ctx context.Context) (user, error) {
+		count++
+		return user{}, errRecordNotFound
+	}
+	for i := 0; i < 3; i++ {
+		_, err := GetOrLoad(ctx, c, "user.2", 0, loader)
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("GetOrLoad() err = %v", err)
+		}
+	}
+	if count != 1 {
+		t.Fatalf("loader called %d times, want 1", count)
+	}
+}
+
+func TestGetOrLoad_NoRedis(t *testing.T) {
+	c := New()
+	v, err := GetOrLoad(context.Background(), c, "k", 0, func(ctx context.Context) (string, error) {
+		return "v", nil
+	})
+	if err != nil || v != "v" {
+		t.Fatalf("GetOrLoad() = %v, %v", v, err)
+	}
+}
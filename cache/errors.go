@@ -0,0 +1,11 @@
+package cache
+
+import "errors"
+
+var (
+	// ErrNotFound the key is not found, loader can return it to enable negative cache
+	ErrNotFound = errors.New("cache: not found")
+	ErrNoRedis  = errors.New("cache: redis is nil")
+
+	errMiss = errors.New("cache: miss")
+)
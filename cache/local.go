@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/go-cinch/common/log"
+	"github.com/redis/go-redis/v9"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is the hit/miss counter of each tier
+type Stats struct {
+	LocalHit  uint64 `json:"localHit"`
+	LocalMiss uint64 `json:"localMiss"`
+	RedisHit  uint64 `json:"redisHit"`
+	RedisMiss uint64 `json:"redisMiss"`
+	Load      uint64 `json:"load"`
+	LoadError uint64 `json:"loadError"`
+	LocalSize int    `json:"localSize"`
+}
+
+type stats struct {
+	localHit  uint64
+	localMiss uint64
+	redisHit  uint64
+	redisMiss uint64
+	load      uint64
+	loadError uint64
+}
+
+// invalidation is the pub/sub message, keep local tier of all instances coherent
+type invalidation struct {
+	Id   string   `json:"id"`
+	Keys []string `json:"keys"`
+}
+
+// Stats get hit/miss metrics, can be exported to prometheus or log
+func (c *Cache) Stats() (rp Stats) {
+	rp.LocalHit = atomic.LoadUint64(&c.stats.localHit)
+	rp.LocalMiss = atomic.LoadUint64(&c.stats.localMiss)
+	rp.RedisHit = atomic.LoadUint64(&c.stats.redisHit)
+	rp.RedisMiss = atomic.LoadUint64(&c.stats.redisMiss)
+	rp.Load = atomic.LoadUint64(&c.stats.load)
+	rp.LoadError = atomic.LoadUint64(&c.stats.loadError)
+	if c.local != nil {
+		rp.LocalSize = c.local.len()
+	}
+	return
+}
+
+// Close stop subscribing invalidation messages
+func (c *Cache) Close() (err error) {
+	if c.ps == nil {
+		return
+	}
+	err = c.ps.Close()
+	return
+}
+
+// subscribe receive invalidation messages from other instances, local tier will be purged when reconnect
+func (c *Cache) subscribe() {
+	ctx := context.Background()
+	c.id = instanceId()
+	c.ps = c.ops.redis.Subscribe(ctx, c.ops.channel)
+	go func() {
+		for {
+			msg, err := c.ps.Receive(ctx)
+			if err != nil {
+				if err == redis.ErrClosed {
+					return
+				}
+				// messages may be lost during disconnection
+				c.local.purge()
+				time.Sleep(time.Second)
+				continue
+			}
+			m, ok := msg.(*redis.Message)
+			if !ok {
+				continue
+			}
+			var item invalidation
+			err = json.Unmarshal([]byte(m.Payload), &item)
+			if err != nil {
+				log.WithContext(ctx).WithError(err).Warn("invalid cache invalidation message")
+				continue
+			}
+			if item.Id == c.id {
+				continue
+			}
+			c.local.del(item.Keys...)
+		}
+	}()
+}
+
+// publish notify other instances to remove local keys
+func (c *Cache) publish(ctx context.Context, keys ...string) {
+	if c.ps == nil {
+		return
+	}
+	bs, _ := json.Marshal(invalidation{
+		Id:   c.id,
+		Keys: keys,
+	})
+	err := c.ops.redis.Publish(ctx, c.ops.channel, bs).Err()
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("publish cache invalidation failed")
+	}
+}
+
+func instanceId() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"testing"
+	"time"
+)
+
+func TestLocal(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	ctx := context.Background()
+	c1 := New(WithRedis(client), WithLocal(100, 60))
+	defer c1.Close()
+	c2 := New(WithRedis(client), WithLocal(100, 60))
+	defer c2.Close()
+
+	if err := Set(ctx, c1, "dict", "v1", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		v, err := Get[string](ctx, c2, "dict")
+		if err != nil || v != "v1" {
+			t.Fatalf("Get() = %v, %v", v, err)
+		}
+	}
+	st := c2.Stats()
+	if st.LocalHit != 1 || st.LocalMiss != 1 || st.RedisHit != 1 || st.LocalSize != 1 {
+		t.Fatalf("Stats() = %+v", st)
+	}
+
+	// c2 local tier should be invalidated by pub/sub
+	if err := Set(ctx, c1, "dict", "v2", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		v, _ := Get[string](ctx, c2, "dict")
+		if v == "v2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("local tier not invalidated, got %s", v)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := c1.Del(ctx, "dict"); err != nil {
+		t.Fatal(err)
+	}
+	deadline = time.Now().Add(2 * time.Second)
+	for c2.Stats().LocalSize != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("local tier not invalidated after Del")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLru(t *testing.T) {
+	l := newLru(2, 50*time.Millisecond)
+	l.set("a", []byte("1"))
+	l.set("b", []byte("2"))
+	l.get("a")
+	l.set("c", []byte("3"))
+	if _, ok := l.get("b"); ok {
+		t.Fatal("b should be evicted")
+	}
+	if _, ok := l.get("a"); !ok {
+		t.Fatal("a should exist")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if _, ok := l.get("a"); ok {
+		t.Fatal("a should be expired")
+	}
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lru is the in-process cache tier, entries will be evicted by size and ttl
+type lru struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type entry struct {
+	key    string
+	data   []byte
+	expire time.Time
+}
+
+func newLru(size int, ttl time.Duration) *lru {
+	return &lru{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (l *lru) get(key string) (data []byte, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	el, ok := l.items[key]
+	if !ok {
+		return
+	}
+	e := el.Value.(*entry)
+	if time.Now().After(e.expire) {
+		l.remove(el)
+		ok = false
+		return
+	}
+	l.ll.MoveToFront(el)
+	data = e.data
+	return
+}
+
+func (l *lru) set(key string, data []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	expire := time.Now().Add(l.ttl)
+	if el, ok := l.items[key]; ok {
+		e := el.Value.(*entry)
+		e.data = data
+		e.expire = expire
+		l.ll.MoveToFront(el)
+		return
+	}
+	l.items[key] = l.ll.PushFront(&entry{
+		key:    key,
+		data:   data,
+		expire: expire,
+	})
+	for l.ll.Len() > l.size {
+		l.remove(l.ll.Back())
+	}
+}
+
+func (l *lru) del(keys ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		if el, ok := l.items[key]; ok {
+			l.remove(el)
+		}
+	}
+}
+
+func (l *lru) purge() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ll.Init()
+	l.items = make(map[string]*list.Element)
+}
+
+func (l *lru) len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ll.Len()
+}
+
+func (l *lru) remove(el *list.Element) {
+	l.ll.Remove(el)
+	delete(l.items, el.Value.(*entry).key)
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// Codec marshal/unmarshal cache value, default json
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type Options struct {
+	redis          redis.UniversalClient
+	prefix         string
+	expire         int
+	notFoundExpire int
+	jitter         float64
+	codec          Codec
+	notFound       func(err error) bool
+	localSize      int
+	localExpire    int
+	channel        string
+}
+
+func WithRedis(rd redis.UniversalClient) func(*Options) {
+	return func(options *Options) {
+		if rd != nil {
+			getOptionsOrSetDefault(options).redis = rd
+		}
+	}
+}
+
+func WithPrefix(prefix string) func(*Options) {
+	return func(options *Options) {
+		if prefix != "" {
+			getOptionsOrSetDefault(options).prefix = prefix
+		}
+	}
+}
+
+// WithExpire default expire seconds when ttl is 0
+func WithExpire(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).expire = second
+		}
+	}
+}
+
+// WithNotFoundExpire negative cache expire seconds, 0 means disable negative cache
+func WithNotFoundExpire(second int) func(*Options) {
+	return func(options *Options) {
+		if second >= 0 {
+			getOptionsOrSetDefault(options).notFoundExpire = second
+		}
+	}
+}
+
+// WithJitter add random [0, ttl*ratio) to ttl, avoid keys expire at the same time
+func WithJitter(ratio float64) func(*Options) {
+	return func(options *Options) {
+		if ratio >= 0 && ratio <= 1 {
+			getOptionsOrSetDefault(options).jitter = ratio
+		}
+	}
+}
+
+func WithCodec(codec Codec) func(*Options) {
+	return func(options *Options) {
+		if codec != nil {
+			getOptionsOrSetDefault(options).codec = codec
+		}
+	}
+}
+
+// WithNotFound judge whether the loader error means not found, e.g. gorm.ErrRecordNotFound
+func WithNotFound(f func(err error) bool) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).notFound = f
+		}
+	}
+}
+
+// WithLocal enable in-process lru tier in front of redis, size is the max entries, second is the local expire
+func WithLocal(size, second int) func(*Options) {
+	return func(options *Options) {
+		if size > 0 && second > 0 {
+			getOptionsOrSetDefault(options).localSize = size
+			getOptionsOrSetDefault(options).localExpire = second
+		}
+	}
+}
+
+// WithChannel redis pub/sub channel of local tier invalidation, default {prefix}.invalidate
+func WithChannel(channel string) func(*Options) {
+	return func(options *Options) {
+		if channel != "" {
+			getOptionsOrSetDefault(options).channel = channel
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			prefix:         "cache",
+			expire:         300,
+			notFoundExpire: 60,
+			jitter:         0.1,
+			codec:          jsonCodec{},
+			notFound: func(err error) bool {
+				return errors.Is(err, ErrNotFound)
+			},
+		}
+	}
+	return options
+}
@@ -20,8 +20,11 @@ func New(options ...func(*Options)) *Captcha {
 		ops: *ops,
 	}
 	ca.store = NewStore(options...)
-	width := ops.num * 45
-	ca.c = base64Captcha.NewCaptcha(base64Captcha.NewDriverDigit(80, width, ops.num, 0.7, 80), ca.store)
+	driver := ops.driver
+	if driver == nil {
+		driver = DigitDriver(ops.num)
+	}
+	ca.c = base64Captcha.NewCaptcha(driver, ca.store)
 	return ca
 }
 
@@ -34,6 +37,7 @@ func (ca Captcha) Get() (id, img string) {
 	return
 }
 
+// Verify check answer by id, the captcha will be removed after verify whether pass or not
 func (ca Captcha) Verify(id, answer string) (pass bool) {
 	if answer == "" {
 		return
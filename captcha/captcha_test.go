@@ -0,0 +1,50 @@
+package captcha
+
+import (
+	"github.com/alicebob/miniredis/v2"
+	"github.com/mojocn/base64Captcha"
+	"github.com/redis/go-redis/v9"
+	"testing"
+)
+
+func TestCaptcha_Verify(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	tests := []struct {
+		name   string
+		driver base64Captcha.Driver
+	}{
+		{
+			name:   "digit",
+			driver: DigitDriver(4),
+		},
+		{
+			name:   "math",
+			driver: MathDriver(),
+		},
+		{
+			name:   "string",
+			driver: StringDriver(6),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New(WithRedis(client), WithDriver(tt.driver))
+			id, img := c.Get()
+			if id == "" || img == "" {
+				t.Fatal("Get() failed")
+			}
+			answer, err := s.Get("captcha_" + id)
+			if err != nil {
+				t.Fatalf("answer not found: %v", err)
+			}
+			if !c.Verify(id, answer) {
+				t.Fatal("Verify() = false, want true")
+			}
+			// one-shot
+			if c.Verify(id, answer) {
+				t.Fatal("Verify() again = true, want false")
+			}
+		})
+	}
+}
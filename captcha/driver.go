@@ -0,0 +1,39 @@
+package captcha
+
+import (
+	"github.com/mojocn/base64Captcha"
+	"image/color"
+)
+
+// DigitDriver image captcha with num digits
+func DigitDriver(num int) base64Captcha.Driver {
+	return base64Captcha.NewDriverDigit(80, num*45, num, 0.7, 80)
+}
+
+// MathDriver image captcha with simple arithmetic, answer is the result
+func MathDriver() base64Captcha.Driver {
+	return base64Captcha.NewDriverMath(
+		80,
+		240,
+		5,
+		base64Captcha.OptionShowHollowLine|base64Captcha.OptionShowSlimeLine,
+		&color.RGBA{R: 255, G: 255, B: 255, A: 255},
+		nil,
+		nil,
+	)
+}
+
+// StringDriver image captcha with num letters and numbers
+func StringDriver(num int) base64Captcha.Driver {
+	return base64Captcha.NewDriverString(
+		80,
+		num*45,
+		5,
+		base64Captcha.OptionShowHollowLine|base64Captcha.OptionShowSlimeLine,
+		num,
+		base64Captcha.TxtNumbers+base64Captcha.TxtAlphabet,
+		&color.RGBA{R: 255, G: 255, B: 255, A: 255},
+		nil,
+		nil,
+	)
+}
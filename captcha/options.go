@@ -2,6 +2,7 @@ package captcha
 
 import (
 	"context"
+	"github.com/mojocn/base64Captcha"
 	"github.com/redis/go-redis/v9"
 	"reflect"
 )
@@ -12,6 +13,7 @@ type Options struct {
 	prefix string
 	expire int
 	num    int
+	driver base64Captcha.Driver
 }
 
 func WithCtx(ctx context.Context) func(*Options) {
@@ -52,6 +54,15 @@ func WithNum(num int) func(*Options) {
 	}
 }
 
+// WithDriver custom captcha driver, such as MathDriver()/StringDriver(6) or any base64Captcha.Driver
+func WithDriver(driver base64Captcha.Driver) func(*Options) {
+	return func(options *Options) {
+		if driver != nil {
+			getOptionsOrSetDefault(options).driver = driver
+		}
+	}
+}
+
 func getOptionsOrSetDefault(options *Options) *Options {
 	if options == nil {
 		return &Options{
@@ -39,10 +39,10 @@ func NewStore(options ...func(*Options)) *Store {
 		ops:      *ops,
 		duration: time.Duration(ops.expire) * time.Minute,
 	}
-	if ops.redis == nil && memory == nil {
-		memory = base64Captcha.NewMemoryStore(100, st.duration)
-	}
-	if st.memory == nil {
+	if ops.redis == nil {
+		if memory == nil {
+			memory = base64Captcha.NewMemoryStore(100, st.duration)
+		}
 		st.memory = memory
 	}
 	return st
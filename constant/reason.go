@@ -6,16 +6,18 @@ const (
 	JwtTokenExpired           = "jwt.token.expired"
 	JwtTokenParseFail         = "jwt.token.parse.failed"
 	JwtUnSupportSigningMethod = "jwt.wrong.signing.method"
+	JwtTokenRevoked           = "jwt.token.revoked"
 	IdempotentMissingToken    = "idempotent.token.missing"
 	IdempotentTokenExpired    = "idempotent.token.invalid"
 
-	TooManyRequests  = "too.many.requests"
-	DataNotChange    = "data.not.change"
-	DuplicateField   = "duplicate.field"
-	RecordNotFound   = "record.not.found"
-	NoPermission     = "no.permission"
-	InternalError    = "internal.error"
-	IllegalParameter = "illegal.parameter"
+	TooManyRequests    = "too.many.requests"
+	DataNotChange      = "data.not.change"
+	DuplicateField     = "duplicate.field"
+	RecordNotFound     = "record.not.found"
+	NoPermission       = "no.permission"
+	InternalError      = "internal.error"
+	IllegalParameter   = "illegal.parameter"
+	ServiceUnavailable = "service.unavailable"
 
 	IncorrectPassword  = "login.incorrect.password"
 	SamePassword       = "login.same.password"
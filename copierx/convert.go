@@ -0,0 +1,254 @@
+package copierx
+
+import (
+	"database/sql"
+	"github.com/golang-module/carbon/v2"
+	"github.com/jinzhu/copier"
+	"time"
+)
+
+var (
+	// TimeConverters time.Time <-> string/carbon.DateTime/carbon.Date
+	TimeConverters = []copier.TypeConverter{
+		{
+			SrcType: time.Time{},
+			DstType: copier.String,
+			Fn:      timeToString,
+		},
+		{
+			SrcType: copier.String,
+			DstType: time.Time{},
+			Fn:      stringToTime,
+		},
+		{
+			SrcType: time.Time{},
+			DstType: carbon.DateTime{},
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = carbon.DateTime{Carbon: carbon.CreateFromStdTime(src.(time.Time))}
+				return
+			},
+		},
+		{
+			SrcType: time.Time{},
+			DstType: carbon.Date{},
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = carbon.Date{Carbon: carbon.CreateFromStdTime(src.(time.Time))}
+				return
+			},
+		},
+		{
+			SrcType: carbon.DateTime{},
+			DstType: time.Time{},
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = carbonToTime(src.(carbon.DateTime).Carbon)
+				return
+			},
+		},
+		{
+			SrcType: carbon.Date{},
+			DstType: time.Time{},
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = carbonToTime(src.(carbon.Date).Carbon)
+				return
+			},
+		},
+	}
+	// NullConverters sql.NullXxx <-> basic type, invalid null value will be converted to zero value
+	NullConverters = []copier.TypeConverter{
+		{
+			SrcType: sql.NullString{},
+			DstType: copier.String,
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = src.(sql.NullString).String
+				return
+			},
+		},
+		{
+			SrcType: copier.String,
+			DstType: sql.NullString{},
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				v := src.(string)
+				rp = sql.NullString{String: v, Valid: v != ""}
+				return
+			},
+		},
+		{
+			SrcType: sql.NullInt64{},
+			DstType: copier.Int,
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = int(src.(sql.NullInt64).Int64)
+				return
+			},
+		},
+		{
+			SrcType: sql.NullInt64{},
+			DstType: int64(0),
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = src.(sql.NullInt64).Int64
+				return
+			},
+		},
+		{
+			SrcType: int64(0),
+			DstType: sql.NullInt64{},
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = sql.NullInt64{Int64: src.(int64), Valid: true}
+				return
+			},
+		},
+		{
+			SrcType: sql.NullInt32{},
+			DstType: int32(0),
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = src.(sql.NullInt32).Int32
+				return
+			},
+		},
+		{
+			SrcType: int32(0),
+			DstType: sql.NullInt32{},
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = sql.NullInt32{Int32: src.(int32), Valid: true}
+				return
+			},
+		},
+		{
+			SrcType: sql.NullFloat64{},
+			DstType: copier.Float64,
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = src.(sql.NullFloat64).Float64
+				return
+			},
+		},
+		{
+			SrcType: copier.Float64,
+			DstType: sql.NullFloat64{},
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = sql.NullFloat64{Float64: src.(float64), Valid: true}
+				return
+			},
+		},
+		{
+			SrcType: sql.NullBool{},
+			DstType: copier.Bool,
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = src.(sql.NullBool).Bool
+				return
+			},
+		},
+		{
+			SrcType: copier.Bool,
+			DstType: sql.NullBool{},
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = sql.NullBool{Bool: src.(bool), Valid: true}
+				return
+			},
+		},
+		{
+			SrcType: sql.NullTime{},
+			DstType: time.Time{},
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = src.(sql.NullTime).Time
+				return
+			},
+		},
+		{
+			SrcType: time.Time{},
+			DstType: sql.NullTime{},
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				v := src.(time.Time)
+				rp = sql.NullTime{Time: v, Valid: !v.IsZero()}
+				return
+			},
+		},
+		{
+			SrcType: sql.NullTime{},
+			DstType: copier.String,
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				v := src.(sql.NullTime)
+				rp = ""
+				if v.Valid {
+					rp, err = timeToString(v.Time)
+				}
+				return
+			},
+		},
+		{
+			SrcType: copier.String,
+			DstType: sql.NullTime{},
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				var v interface{}
+				v, err = stringToTime(src)
+				t := v.(time.Time)
+				rp = sql.NullTime{Time: t, Valid: !t.IsZero()}
+				return
+			},
+		},
+	}
+)
+
+func timeToString(src interface{}) (rp interface{}, err error) {
+	rp = ""
+	if v, ok := src.(time.Time); ok && !v.IsZero() {
+		rp = carbon.CreateFromStdTime(v).ToDateTimeString()
+	}
+	return
+}
+
+func stringToTime(src interface{}) (rp interface{}, err error) {
+	rp = time.Time{}
+	if v, ok := src.(string); ok && v != "" {
+		c := carbon.Parse(v)
+		if c.Error == nil {
+			rp = c.ToStdTime()
+		}
+	}
+	return
+}
+
+func carbonToTime(c carbon.Carbon) time.Time {
+	if c.IsZero() || c.Error != nil {
+		return time.Time{}
+	}
+	return c.ToStdTime()
+}
+
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+// Enum enum <-> string by names, unknown string will be converted to zero value
+func Enum[T integer](names map[T]string) []copier.TypeConverter {
+	values := make(map[string]T, len(names))
+	for k, v := range names {
+		values[v] = k
+	}
+	var zero T
+	return []copier.TypeConverter{
+		{
+			SrcType: zero,
+			DstType: copier.String,
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = names[src.(T)]
+				return
+			},
+		},
+		{
+			SrcType: copier.String,
+			DstType: zero,
+			Fn: func(src interface{}) (rp interface{}, err error) {
+				rp = values[src.(string)]
+				return
+			},
+		},
+	}
+}
+
+// ProtoEnum proto enum <-> string by generated XXX_name map, e.g. ProtoEnum[pb.Status](pb.Status_name)
+func ProtoEnum[T ~int32](names map[int32]string) []copier.TypeConverter {
+	m := make(map[T]string, len(names))
+	for k, v := range names {
+		m[T(k)] = v
+	}
+	return Enum(m)
+}
@@ -65,11 +65,23 @@ func stringToCarbonDate(src interface{}) (rp interface{}, err error) {
 	return
 }
 
+// Converters get all built-in converters, the custom converters will be appended(the later one has higher priority)
+func Converters(converters ...copier.TypeConverter) (rp []copier.TypeConverter) {
+	rp = make([]copier.TypeConverter, 0, len(CarbonToString)+len(StringToCarbon)+len(TimeConverters)+len(NullConverters)+len(converters))
+	rp = append(rp, CarbonToString...)
+	rp = append(rp, StringToCarbon...)
+	rp = append(rp, TimeConverters...)
+	rp = append(rp, NullConverters...)
+	rp = append(rp, converters...)
+	return
+}
+
 func Copy(to interface{}, from interface{}) (err error) {
-	return copier.CopyWithOption(to, from, copier.Option{Converters: append(CarbonToString, StringToCarbon...)})
+	return copier.CopyWithOption(to, from, copier.Option{Converters: Converters()})
 }
 
+// CopyWithOption copy with custom option, opt.Converters will be kept with higher priority than built-in converters
 func CopyWithOption(to interface{}, from interface{}, opt copier.Option) (err error) {
-	opt.Converters = append(CarbonToString, StringToCarbon...)
+	opt.Converters = Converters(opt.Converters...)
 	return copier.CopyWithOption(to, from, opt)
 }
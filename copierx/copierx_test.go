@@ -0,0 +1,118 @@
+package copierx
+
+import (
+	"database/sql"
+	"github.com/golang-module/carbon/v2"
+	"github.com/jinzhu/copier"
+	"testing"
+	"time"
+)
+
+type status int32
+
+const (
+	statusDisable status = iota
+	statusEnable
+)
+
+var statusName = map[int32]string{
+	0: "DISABLE",
+	1: "ENABLE",
+}
+
+type model struct {
+	Id        uint64
+	Name      sql.NullString
+	Age       sql.NullInt64
+	Status    status
+	Password  string
+	CreatedAt time.Time
+	UpdatedAt carbon.DateTime
+}
+
+type dto struct {
+	Id        uint64
+	Nick      string `copier:"Name"`
+	Age       int64
+	Status    string
+	Password  string `copier:"-"`
+	CreatedAt string
+	UpdatedAt time.Time
+}
+
+func TestCopy(t *testing.T) {
+	now := time.Date(2023, 1, 2, 3, 4, 5, 0, time.Local)
+	m := model{
+		Id:        1,
+		Name:      sql.NullString{String: "cinch", Valid: true},
+		Age:       sql.NullInt64{Int64: 18, Valid: true},
+		Status:    statusEnable,
+		Password:  "secret",
+		CreatedAt: now,
+		UpdatedAt: carbon.DateTime{Carbon: carbon.CreateFromStdTime(now)},
+	}
+	var d dto
+	err := CopyWithOption(&d, m, copier.Option{Converters: ProtoEnum[status](statusName)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Nick != "cinch" || d.Age != 18 || d.Status != "ENABLE" || d.Password != "" || d.CreatedAt != "2023-01-02 03:04:05" || !d.UpdatedAt.Equal(now) {
+		t.Fatalf("Copy() = %+v", d)
+	}
+
+	var m2 model
+	err = CopyWithOption(&m2, d, copier.Option{Converters: ProtoEnum[status](statusName)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m2.Age.Int64 != 18 || !m2.Age.Valid || m2.Status != statusEnable || !m2.CreatedAt.Equal(now) || m2.UpdatedAt.ToDateTimeString() != "2023-01-02 03:04:05" {
+		t.Fatalf("Copy() = %+v", m2)
+	}
+
+	var m3 model
+	err = Copy(&m3, dto{Status: "UNKNOWN"})
+	if err != nil || m3.Status != statusDisable || !m3.CreatedAt.IsZero() || m3.Name.Valid {
+		t.Fatalf("Copy() = %+v, %v", m3, err)
+	}
+}
+
+func TestCopyStrict(t *testing.T) {
+	type from struct {
+		Id   uint64
+		Name string
+	}
+	type to struct {
+		Id     uint64
+		Name   string
+		Remark string `copierx:"optional"`
+		Ignore string `copier:"-"`
+	}
+	type toMissing struct {
+		Id    uint64
+		Title string
+	}
+	var a to
+	if err := CopyStrict(&a, from{Id: 1, Name: "a"}); err != nil || a.Name != "a" {
+		t.Fatalf("CopyStrict() = %+v, %v", a, err)
+	}
+	var b toMissing
+	if err := CopyStrict(&b, from{Id: 1}); err == nil {
+		t.Fatal("CopyStrict() unmapped field should fail")
+	}
+	var c []toMissing
+	if err := CopyStrict(&c, []from{{Id: 1}}); err == nil {
+		t.Fatal("CopyStrict() unmapped field of slice should fail")
+	}
+	err := CopyStrictWithOption(&b, from{Id: 1, Name: "b"}, copier.Option{
+		FieldNameMapping: []copier.FieldNameMapping{
+			{
+				SrcType: from{},
+				DstType: toMissing{},
+				Mapping: map[string]string{"Name": "Title"},
+			},
+		},
+	})
+	if err != nil || b.Title != "b" {
+		t.Fatalf("CopyStrictWithOption() = %+v, %v", b, err)
+	}
+}
@@ -0,0 +1,144 @@
+package copierx
+
+import (
+	"fmt"
+	"github.com/jinzhu/copier"
+	"reflect"
+	"strings"
+)
+
+const (
+	tagCopier  = "copier"
+	tagCopierx = "copierx"
+	// tagOptional the dest field can be unmapped in strict mode
+	tagOptional = "optional"
+)
+
+// CopyStrict copy and return error if any exported dest field has no source field/method to map,
+// fields with `copier:"-"` or `copierx:"optional"` tag are skipped
+func CopyStrict(to interface{}, from interface{}) (err error) {
+	return CopyStrictWithOption(to, from, copier.Option{})
+}
+
+func CopyStrictWithOption(to interface{}, from interface{}, opt copier.Option) (err error) {
+	err = check(indirectType(reflect.TypeOf(to)), indirectType(reflect.TypeOf(from)), opt)
+	if err != nil {
+		return
+	}
+	err = CopyWithOption(to, from, opt)
+	return
+}
+
+func check(to, from reflect.Type, opt copier.Option) (err error) {
+	if to == nil || from == nil || to.Kind() != reflect.Struct || from.Kind() != reflect.Struct {
+		return
+	}
+	mapping := make(map[string]string)
+	for _, item := range opt.FieldNameMapping {
+		if reflect.TypeOf(item.SrcType) == from && reflect.TypeOf(item.DstType) == to {
+			for k, v := range item.Mapping {
+				mapping[v] = k
+			}
+		}
+	}
+	names := sourceNames(from, opt.CaseSensitive)
+	missing := make([]string, 0)
+	for _, field := range deepFields(to) {
+		tag := field.Tag.Get(tagCopier)
+		if tag == "-" || hasTag(field.Tag.Get(tagCopierx), tagOptional) {
+			continue
+		}
+		name := field.Name
+		if v := tagName(tag); v != "" {
+			name = v
+		}
+		if v, ok := mapping[field.Name]; ok {
+			name = v
+		}
+		if !opt.CaseSensitive {
+			name = strings.ToLower(name)
+		}
+		if _, ok := names[name]; !ok {
+			missing = append(missing, field.Name)
+		}
+	}
+	if len(missing) > 0 {
+		err = fmt.Errorf("copierx: %s fields %s are not mapped from %s", to.Name(), strings.Join(missing, ","), from.Name())
+	}
+	return
+}
+
+// sourceNames get all source field names, field tag names and getter method names
+func sourceNames(from reflect.Type, caseSensitive bool) (names map[string]struct{}) {
+	names = make(map[string]struct{})
+	add := func(name string) {
+		if !caseSensitive {
+			name = strings.ToLower(name)
+		}
+		names[name] = struct{}{}
+	}
+	for _, field := range deepFields(from) {
+		tag := field.Tag.Get(tagCopier)
+		if tag == "-" {
+			continue
+		}
+		add(field.Name)
+		if v := tagName(tag); v != "" {
+			add(v)
+		}
+	}
+	ptr := reflect.PtrTo(from)
+	for i := 0; i < ptr.NumMethod(); i++ {
+		m := ptr.Method(i)
+		// receiver is the first param
+		if m.Type.NumIn() == 1 && m.Type.NumOut() == 1 {
+			add(m.Name)
+		}
+	}
+	return
+}
+
+// deepFields get exported fields, anonymous struct fields are flattened like copier
+func deepFields(t reflect.Type) (fields []reflect.StructField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		ft := indirectType(field.Type)
+		if field.Anonymous && ft.Kind() == reflect.Struct {
+			fields = append(fields, deepFields(ft)...)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	return t
+}
+
+// tagName get the rename of copier tag, e.g. `copier:"Name"`
+func tagName(tag string) (name string) {
+	for _, item := range strings.Split(tag, ",") {
+		switch item {
+		case "", "-", "must", "nopanic", "override":
+		default:
+			name = item
+		}
+	}
+	return
+}
+
+func hasTag(tag, name string) bool {
+	for _, item := range strings.Split(tag, ",") {
+		if item == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package cron
+
+import (
+	"github.com/gorhill/cronexpr"
+	"github.com/pkg/errors"
+	"strings"
+	"time"
+)
+
+// MaxOccurrences is the max count returned by Between
+const MaxOccurrences = 1000
+
+// Parse parse expr of gorhill/cronexpr, seconds and year fields are optional
+func Parse(expr string) (e *cronexpr.Expression, err error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		err = ErrExprNil
+		return
+	}
+	e, err = cronexpr.Parse(expr)
+	if err != nil {
+		err = errors.Wrap(ErrExprInvalid, err.Error())
+	}
+	return
+}
+
+// Validate check expr syntax and it has next occurrence
+func Validate(expr string) (err error) {
+	_, err = Next(expr, time.Now())
+	return
+}
+
+// Next get the first occurrence after from
+func Next(expr string, from time.Time) (next time.Time, err error) {
+	e, err := Parse(expr)
+	if err != nil {
+		return
+	}
+	next = e.Next(from)
+	if next.IsZero() {
+		err = ErrNoOccurrence
+	}
+	return
+}
+
+// Between get occurrences in (from, to], at most MaxOccurrences
+func Between(expr string, from, to time.Time) (rp []time.Time, err error) {
+	e, err := Parse(expr)
+	if err != nil {
+		return
+	}
+	rp = make([]time.Time, 0)
+	t := from
+	for len(rp) < MaxOccurrences {
+		t = e.Next(t)
+		if t.IsZero() || t.After(to) {
+			break
+		}
+		rp = append(rp, t)
+	}
+	return
+}
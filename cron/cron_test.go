@@ -0,0 +1,74 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	for _, expr := range []string{"0/1 * * * ?", "0 30 8 * * 1-5 *", "0 0 0 L * ? *", "@daily"} {
+		if err := Validate(expr); err != nil {
+			t.Fatalf("Validate(%s) error = %v", expr, err)
+		}
+	}
+	if err := Validate(""); err != ErrExprNil {
+		t.Fatalf("expect expr nil but got %v", err)
+	}
+	if err := Validate("* * *"); !errors.Is(err, ErrExprInvalid) {
+		t.Fatalf("expect expr invalid but got %v", err)
+	}
+	if err := Validate("0 0 1 1 * 2000"); err != ErrNoOccurrence {
+		t.Fatalf("expect no occurrence but got %v", err)
+	}
+}
+
+func TestBetween(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	list, err := Between("0 9 * * 1-5", from, from.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2024-01-01 is monday
+	if len(list) != 5 || !list[0].Equal(from.Add(9*time.Hour)) || list[4].Weekday() != time.Friday {
+		t.Fatalf("unexpected occurrences %v", list)
+	}
+	list, _ = Between("* * * * * * *", from, from.AddDate(0, 0, 1))
+	if len(list) != MaxOccurrences {
+		t.Fatalf("unexpected occurrences count %d", len(list))
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	cases := []struct {
+		expr string
+		en   string
+		zh   string
+	}{
+		{"* * * * *", "Every minute", "每分钟"},
+		{"*/5 * * * *", "Every 5 minutes", "每 5 分钟"},
+		{"30 8 * * 1-5", "At 08:30, on Monday through Friday", "周一至周五，08:30"},
+		{"15 * * * *", "At minute 15 of every hour", "每小时第 15 分钟"},
+		{"0 0 L * *", "At 00:00, on the last day of the month", "每月最后一天，00:00"},
+		{"0 10 * * 5#2", "At 10:00, on the second Friday of the month", "每月第 2 个周五，10:00"},
+		{"0 0 1 1,6 *", "At 00:00, on day 1 of the month, in January and June", "1月、6月，每月 1 号，00:00"},
+		{"0 9-17 * * MON-FRI", "At minute 0 of every hour between 09:00 and 17:59, on Monday through Friday", "周一至周五，09:00至17:59每小时第 0 分钟"},
+		{"@weekly", "At 00:00, on Sunday", "周日，00:00"},
+	}
+	for _, item := range cases {
+		en, err := Describe(item.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if en != item.en {
+			t.Fatalf("Describe(%s) = %s, want %s", item.expr, en, item.en)
+		}
+		zh, _ := Describe(item.expr, LangZh)
+		if zh != item.zh {
+			t.Fatalf("Describe(%s, zh) = %s, want %s", item.expr, zh, item.zh)
+		}
+	}
+	if _, err := Describe("* * *"); !errors.Is(err, ErrExprInvalid) {
+		t.Fatalf("expect expr invalid but got %v", err)
+	}
+}
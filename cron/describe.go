@@ -0,0 +1,418 @@
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	LangEn = "en"
+	LangZh = "zh"
+)
+
+const (
+	fieldSecond = iota
+	fieldMinute
+	fieldHour
+	fieldDom
+	fieldMonth
+	fieldDow
+	fieldYear
+)
+
+var macros = map[string]string{
+	"@yearly":   "0 0 0 1 1 * *",
+	"@annually": "0 0 0 1 1 * *",
+	"@monthly":  "0 0 0 1 * * *",
+	"@weekly":   "0 0 0 * * 0 *",
+	"@daily":    "0 0 0 * * * *",
+	"@midnight": "0 0 0 * * * *",
+	"@hourly":   "0 0 * * * * *",
+}
+
+var (
+	monthNames = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	dowNames   = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+	ordinals   = []string{"", "first", "second", "third", "fourth", "fifth"}
+	fieldMax   = []int{59, 59, 23, 31, 12, 6, 2099}
+)
+
+type phrases struct {
+	unit     []string
+	units    []string
+	list     []string
+	through  string
+	join     string
+	last     string
+	sep      string
+	months   []string
+	weekdays []string
+}
+
+var langs = map[string]phrases{
+	LangEn: {
+		unit:     []string{"second", "minute", "hour", "day", "month", "day", "year"},
+		units:    []string{"seconds", "minutes", "hours", "days", "months", "days", "years"},
+		list:     []string{"at second %s", "at minute %s", "at hour %s", "on day %s of the month", "in %s", "on %s", "in %s"},
+		through:  "%s through %s",
+		join:     ", ",
+		last:     " and ",
+		sep:      ", ",
+		months:   []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		weekdays: []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	},
+	LangZh: {
+		unit:     []string{"秒", "分钟", "小时", "天", "月", "天", "年"},
+		units:    []string{"秒", "分钟", "小时", "天", "个月", "天", "年"},
+		list:     []string{"第 %s 秒", "第 %s 分钟", "%s 点", "每月 %s 号", "%s", "%s", "%s 年"},
+		through:  "%s至%s",
+		join:     "、",
+		last:     "、",
+		sep:      "，",
+		months:   []string{"1月", "2月", "3月", "4月", "5月", "6月", "7月", "8月", "9月", "10月", "11月", "12月"},
+		weekdays: []string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"},
+	},
+}
+
+// item is one comma separated part of field
+type item struct {
+	all  bool
+	from int
+	to   int
+	step int
+}
+
+// Describe explain expr in human-readable text, lang is en(default) or zh
+func Describe(expr string, lang ...string) (rp string, err error) {
+	_, err = Parse(expr)
+	if err != nil {
+		return
+	}
+	p := langs[LangEn]
+	zh := len(lang) > 0 && strings.HasPrefix(strings.ToLower(lang[0]), LangZh)
+	if zh {
+		p = langs[LangZh]
+	}
+	fields := normalize(expr)
+	parts := []string{
+		p.time(fields[fieldSecond], fields[fieldMinute], fields[fieldHour], zh),
+		p.dom(fields[fieldDom], zh),
+		p.dow(fields[fieldDow], zh),
+		p.field(fields[fieldMonth], fieldMonth, zh),
+		p.field(fields[fieldYear], fieldYear, zh),
+	}
+	if zh {
+		// larger unit first
+		for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+			parts[i], parts[j] = parts[j], parts[i]
+		}
+	}
+	list := make([]string, 0, len(parts))
+	for _, item := range parts {
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	rp = strings.Join(list, p.sep)
+	if !zh && rp != "" {
+		rp = strings.ToUpper(rp[:1]) + rp[1:]
+	}
+	return
+}
+
+// normalize expand macro and fill optional fields, the same as cronexpr
+func normalize(expr string) []string {
+	expr = strings.TrimSpace(expr)
+	if v, ok := macros[strings.ToLower(expr)]; ok {
+		expr = v
+	}
+	fields := strings.Fields(expr)
+	switch len(fields) {
+	case 5:
+		fields = append(append([]string{"0"}, fields...), "*")
+	case 6:
+		fields = append([]string{"0"}, fields...)
+	}
+	return fields
+}
+
+func (p phrases) time(second, minute, hour string, zh bool) string {
+	s, sOk := single(second)
+	m, mOk := single(minute)
+	h, hOk := single(hour)
+	if sOk && mOk && hOk {
+		t := fmt.Sprintf("%02d:%02d", h, m)
+		if s > 0 {
+			t = fmt.Sprintf("%s:%02d", t, s)
+		}
+		if zh {
+			return t
+		}
+		return "at " + t
+	}
+	secondAll, minuteAll, hourAll := isAll(second), isAll(minute), isAll(hour)
+	parts := make([]string, 0, 3)
+	if !sOk || s != 0 {
+		parts = append(parts, p.field(second, fieldSecond, zh))
+	}
+	if secondAll {
+		parts = parts[:0]
+		parts = append(parts, p.every(1, fieldSecond, zh))
+	}
+	switch {
+	case minuteAll && !secondAll && (sOk && s == 0):
+		parts = append(parts, p.every(1, fieldMinute, zh))
+	case minuteAll && !secondAll && !isStep(second):
+		parts = append(parts, p.of(fieldMinute, zh))
+	case !minuteAll:
+		parts = append(parts, p.field(minute, fieldMinute, zh))
+	}
+	switch {
+	case hourAll && !minuteAll && !isStep(minute):
+		parts = append(parts, p.of(fieldHour, zh))
+	case !hourAll:
+		if isRange(hour) && !minuteAll && !isStep(minute) {
+			parts = append(parts, p.of(fieldHour, zh))
+		}
+		parts = append(parts, p.hour(hour, zh))
+	}
+	list := make([]string, 0, len(parts))
+	for _, item := range parts {
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	if zh {
+		for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+			list[i], list[j] = list[j], list[i]
+		}
+		return strings.Join(list, "")
+	}
+	return strings.Join(list, " ")
+}
+
+// of is the suffix of smaller unit, such as "of every minute"
+func (p phrases) of(f int, zh bool) string {
+	if zh {
+		return "每" + p.unit[f]
+	}
+	return "of every " + p.unit[f]
+}
+
+func (p phrases) hour(s string, zh bool) string {
+	if isRange(s) {
+		list, _ := parse(s, fieldHour)
+		// range of hours
+		if zh {
+			return fmt.Sprintf("%02d:00至%02d:59", list[0].from, list[0].to)
+		}
+		return fmt.Sprintf("between %02d:00 and %02d:59", list[0].from, list[0].to)
+	}
+	return p.field(s, fieldHour, zh)
+}
+
+func (p phrases) dom(s string, zh bool) string {
+	switch {
+	case s == "L":
+		if zh {
+			return "每月最后一天"
+		}
+		return "on the last day of the month"
+	case s == "LW":
+		if zh {
+			return "每月最后一个工作日"
+		}
+		return "on the last weekday of the month"
+	case strings.HasSuffix(s, "W"):
+		if zh {
+			return fmt.Sprintf("每月 %s 号最近的工作日", strings.TrimSuffix(s, "W"))
+		}
+		return fmt.Sprintf("on the weekday nearest day %s of the month", strings.TrimSuffix(s, "W"))
+	}
+	return p.field(s, fieldDom, zh)
+}
+
+func (p phrases) dow(s string, zh bool) string {
+	if i := strings.Index(s, "#"); i > 0 {
+		d, ok1 := value(s[:i], fieldDow)
+		n, err := strconv.Atoi(s[i+1:])
+		if ok1 && err == nil && n > 0 && n < len(ordinals) {
+			if zh {
+				return fmt.Sprintf("每月第 %d 个%s", n, p.weekdays[d])
+			}
+			return fmt.Sprintf("on the %s %s of the month", ordinals[n], p.weekdays[d])
+		}
+	}
+	if strings.HasSuffix(s, "L") && len(s) > 1 {
+		if d, ok := value(strings.TrimSuffix(s, "L"), fieldDow); ok {
+			if zh {
+				return "每月最后一个" + p.weekdays[d]
+			}
+			return fmt.Sprintf("on the last %s of the month", p.weekdays[d])
+		}
+	}
+	return p.field(s, fieldDow, zh)
+}
+
+// field describe common field, empty means every value
+func (p phrases) field(s string, f int, zh bool) string {
+	list, ok := parse(s, f)
+	if !ok {
+		// unknown syntax, keep raw
+		return fmt.Sprintf(p.list[f], s)
+	}
+	if len(list) == 1 && list[0].all {
+		if list[0].step <= 1 {
+			return ""
+		}
+		return p.every(list[0].step, f, zh)
+	}
+	if len(list) == 1 && list[0].step > 1 {
+		every := p.every(list[0].step, f, zh)
+		values := fmt.Sprintf(p.through, p.value(list[0].from, f), p.value(list[0].to, f))
+		if zh {
+			return fmt.Sprintf(p.list[f], values) + every
+		}
+		return every + " from " + values
+	}
+	values := make([]string, 0, len(list))
+	for _, item := range list {
+		if item.from == item.to {
+			values = append(values, p.value(item.from, f))
+			continue
+		}
+		v := fmt.Sprintf(p.through, p.value(item.from, f), p.value(item.to, f))
+		if item.step > 1 {
+			v = fmt.Sprintf("%s/%d", v, item.step)
+		}
+		values = append(values, v)
+	}
+	return fmt.Sprintf(p.list[f], p.joinValues(values))
+}
+
+func (p phrases) every(n int, f int, zh bool) string {
+	if zh {
+		if n <= 1 {
+			return "每" + p.unit[f]
+		}
+		return fmt.Sprintf("每 %d %s", n, p.units[f])
+	}
+	if n <= 1 {
+		return "every " + p.unit[f]
+	}
+	return fmt.Sprintf("every %d %s", n, p.units[f])
+}
+
+func (p phrases) value(v int, f int) string {
+	switch f {
+	case fieldMonth:
+		return p.months[v-1]
+	case fieldDow:
+		return p.weekdays[v%7]
+	}
+	return strconv.Itoa(v)
+}
+
+func (p phrases) joinValues(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return strings.Join(values[:len(values)-1], p.join) + p.last + values[len(values)-1]
+}
+
+func parse(s string, f int) (list []item, ok bool) {
+	for _, part := range strings.Split(s, ",") {
+		var it item
+		base := part
+		if i := strings.Index(part, "/"); i >= 0 {
+			step, err := strconv.Atoi(part[i+1:])
+			if err != nil || step <= 0 {
+				return
+			}
+			it.step = step
+			base = part[:i]
+		}
+		switch {
+		case base == "*" || base == "?":
+			it.all = true
+			it.from = 0
+			if f == fieldDom || f == fieldMonth {
+				it.from = 1
+			}
+			it.to = fieldMax[f]
+		case strings.Contains(base, "-"):
+			items := strings.SplitN(base, "-", 2)
+			from, ok1 := value(items[0], f)
+			to, ok2 := value(items[1], f)
+			if !ok1 || !ok2 {
+				return
+			}
+			it.from, it.to = from, to
+		default:
+			v, ok1 := value(base, f)
+			if !ok1 {
+				return
+			}
+			it.from, it.to = v, v
+			if it.step > 0 {
+				// a/n means from a to max
+				it.to = fieldMax[f]
+			}
+		}
+		list = append(list, it)
+	}
+	ok = len(list) > 0
+	return
+}
+
+func value(s string, f int) (v int, ok bool) {
+	u := strings.ToUpper(s)
+	switch f {
+	case fieldMonth:
+		for i, item := range monthNames {
+			if u == item {
+				return i + 1, true
+			}
+		}
+	case fieldDow:
+		for i, item := range dowNames {
+			if u == item {
+				return i, true
+			}
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return
+	}
+	if f == fieldDow {
+		// 7 is sunday
+		v %= 7
+	}
+	ok = true
+	return
+}
+
+func single(s string) (v int, ok bool) {
+	list, ok := parse(s, fieldSecond)
+	if !ok || len(list) != 1 || list[0].all || list[0].step > 0 || list[0].from != list[0].to {
+		ok = false
+		return
+	}
+	v = list[0].from
+	return
+}
+
+func isAll(s string) bool {
+	return s == "*" || s == "?"
+}
+
+func isRange(s string) bool {
+	list, ok := parse(s, fieldHour)
+	return ok && len(list) == 1 && !list[0].all && list[0].step == 0 && list[0].from < list[0].to
+}
+
+func isStep(s string) bool {
+	return strings.Contains(s, "/")
+}
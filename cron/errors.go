@@ -0,0 +1,9 @@
+package cron
+
+import "github.com/pkg/errors"
+
+var (
+	ErrExprNil      = errors.New("cron expr is empty")
+	ErrExprInvalid  = errors.New("invalid cron expr")
+	ErrNoOccurrence = errors.New("cron expr has no next occurrence")
+)
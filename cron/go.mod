@@ -0,0 +1,8 @@
+module github.com/go-cinch/common/cron
+
+go 1.20
+
+require (
+	github.com/gorhill/cronexpr v0.0.0-20180427100037-88b0669f7d75
+	github.com/pkg/errors v0.9.1
+)
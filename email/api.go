@@ -0,0 +1,278 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+// Sendgrid send email by sendgrid v3 api
+type Sendgrid struct {
+	ops ApiOptions
+}
+
+// Mailgun send email by mailgun v3 api
+type Mailgun struct {
+	ops ApiOptions
+}
+
+func NewSendgrid(options ...func(*ApiOptions)) (s *Sendgrid, err error) {
+	ops := getApiOptionsOrSetDefault(nil)
+	ops.url = "https://api.sendgrid.com"
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.key == "" {
+		err = ErrApiKeyNil
+		return
+	}
+	s = &Sendgrid{
+		ops: *ops,
+	}
+	return
+}
+
+func NewMailgun(options ...func(*ApiOptions)) (m *Mailgun, err error) {
+	ops := getApiOptionsOrSetDefault(nil)
+	ops.url = "https://api.mailgun.net"
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.key == "" {
+		err = ErrApiKeyNil
+		return
+	}
+	if ops.domain == "" {
+		err = ErrDomainNil
+		return
+	}
+	m = &Mailgun{
+		ops: *ops,
+	}
+	return
+}
+
+type sendgridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendgridPersonalization struct {
+	To  []sendgridAddress `json:"to"`
+	Cc  []sendgridAddress `json:"cc,omitempty"`
+	Bcc []sendgridAddress `json:"bcc,omitempty"`
+}
+
+type sendgridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendgridAttachment struct {
+	Content     string `json:"content"`
+	Type        string `json:"type"`
+	Filename    string `json:"filename"`
+	Disposition string `json:"disposition"`
+	ContentId   string `json:"content_id,omitempty"`
+}
+
+type sendgridMessage struct {
+	Personalizations []sendgridPersonalization `json:"personalizations"`
+	From             sendgridAddress           `json:"from"`
+	ReplyTo          *sendgridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendgridContent         `json:"content"`
+	Attachments      []sendgridAttachment      `json:"attachments,omitempty"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+}
+
+func (s *Sendgrid) Send(ctx context.Context, msg Message) (err error) {
+	if msg.From == "" {
+		msg.From = s.ops.from
+	}
+	if msg.From == "" {
+		err = ErrFromNil
+		return
+	}
+	err = msg.validate()
+	if err != nil {
+		return
+	}
+	var body sendgridMessage
+	var p sendgridPersonalization
+	p.To, err = sendgridAddresses(msg.To...)
+	if err != nil {
+		return
+	}
+	p.Cc, err = sendgridAddresses(msg.Cc...)
+	if err != nil {
+		return
+	}
+	p.Bcc, err = sendgridAddresses(msg.Bcc...)
+	if err != nil {
+		return
+	}
+	body.Personalizations = []sendgridPersonalization{p}
+	from, err := sendgridAddresses(msg.From)
+	if err != nil {
+		return
+	}
+	body.From = from[0]
+	if msg.ReplyTo != "" {
+		var replyTo []sendgridAddress
+		replyTo, err = sendgridAddresses(msg.ReplyTo)
+		if err != nil {
+			return
+		}
+		body.ReplyTo = &replyTo[0]
+	}
+	body.Subject = msg.Subject
+	// text/plain must be the first one
+	if msg.Text != "" {
+		body.Content = append(body.Content, sendgridContent{Type: "text/plain", Value: msg.Text})
+	}
+	if msg.Html != "" {
+		body.Content = append(body.Content, sendgridContent{Type: "text/html", Value: msg.Html})
+	}
+	for _, item := range msg.Attachments {
+		a := sendgridAttachment{
+			Content:     base64.StdEncoding.EncodeToString(item.Data),
+			Type:        item.contentType(),
+			Filename:    item.Name,
+			Disposition: "attachment",
+		}
+		if item.ContentId != "" {
+			a.Disposition = "inline"
+			a.ContentId = item.ContentId
+		}
+		body.Attachments = append(body.Attachments, a)
+	}
+	body.Headers = msg.Headers
+	bs, _ := json.Marshal(body)
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(s.ops.url, "/")+"/v3/mail/send", bytes.NewReader(bs))
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	r.Header.Set("Authorization", "Bearer "+s.ops.key)
+	r.Header.Set("Content-Type", "application/json")
+	err = do(s.ops.client, r)
+	return
+}
+
+func (m *Mailgun) Send(ctx context.Context, msg Message) (err error) {
+	if msg.From == "" {
+		msg.From = m.ops.from
+	}
+	if msg.From == "" {
+		err = ErrFromNil
+		return
+	}
+	err = msg.validate()
+	if err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	fields := [][2]string{
+		{"from", msg.From},
+		{"subject", msg.Subject},
+	}
+	for _, item := range msg.To {
+		fields = append(fields, [2]string{"to", item})
+	}
+	for _, item := range msg.Cc {
+		fields = append(fields, [2]string{"cc", item})
+	}
+	for _, item := range msg.Bcc {
+		fields = append(fields, [2]string{"bcc", item})
+	}
+	if msg.ReplyTo != "" {
+		fields = append(fields, [2]string{"h:Reply-To", msg.ReplyTo})
+	}
+	if msg.Text != "" {
+		fields = append(fields, [2]string{"text", msg.Text})
+	}
+	if msg.Html != "" {
+		fields = append(fields, [2]string{"html", msg.Html})
+	}
+	for k, v := range msg.Headers {
+		fields = append(fields, [2]string{"h:" + k, v})
+	}
+	for _, item := range fields {
+		_ = w.WriteField(item[0], item[1])
+	}
+	for _, item := range msg.Attachments {
+		field := "attachment"
+		name := item.Name
+		if item.ContentId != "" {
+			// mailgun use filename as content id of inline image
+			field = "inline"
+			name = item.ContentId
+		}
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", `form-data; name="`+field+`"; filename="`+escapeQuotes(name)+`"`)
+		h.Set("Content-Type", item.contentType())
+		var pw io.Writer
+		pw, err = w.CreatePart(h)
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+		_, _ = pw.Write(item.Data)
+	}
+	_ = w.Close()
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(m.ops.url, "/")+"/v3/"+m.ops.domain+"/messages", &buf)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	r.SetBasicAuth("api", m.ops.key)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	err = do(m.ops.client, r)
+	return
+}
+
+func sendgridAddresses(list ...string) (rp []sendgridAddress, err error) {
+	for _, item := range list {
+		var addr *mail.Address
+		addr, err = mail.ParseAddress(item)
+		if err != nil {
+			err = errors.Wrapf(err, "invalid address %s", item)
+			return
+		}
+		rp = append(rp, sendgridAddress{
+			Email: addr.Address,
+			Name:  addr.Name,
+		})
+	}
+	return
+}
+
+func do(client *http.Client, r *http.Request) (err error) {
+	res, err := client.Do(r)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	defer res.Body.Close()
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		bs, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		err = errors.Wrapf(ErrInvalidStatusCode, "%d %s", res.StatusCode, bs)
+	}
+	return
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
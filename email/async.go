@@ -0,0 +1,74 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-cinch/common/worker"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// Async enqueue email by worker, the task will be retried if sender failed
+type Async struct {
+	ops    AsyncOptions
+	sender Sender
+	wk     *worker.Worker
+}
+
+// NewAsync wrap sender, call Process in worker handler when payload group is Group()
+func NewAsync(sender Sender, wk *worker.Worker, options ...func(*AsyncOptions)) (a *Async, err error) {
+	ops := getAsyncOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if wk == nil {
+		err = ErrWorkerNil
+		return
+	}
+	a = &Async{
+		ops:    *ops,
+		sender: sender,
+		wk:     wk,
+	}
+	return
+}
+
+// Group is the worker task group of email
+func (a *Async) Group() string {
+	return a.ops.group
+}
+
+func (a *Async) Send(ctx context.Context, msg Message) (err error) {
+	// from may be filled by sender, check it when processing
+	err = msg.validate()
+	if err != nil {
+		return
+	}
+	bs, err := json.Marshal(msg)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	err = a.wk.Once(
+		worker.WithRunUuid(uuid.NewString()),
+		worker.WithRunGroup(a.ops.group),
+		worker.WithRunPayload(string(bs)),
+		worker.WithRunNow(true),
+		worker.WithRunMaxRetry(a.ops.maxRetry),
+		worker.WithRunTimeout(a.ops.timeout),
+		worker.WithRunCtx(ctx),
+	)
+	return
+}
+
+// Process send the enqueued email, return error to retry
+func (a *Async) Process(ctx context.Context, p worker.Payload) (err error) {
+	var msg Message
+	err = json.Unmarshal([]byte(p.Payload), &msg)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	err = a.sender.Send(ctx, msg)
+	return
+}
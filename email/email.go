@@ -0,0 +1,280 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/pkg/errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Sender deliver email, implemented by smtp/sendgrid/mailgun/async
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message is the email content, address format can be `a@b.com` or `Name <a@b.com>`
+type Message struct {
+	From        string            `json:"from,omitempty"`
+	To          []string          `json:"to"`
+	Cc          []string          `json:"cc,omitempty"`
+	Bcc         []string          `json:"bcc,omitempty"`
+	ReplyTo     string            `json:"replyTo,omitempty"`
+	Subject     string            `json:"subject"`
+	Text        string            `json:"text,omitempty"`
+	Html        string            `json:"html,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Attachments []Attachment      `json:"attachments,omitempty"`
+}
+
+// Attachment is a file of message, it will be inline(referenced by cid:xxx in html) if ContentId is set
+type Attachment struct {
+	Name        string `json:"name"`
+	ContentType string `json:"contentType,omitempty"`
+	ContentId   string `json:"contentId,omitempty"`
+	Data        []byte `json:"data"`
+}
+
+// NewAttachment read attachment from file
+func NewAttachment(file string) (rp Attachment, err error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	rp = Attachment{
+		Name: filepath.Base(file),
+		Data: data,
+	}
+	return
+}
+
+func (a Attachment) contentType() (rp string) {
+	rp = a.ContentType
+	if rp == "" {
+		rp = mime.TypeByExtension(filepath.Ext(a.Name))
+	}
+	if rp == "" {
+		rp = "application/octet-stream"
+	}
+	return
+}
+
+// Recipients get all envelope recipients(to/cc/bcc) without name
+func (m Message) Recipients() (rp []string, err error) {
+	for _, list := range [][]string{m.To, m.Cc, m.Bcc} {
+		for _, item := range list {
+			var addr *mail.Address
+			addr, err = mail.ParseAddress(item)
+			if err != nil {
+				err = errors.Wrapf(err, "invalid address %s", item)
+				return
+			}
+			rp = append(rp, addr.Address)
+		}
+	}
+	return
+}
+
+// Bytes build RFC 5322 message, the structure is mixed(related(alternative(text, html), inline...), attachment...)
+func (m Message) Bytes() (rp []byte, err error) {
+	var body mimePart
+	switch {
+	case m.Text != "" && m.Html != "":
+		body, err = multipartOf("alternative", textPart("text/plain", m.Text), textPart("text/html", m.Html))
+	case m.Html != "":
+		body = textPart("text/html", m.Html)
+	default:
+		body = textPart("text/plain", m.Text)
+	}
+	if err != nil {
+		return
+	}
+	inlines := []mimePart{body}
+	attachments := make([]mimePart, 0)
+	for _, item := range m.Attachments {
+		if item.ContentId != "" {
+			inlines = append(inlines, attachmentPart(item))
+		} else {
+			attachments = append(attachments, attachmentPart(item))
+		}
+	}
+	if len(inlines) > 1 {
+		body, err = multipartOf("related", inlines...)
+		if err != nil {
+			return
+		}
+	}
+	if len(attachments) > 0 {
+		body, err = multipartOf("mixed", append([]mimePart{body}, attachments...)...)
+		if err != nil {
+			return
+		}
+	}
+
+	header := textproto.MIMEHeader{}
+	for k, v := range m.Headers {
+		header.Set(k, v)
+	}
+	header.Set("From", formatAddress(m.From))
+	header.Set("To", formatAddress(m.To...))
+	if len(m.Cc) > 0 {
+		header.Set("Cc", formatAddress(m.Cc...))
+	}
+	if m.ReplyTo != "" {
+		header.Set("Reply-To", formatAddress(m.ReplyTo))
+	}
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	if header.Get("Date") == "" {
+		header.Set("Date", time.Now().Format(time.RFC1123Z))
+	}
+	if header.Get("Message-Id") == "" {
+		header.Set("Message-Id", messageId(m.From))
+	}
+	header.Set("Mime-Version", "1.0")
+	for k, v := range body.header {
+		header[k] = v
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, header)
+	buf.WriteString("\r\n")
+	buf.Write(body.body)
+	rp = buf.Bytes()
+	return
+}
+
+func (m Message) validate() (err error) {
+	if len(m.To) == 0 {
+		err = ErrToNil
+		return
+	}
+	if m.Text == "" && m.Html == "" {
+		err = ErrBodyNil
+	}
+	return
+}
+
+type mimePart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+func textPart(contentType, s string) (rp mimePart) {
+	var buf bytes.Buffer
+	w := quotedprintable.NewWriter(&buf)
+	_, _ = w.Write([]byte(s))
+	_ = w.Close()
+	rp.header = textproto.MIMEHeader{}
+	rp.header.Set("Content-Type", mime.FormatMediaType(contentType, map[string]string{"charset": "utf-8"}))
+	rp.header.Set("Content-Transfer-Encoding", "quoted-printable")
+	rp.body = buf.Bytes()
+	return
+}
+
+func attachmentPart(a Attachment) (rp mimePart) {
+	rp.header = textproto.MIMEHeader{}
+	mediaType, params, err := mime.ParseMediaType(a.contentType())
+	if err != nil {
+		mediaType = "application/octet-stream"
+		params = make(map[string]string)
+	}
+	params["name"] = a.Name
+	rp.header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	rp.header.Set("Content-Transfer-Encoding", "base64")
+	disposition := "attachment"
+	if a.ContentId != "" {
+		disposition = "inline"
+		rp.header.Set("Content-Id", "<"+a.ContentId+">")
+	}
+	rp.header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": a.Name}))
+	// base64 lines must not be longer than 76 characters
+	s := base64.StdEncoding.EncodeToString(a.Data)
+	var buf bytes.Buffer
+	for len(s) > 76 {
+		buf.WriteString(s[:76])
+		buf.WriteString("\r\n")
+		s = s[76:]
+	}
+	buf.WriteString(s)
+	rp.body = buf.Bytes()
+	return
+}
+
+func multipartOf(subtype string, parts ...mimePart) (rp mimePart, err error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	for _, item := range parts {
+		var pw io.Writer
+		pw, err = w.CreatePart(item.header)
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+		_, _ = pw.Write(item.body)
+	}
+	err = w.Close()
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	rp.header = textproto.MIMEHeader{}
+	rp.header.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": w.Boundary()}))
+	rp.body = buf.Bytes()
+	return
+}
+
+// formatAddress encode non-ascii name by RFC 2047, keep the origin if it can not be parsed
+func formatAddress(list ...string) string {
+	rp := make([]string, 0, len(list))
+	for _, item := range list {
+		if addr, err := mail.ParseAddress(item); err == nil {
+			item = addr.String()
+		}
+		rp = append(rp, item)
+	}
+	return strings.Join(rp, ", ")
+}
+
+var headerReplacer = strings.NewReplacer("\r", "", "\n", "")
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range header[k] {
+			buf.WriteString(k)
+			buf.WriteString(": ")
+			// avoid header injection
+			buf.WriteString(headerReplacer.Replace(v))
+			buf.WriteString("\r\n")
+		}
+	}
+}
+
+func messageId(from string) string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	domain := "localhost"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+			domain = addr.Address[i+1:]
+		}
+	}
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
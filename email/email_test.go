@@ -0,0 +1,300 @@
+package email
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/go-cinch/common/worker"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMessageBytes(t *testing.T) {
+	msg := Message{
+		From:    "张三 <a@example.com>",
+		To:      []string{"b@example.com"},
+		Bcc:     []string{"c@example.com"},
+		Subject: "你好\r\nBcc: x@example.com",
+		Text:    "hello",
+		Html:    `<img src="cid:logo.png">`,
+		Attachments: []Attachment{
+			{Name: "logo.png", ContentId: "logo.png", Data: []byte("png")},
+			{Name: "a.txt", Data: []byte("attachment")},
+		},
+	}
+	bs, err := msg.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := mail.ReadMessage(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Header.Get("Bcc") != "" {
+		t.Fatal("bcc should not be in header")
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	if subject != "你好\r\nBcc: x@example.com" {
+		t.Fatalf("unexpected subject %q", subject)
+	}
+	from, err := m.Header.AddressList("From")
+	if err != nil || from[0].Name != "张三" {
+		t.Fatalf("unexpected from %v %v", from, err)
+	}
+
+	// mixed(related(alternative(text, html), inline), attachment)
+	types := make([]string, 0)
+	var walk func(r io.Reader, contentType string)
+	walk = func(r io.Reader, contentType string) {
+		mediaType, params, _ := mime.ParseMediaType(contentType)
+		types = append(types, mediaType)
+		if !strings.HasPrefix(mediaType, "multipart/") {
+			return
+		}
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			p, e := mr.NextPart()
+			if e != nil {
+				return
+			}
+			walk(p, p.Header.Get("Content-Type"))
+		}
+	}
+	walk(m.Body, m.Header.Get("Content-Type"))
+	expect := "multipart/mixed,multipart/related,multipart/alternative,text/plain,text/html,image/png,text/plain"
+	if strings.Join(types, ",") != expect {
+		t.Fatalf("unexpected structure %v", types)
+	}
+}
+
+func TestSmtp(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	done := make(chan []string, 1)
+	go fakeSmtp(ln, done)
+
+	port, _ := strconv.Atoi(strings.Split(ln.Addr().String(), ":")[1])
+	s, err := NewSmtp(
+		WithSmtpHost("127.0.0.1"),
+		WithSmtpPort(port),
+		WithSmtpSecurity(None),
+		WithSmtpFrom("a@example.com"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Send(context.Background(), Message{
+		To:      []string{"B <b@example.com>"},
+		Cc:      []string{"c@example.com"},
+		Bcc:     []string{"d@example.com"},
+		Subject: "hello",
+		Text:    "world",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	commands := <-done
+	expect := []string{"MAIL FROM:<a@example.com>", "RCPT TO:<b@example.com>", "RCPT TO:<c@example.com>", "RCPT TO:<d@example.com>"}
+	for _, item := range expect {
+		if !contains(commands, item) {
+			t.Fatalf("command %s not found in %v", item, commands)
+		}
+	}
+
+	err = s.Send(context.Background(), Message{
+		Subject: "hello",
+		Text:    "world",
+	})
+	if err != ErrToNil {
+		t.Fatalf("expect to nil but got %v", err)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/welcome.html": {Data: []byte(`<img src="{{asset "images/logo.png"}}"><p>Hi {{.Name}}</p><img src="{{asset "images/logo.png"}}">`)},
+		"images/logo.png":        {Data: []byte("png")},
+	}
+	tpl, err := NewTemplate(fsys, "templates/*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg Message
+	err = tpl.Render(&msg, "welcome.html", map[string]string{"Name": "<cinch>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Html != `<img src="cid:images/logo.png"><p>Hi &lt;cinch&gt;</p><img src="cid:images/logo.png">` {
+		t.Fatalf("unexpected html %s", msg.Html)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].ContentId != "images/logo.png" || string(msg.Attachments[0].Data) != "png" {
+		t.Fatalf("unexpected attachments %+v", msg.Attachments)
+	}
+}
+
+func TestSendgrid(t *testing.T) {
+	var body sendgridMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v3/mail/send" || r.Header.Get("Authorization") != "Bearer key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+	s, _ := NewSendgrid(WithApiUrl(srv.URL), WithApiKey("key"), WithApiFrom("A <a@example.com>"))
+	err := s.Send(context.Background(), Message{
+		To:      []string{"b@example.com"},
+		Subject: "hello",
+		Html:    "<p>world</p>",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body.From.Email != "a@example.com" || body.From.Name != "A" || body.Personalizations[0].To[0].Email != "b@example.com" {
+		t.Fatalf("unexpected body %+v", body)
+	}
+
+	s, _ = NewSendgrid(WithApiUrl(srv.URL), WithApiKey("invalid"), WithApiFrom("a@example.com"))
+	err = s.Send(context.Background(), Message{
+		To:   []string{"b@example.com"},
+		Text: "world",
+	})
+	if err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("expect invalid status code but got %v", err)
+	}
+}
+
+func TestMailgun(t *testing.T) {
+	var to []string
+	var inline string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, key, _ := r.BasicAuth()
+		if r.URL.Path != "/v3/example.com/messages" || key != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = r.ParseMultipartForm(1 << 20)
+		to = r.MultipartForm.Value["to"]
+		if files := r.MultipartForm.File["inline"]; len(files) > 0 {
+			inline = files[0].Filename
+		}
+	}))
+	defer srv.Close()
+	_, err := NewMailgun(WithApiKey("key"))
+	if err != ErrDomainNil {
+		t.Fatalf("expect domain nil but got %v", err)
+	}
+	m, _ := NewMailgun(WithApiUrl(srv.URL), WithApiKey("key"), WithApiDomain("example.com"))
+	err = m.Send(context.Background(), Message{
+		From:        "a@example.com",
+		To:          []string{"b@example.com", "c@example.com"},
+		Html:        `<img src="cid:logo.png">`,
+		Attachments: []Attachment{{Name: "logo.png", ContentId: "logo.png", Data: []byte("png")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(to, ",") != "b@example.com,c@example.com" || inline != "logo.png" {
+		t.Fatalf("unexpected form %v %s", to, inline)
+	}
+}
+
+type memorySender struct {
+	list []Message
+}
+
+func (m *memorySender) Send(ctx context.Context, msg Message) error {
+	m.list = append(m.list, msg)
+	return nil
+}
+
+func TestAsyncProcess(t *testing.T) {
+	sender := &memorySender{}
+	_, err := NewAsync(sender, nil)
+	if err != ErrWorkerNil {
+		t.Fatalf("expect worker nil but got %v", err)
+	}
+	a, _ := NewAsync(sender, &worker.Worker{}, WithAsyncGroup("mail"))
+	if a.Group() != "mail" {
+		t.Fatalf("unexpected group %s", a.Group())
+	}
+	bs, _ := json.Marshal(Message{
+		To:          []string{"b@example.com"},
+		Text:        "hello",
+		Attachments: []Attachment{{Name: "a.bin", Data: []byte{0, 1, 2}}},
+	})
+	err = a.Process(context.Background(), worker.Payload{Group: "mail", Payload: string(bs)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.list) != 1 || !bytes.Equal(sender.list[0].Attachments[0].Data, []byte{0, 1, 2}) {
+		t.Fatalf("unexpected messages %+v", sender.list)
+	}
+}
+
+// fakeSmtp accept one session and record commands
+func fakeSmtp(ln net.Listener, done chan<- []string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	commands := make([]string, 0)
+	r := bufio.NewReader(conn)
+	write := func(s string) {
+		_, _ = conn.Write([]byte(s + "\r\n"))
+	}
+	write("220 localhost ESMTP")
+	for {
+		line, e := r.ReadString('\n')
+		if e != nil {
+			break
+		}
+		line = strings.TrimSpace(line)
+		commands = append(commands, line)
+		switch {
+		case strings.HasPrefix(line, "EHLO"):
+			write("250 localhost")
+		case line == "DATA":
+			write("354 go ahead")
+			for {
+				l, e := r.ReadString('\n')
+				if e != nil || l == ".\r\n" {
+					break
+				}
+			}
+			write("250 ok")
+		case line == "QUIT":
+			write("221 bye")
+			done <- commands
+			return
+		default:
+			write("250 ok")
+		}
+	}
+	done <- commands
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
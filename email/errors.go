@@ -0,0 +1,14 @@
+package email
+
+import "github.com/pkg/errors"
+
+var (
+	ErrHostNil           = errors.New("smtp host is empty")
+	ErrApiKeyNil         = errors.New("api key is empty")
+	ErrDomainNil         = errors.New("mailgun domain is empty")
+	ErrFromNil           = errors.New("from is empty")
+	ErrToNil             = errors.New("to is empty")
+	ErrBodyNil           = errors.New("text and html body are both empty")
+	ErrWorkerNil         = errors.New("worker is nil")
+	ErrInvalidStatusCode = errors.New("invalid status code")
+)
@@ -0,0 +1,196 @@
+package email
+
+import (
+	"net/http"
+	"time"
+)
+
+// Security is the smtp connection encryption
+type Security int
+
+const (
+	// StartTLS upgrade plain connection by STARTTLS command, usually port 587
+	StartTLS Security = iota
+	// TLS implicit tls connection, usually port 465
+	TLS
+	// None no encryption, only for local test server
+	None
+)
+
+type SmtpOptions struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string // default from address
+	security Security
+	insecure bool // skip tls certificate verify
+	timeout  time.Duration
+}
+
+func WithSmtpHost(host string) func(*SmtpOptions) {
+	return func(options *SmtpOptions) {
+		if host != "" {
+			getSmtpOptionsOrSetDefault(options).host = host
+		}
+	}
+}
+
+func WithSmtpPort(port int) func(*SmtpOptions) {
+	return func(options *SmtpOptions) {
+		if port > 0 {
+			getSmtpOptionsOrSetDefault(options).port = port
+		}
+	}
+}
+
+func WithSmtpUsername(username string) func(*SmtpOptions) {
+	return func(options *SmtpOptions) {
+		getSmtpOptionsOrSetDefault(options).username = username
+	}
+}
+
+func WithSmtpPassword(password string) func(*SmtpOptions) {
+	return func(options *SmtpOptions) {
+		getSmtpOptionsOrSetDefault(options).password = password
+	}
+}
+
+func WithSmtpFrom(from string) func(*SmtpOptions) {
+	return func(options *SmtpOptions) {
+		if from != "" {
+			getSmtpOptionsOrSetDefault(options).from = from
+		}
+	}
+}
+
+func WithSmtpSecurity(security Security) func(*SmtpOptions) {
+	return func(options *SmtpOptions) {
+		getSmtpOptionsOrSetDefault(options).security = security
+	}
+}
+
+func WithSmtpInsecure(flag bool) func(*SmtpOptions) {
+	return func(options *SmtpOptions) {
+		getSmtpOptionsOrSetDefault(options).insecure = flag
+	}
+}
+
+func WithSmtpTimeout(second int) func(*SmtpOptions) {
+	return func(options *SmtpOptions) {
+		if second > 0 {
+			getSmtpOptionsOrSetDefault(options).timeout = time.Duration(second) * time.Second
+		}
+	}
+}
+
+func getSmtpOptionsOrSetDefault(options *SmtpOptions) *SmtpOptions {
+	if options == nil {
+		return &SmtpOptions{
+			port:     587,
+			security: StartTLS,
+			timeout:  10 * time.Second,
+		}
+	}
+	return options
+}
+
+type ApiOptions struct {
+	url    string // api base url, override it for eu region or test
+	key    string
+	domain string // only mailgun
+	from   string // default from address
+	client *http.Client
+}
+
+func WithApiUrl(url string) func(*ApiOptions) {
+	return func(options *ApiOptions) {
+		if url != "" {
+			getApiOptionsOrSetDefault(options).url = url
+		}
+	}
+}
+
+func WithApiKey(key string) func(*ApiOptions) {
+	return func(options *ApiOptions) {
+		if key != "" {
+			getApiOptionsOrSetDefault(options).key = key
+		}
+	}
+}
+
+func WithApiDomain(domain string) func(*ApiOptions) {
+	return func(options *ApiOptions) {
+		if domain != "" {
+			getApiOptionsOrSetDefault(options).domain = domain
+		}
+	}
+}
+
+func WithApiFrom(from string) func(*ApiOptions) {
+	return func(options *ApiOptions) {
+		if from != "" {
+			getApiOptionsOrSetDefault(options).from = from
+		}
+	}
+}
+
+func WithApiClient(client *http.Client) func(*ApiOptions) {
+	return func(options *ApiOptions) {
+		if client != nil {
+			getApiOptionsOrSetDefault(options).client = client
+		}
+	}
+}
+
+func getApiOptionsOrSetDefault(options *ApiOptions) *ApiOptions {
+	if options == nil {
+		return &ApiOptions{
+			client: &http.Client{
+				Timeout: 10 * time.Second,
+			},
+		}
+	}
+	return options
+}
+
+type AsyncOptions struct {
+	group    string
+	maxRetry int
+	timeout  int
+}
+
+func WithAsyncGroup(group string) func(*AsyncOptions) {
+	return func(options *AsyncOptions) {
+		if group != "" {
+			getAsyncOptionsOrSetDefault(options).group = group
+		}
+	}
+}
+
+func WithAsyncMaxRetry(count int) func(*AsyncOptions) {
+	return func(options *AsyncOptions) {
+		if count >= 0 {
+			getAsyncOptionsOrSetDefault(options).maxRetry = count
+		}
+	}
+}
+
+func WithAsyncTimeout(second int) func(*AsyncOptions) {
+	return func(options *AsyncOptions) {
+		if second > 0 {
+			getAsyncOptionsOrSetDefault(options).timeout = second
+		}
+	}
+}
+
+func getAsyncOptionsOrSetDefault(options *AsyncOptions) *AsyncOptions {
+	if options == nil {
+		return &AsyncOptions{
+			group:    "email",
+			maxRetry: 5,
+			timeout:  60,
+		}
+	}
+	return options
+}
@@ -0,0 +1,143 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"github.com/pkg/errors"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// Smtp send email by smtp server
+type Smtp struct {
+	ops SmtpOptions
+}
+
+func NewSmtp(options ...func(*SmtpOptions)) (s *Smtp, err error) {
+	ops := getSmtpOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.host == "" {
+		err = ErrHostNil
+		return
+	}
+	s = &Smtp{
+		ops: *ops,
+	}
+	return
+}
+
+func (s *Smtp) Send(ctx context.Context, msg Message) (err error) {
+	if msg.From == "" {
+		msg.From = s.ops.from
+	}
+	if msg.From == "" {
+		err = ErrFromNil
+		return
+	}
+	err = msg.validate()
+	if err != nil {
+		return
+	}
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		err = errors.Wrapf(err, "invalid address %s", msg.From)
+		return
+	}
+	rcpts, err := msg.Recipients()
+	if err != nil {
+		return
+	}
+	data, err := msg.Bytes()
+	if err != nil {
+		return
+	}
+
+	c, err := s.dial(ctx)
+	if err != nil {
+		return
+	}
+	defer c.Close()
+	if s.ops.username != "" {
+		// PlainAuth refuses to send password over unencrypted connection except localhost
+		err = c.Auth(smtp.PlainAuth("", s.ops.username, s.ops.password, s.ops.host))
+		if err != nil {
+			err = errors.WithMessage(err, "smtp auth failed")
+			return
+		}
+	}
+	err = c.Mail(from.Address)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	for _, item := range rcpts {
+		err = c.Rcpt(item)
+		if err != nil {
+			err = errors.Wrapf(err, "smtp rcpt %s failed", item)
+			return
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	err = w.Close()
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	err = c.Quit()
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+func (s *Smtp) dial(ctx context.Context) (c *smtp.Client, err error) {
+	addr := net.JoinHostPort(s.ops.host, strconv.Itoa(s.ops.port))
+	d := net.Dialer{
+		Timeout: s.ops.timeout,
+	}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(s.ops.timeout)
+	}
+	_ = conn.SetDeadline(deadline)
+	cfg := &tls.Config{
+		ServerName:         s.ops.host,
+		InsecureSkipVerify: s.ops.insecure,
+	}
+	if s.ops.security == TLS {
+		conn = tls.Client(conn, cfg)
+	}
+	c, err = smtp.NewClient(conn, s.ops.host)
+	if err != nil {
+		_ = conn.Close()
+		err = errors.WithStack(err)
+		return
+	}
+	if s.ops.security == StartTLS {
+		err = c.StartTLS(cfg)
+		if err != nil {
+			_ = c.Close()
+			err = errors.WithMessage(err, "smtp starttls failed")
+		}
+	}
+	return
+}
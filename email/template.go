@@ -0,0 +1,80 @@
+package email
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"html/template"
+	"io/fs"
+	"path"
+	"sync"
+)
+
+// Template render html body by html/template, assets(image/css...) are loaded from the same fs
+type Template struct {
+	fs  fs.FS
+	tpl *template.Template
+}
+
+// NewTemplate parse templates from fs(go embed is recommended), patterns are the same as template.ParseFS,
+// use {{asset "images/logo.png"}} in template to embed an inline asset, it will be replaced by cid:images/logo.png
+func NewTemplate(fsys fs.FS, patterns ...string) (t *Template, err error) {
+	tpl, err := template.New("").Funcs(template.FuncMap{
+		"asset": func(name string) template.URL {
+			return ""
+		},
+	}).ParseFS(fsys, patterns...)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	t = &Template{
+		fs:  fsys,
+		tpl: tpl,
+	}
+	return
+}
+
+// Render execute template name(base name of file) to msg.Html, used assets are appended to msg.Attachments as inline
+func (t *Template) Render(msg *Message, name string, data interface{}) (err error) {
+	var lock sync.Mutex
+	assets := make([]string, 0)
+	exists := make(map[string]struct{})
+	tpl, err := t.tpl.Clone()
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	tpl.Funcs(template.FuncMap{
+		"asset": func(name string) template.URL {
+			name = path.Clean(name)
+			lock.Lock()
+			defer lock.Unlock()
+			if _, ok := exists[name]; !ok {
+				exists[name] = struct{}{}
+				assets = append(assets, name)
+			}
+			return template.URL("cid:" + name)
+		},
+	})
+	var buf bytes.Buffer
+	err = tpl.ExecuteTemplate(&buf, name, data)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	for _, item := range assets {
+		var bs []byte
+		bs, err = fs.ReadFile(t.fs, item)
+		if err != nil {
+			err = errors.Wrapf(err, "read asset %s failed", item)
+			return
+		}
+		msg.Attachments = append(msg.Attachments, Attachment{
+			Name:      path.Base(item),
+			ContentId: item,
+			Data:      bs,
+		})
+	}
+	msg.Html = buf.String()
+	return
+}
@@ -0,0 +1,10 @@
+package eventbus
+
+import "github.com/pkg/errors"
+
+var (
+	ErrRedisNil   = errors.New("redis is nil")
+	ErrTopicNil   = errors.New("topic is empty")
+	ErrHandlerNil = errors.New("handler is nil")
+	ErrBusClosed  = errors.New("event bus is closed")
+)
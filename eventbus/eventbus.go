@@ -0,0 +1,352 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/go-cinch/common/log"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fieldPayload = "payload"
+	fieldTime    = "time"
+	fieldId      = "id"
+	fieldGroup   = "group"
+	fieldError   = "error"
+)
+
+// Event is one message of topic
+type Event struct {
+	Id      string    `json:"id"`
+	Topic   string    `json:"topic"`
+	Payload []byte    `json:"payload"`
+	Time    time.Time `json:"time"`
+	// Retry is the delivery count, 1 means the first time
+	Retry int64 `json:"retry"`
+}
+
+// Bind unmarshal json payload
+func (e Event) Bind(v interface{}) (err error) {
+	err = json.Unmarshal(e.Payload, v)
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+// Handler process event, return error to retry, the event will be moved to dead letter stream after max retry
+type Handler func(ctx context.Context, e Event) error
+
+// Bus is a lightweight event bus based on redis streams, events are fire-and-forget for publisher,
+// each consumer group receive all events, and each event is processed by one consumer in group
+type Bus struct {
+	ops    Options
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	lock   sync.Mutex
+	topics map[string]struct{}
+}
+
+func New(options ...func(*Options)) (b *Bus, err error) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.redis == nil {
+		err = ErrRedisNil
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b = &Bus{
+		ops:    *ops,
+		ctx:    ctx,
+		cancel: cancel,
+		topics: make(map[string]struct{}),
+	}
+	return
+}
+
+// Publish append event to topic stream, payload can be []byte/string or any json object
+func (b *Bus) Publish(ctx context.Context, topic string, payload interface{}) (id string, err error) {
+	if topic == "" {
+		err = ErrTopicNil
+		return
+	}
+	var data []byte
+	switch v := payload.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		data, err = json.Marshal(v)
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+	}
+	id, err = b.ops.redis.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.stream(topic),
+		MaxLen: b.ops.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			fieldPayload: data,
+			fieldTime:    time.Now().UnixMilli(),
+		},
+	}).Result()
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+// Subscribe start consuming topic in background, only events published after the group created will be received
+func (b *Bus) Subscribe(topic string, handler Handler) (err error) {
+	if topic == "" {
+		err = ErrTopicNil
+		return
+	}
+	if handler == nil {
+		err = ErrHandlerNil
+		return
+	}
+	if b.ctx.Err() != nil {
+		err = ErrBusClosed
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if _, ok := b.topics[topic]; ok {
+		err = errors.Errorf("topic %s is already subscribed", topic)
+		return
+	}
+	err = b.createGroup(b.ctx, topic)
+	if err != nil {
+		return
+	}
+	b.topics[topic] = struct{}{}
+	b.wg.Add(2)
+	go b.consume(topic, handler)
+	go b.claim(topic, handler)
+	return
+}
+
+// DeadLetters read events which exceeded max retry, the latest first
+func (b *Bus) DeadLetters(ctx context.Context, topic string, count int64) (rp []Event, err error) {
+	list, err := b.ops.redis.XRevRangeN(ctx, b.deadStream(topic), "+", "-", count).Result()
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	for _, item := range list {
+		rp = append(rp, parse(topic, item, 0))
+	}
+	return
+}
+
+// Close stop all subscriptions and wait running handlers
+func (b *Bus) Close() {
+	b.cancel()
+	b.wg.Wait()
+}
+
+func (b *Bus) consume(topic string, handler Handler) {
+	defer b.wg.Done()
+	stream := b.stream(topic)
+	for b.ctx.Err() == nil {
+		list, err := b.ops.redis.XReadGroup(b.ctx, &redis.XReadGroupArgs{
+			Group:    b.ops.group,
+			Consumer: b.ops.consumer,
+			Streams:  []string{stream, ">"},
+			Count:    b.ops.batch,
+			Block:    b.ops.block,
+		}).Result()
+		if err != nil {
+			if err == redis.Nil || b.ctx.Err() != nil {
+				continue
+			}
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				// stream or group is deleted
+				_ = b.createGroup(b.ctx, topic)
+				continue
+			}
+			log.WithContext(b.ctx).WithError(err).WithFields(log.Fields{
+				"topic": topic,
+			}).Warn("read event failed")
+			time.Sleep(time.Second)
+			continue
+		}
+		for _, s := range list {
+			for _, m := range s.Messages {
+				b.handle(topic, m, 1, handler)
+			}
+		}
+	}
+}
+
+// claim retry pending events of crashed consumers or failed handlers
+func (b *Bus) claim(topic string, handler Handler) {
+	defer b.wg.Done()
+	ticker := time.NewTicker(b.ops.claimInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+			b.claimPending(topic, handler)
+		}
+	}
+}
+
+func (b *Bus) claimPending(topic string, handler Handler) {
+	ctx := b.ctx
+	stream := b.stream(topic)
+	pending, err := b.ops.redis.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  b.ops.group,
+		Idle:   b.ops.claimIdle,
+		Start:  "-",
+		End:    "+",
+		Count:  b.ops.batch,
+	}).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			log.WithContext(ctx).WithError(err).WithFields(log.Fields{
+				"topic": topic,
+			}).Warn("read pending events failed")
+		}
+		return
+	}
+	for _, item := range pending {
+		if item.RetryCount >= b.ops.maxRetry {
+			b.dead(topic, item.ID, item.RetryCount)
+			continue
+		}
+		var list []redis.XMessage
+		list, err = b.ops.redis.XClaim(ctx, &redis.XClaimArgs{
+			Stream:   stream,
+			Group:    b.ops.group,
+			Consumer: b.ops.consumer,
+			MinIdle:  b.ops.claimIdle,
+			Messages: []string{item.ID},
+		}).Result()
+		if err != nil {
+			continue
+		}
+		for _, m := range list {
+			b.handle(topic, m, item.RetryCount+1, handler)
+		}
+	}
+}
+
+func (b *Bus) handle(topic string, m redis.XMessage, retry int64, handler Handler) {
+	e := parse(topic, m, retry)
+	err := call(b.ctx, handler, e)
+	if err != nil {
+		// keep pending, it will be claimed after idle
+		log.WithContext(b.ctx).WithError(err).WithFields(log.Fields{
+			"topic": topic,
+			"id":    e.Id,
+			"retry": retry,
+		}).Warn("handle event failed")
+		return
+	}
+	// ack even if bus is closing, avoid duplicate delivery
+	err = b.ops.redis.XAck(context.Background(), b.stream(topic), b.ops.group, m.ID).Err()
+	if err != nil {
+		log.WithContext(b.ctx).WithError(err).WithFields(log.Fields{
+			"topic": topic,
+			"id":    e.Id,
+		}).Warn("ack event failed")
+	}
+}
+
+// dead move event to dead letter stream and ack it
+func (b *Bus) dead(topic, id string, retry int64) {
+	ctx := b.ctx
+	stream := b.stream(topic)
+	list, err := b.ops.redis.XRangeN(ctx, stream, id, id, 1).Result()
+	if err != nil {
+		return
+	}
+	// the event may be trimmed
+	if len(list) > 0 {
+		values := list[0].Values
+		values[fieldId] = id
+		values[fieldGroup] = b.ops.group
+		values[fieldError] = fmt.Sprintf("exceeded max retry %d", retry)
+		err = b.ops.redis.XAdd(ctx, &redis.XAddArgs{
+			Stream: b.deadStream(topic),
+			MaxLen: b.ops.maxLen,
+			Approx: true,
+			Values: values,
+		}).Err()
+		if err != nil {
+			log.WithContext(ctx).WithError(err).WithFields(log.Fields{
+				"topic": topic,
+				"id":    id,
+			}).Warn("move event to dead letter failed")
+			return
+		}
+		log.WithContext(ctx).WithFields(log.Fields{
+			"topic": topic,
+			"id":    id,
+			"retry": retry,
+		}).Error("event is moved to dead letter")
+	}
+	b.ops.redis.XAck(ctx, stream, b.ops.group, id)
+}
+
+func (b *Bus) createGroup(ctx context.Context, topic string) (err error) {
+	err = b.ops.redis.XGroupCreateMkStream(ctx, b.stream(topic), b.ops.group, "$").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		err = nil
+	}
+	err = errors.WithStack(err)
+	return
+}
+
+func (b *Bus) stream(topic string) string {
+	return strings.Join([]string{b.ops.prefix, topic}, ".")
+}
+
+func (b *Bus) deadStream(topic string) string {
+	return strings.Join([]string{b.ops.prefix, topic, "dlq"}, ".")
+}
+
+func parse(topic string, m redis.XMessage, retry int64) (e Event) {
+	e.Id = m.ID
+	if v, ok := m.Values[fieldId].(string); ok {
+		// origin id of dead letter
+		e.Id = v
+	}
+	e.Topic = topic
+	e.Retry = retry
+	if v, ok := m.Values[fieldPayload].(string); ok {
+		e.Payload = []byte(v)
+	}
+	if v, ok := m.Values[fieldTime].(string); ok {
+		ms, _ := strconv.ParseInt(v, 10, 64)
+		e.Time = time.UnixMilli(ms)
+	}
+	return
+}
+
+// call run handler and recover panic as error
+func call(ctx context.Context, handler Handler, e Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("handler panic: %v", r)
+		}
+	}()
+	err = handler(ctx, e)
+	return
+}
@@ -0,0 +1,167 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type order struct {
+	Id uint64 `json:"id"`
+}
+
+func newBus(t *testing.T, client redis.UniversalClient, options ...func(*Options)) *Bus {
+	b, err := New(append([]func(*Options){WithRedis(client)}, options...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// speed up test
+	b.ops.block = 100 * time.Millisecond
+	b.ops.claimIdle = 100 * time.Millisecond
+	b.ops.claimInterval = 50 * time.Millisecond
+	t.Cleanup(b.Close)
+	return b
+}
+
+func wait(t *testing.T, f func() bool) {
+	for i := 0; i < 100; i++ {
+		if f() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("timeout")
+}
+
+func TestBus(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	ctx := context.Background()
+
+	_, err := New()
+	if err != ErrRedisNil {
+		t.Fatalf("expect redis nil but got %v", err)
+	}
+
+	var lock sync.Mutex
+	received := make(map[string][]uint64)
+	subscribe := func(b *Bus, name string) {
+		err := b.Subscribe("order.created", func(ctx context.Context, e Event) error {
+			var o order
+			if err := e.Bind(&o); err != nil {
+				return err
+			}
+			lock.Lock()
+			received[name] = append(received[name], o.Id)
+			lock.Unlock()
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// two groups, each group has two consumers
+	subscribe(newBus(t, client, WithGroup("mail"), WithConsumer("mail1")), "mail")
+	subscribe(newBus(t, client, WithGroup("mail"), WithConsumer("mail2")), "mail")
+	subscribe(newBus(t, client, WithGroup("stat"), WithConsumer("stat1")), "stat")
+
+	pub := newBus(t, client)
+	for i := 1; i <= 10; i++ {
+		if _, err := pub.Publish(ctx, "order.created", order{Id: uint64(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wait(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(received["mail"]) == 10 && len(received["stat"]) == 10
+	})
+	time.Sleep(200 * time.Millisecond)
+	lock.Lock()
+	defer lock.Unlock()
+	if len(received["mail"]) != 10 {
+		t.Fatalf("each event should be processed once in group, got %v", received["mail"])
+	}
+}
+
+func TestBusRetry(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	ctx := context.Background()
+
+	var count int64
+	b := newBus(t, client, WithMaxRetry(3))
+	err := b.Subscribe("pay", func(ctx context.Context, e Event) error {
+		n := atomic.AddInt64(&count, 1)
+		if e.Retry != n {
+			t.Errorf("expect retry %d but got %d", n, e.Retry)
+		}
+		if string(e.Payload) == "panic" {
+			panic("boom")
+		}
+		return errors.New("failed")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = b.Subscribe("pay", func(ctx context.Context, e Event) error { return nil })
+	if err == nil {
+		t.Fatal("expect duplicate subscribe error")
+	}
+
+	id, _ := b.Publish(ctx, "pay", "panic")
+	wait(t, func() bool {
+		list, _ := b.DeadLetters(ctx, "pay", 10)
+		return len(list) == 1
+	})
+	list, _ := b.DeadLetters(ctx, "pay", 10)
+	if list[0].Id != id || string(list[0].Payload) != "panic" {
+		t.Fatalf("unexpected dead letter %+v", list[0])
+	}
+	if n := atomic.LoadInt64(&count); n != 3 {
+		t.Fatalf("expect 3 deliveries but got %d", n)
+	}
+	pending, _ := client.XPending(ctx, "eventbus.pay", "default").Result()
+	if pending.Count != 0 {
+		t.Fatalf("expect no pending but got %d", pending.Count)
+	}
+}
+
+func TestBusClaim(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	ctx := context.Background()
+
+	// crashed consumer read but never ack
+	crashed := newBus(t, client, WithConsumer("crashed"))
+	_ = crashed.createGroup(ctx, "stock")
+	_, _ = crashed.Publish(ctx, "stock", "1")
+	_, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    "default",
+		Consumer: "crashed",
+		Streams:  []string{"eventbus.stock", ">"},
+	}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan Event, 1)
+	b := newBus(t, client, WithConsumer("alive"))
+	_ = b.Subscribe("stock", func(ctx context.Context, e Event) error {
+		done <- e
+		return nil
+	})
+	select {
+	case e := <-done:
+		if string(e.Payload) != "1" || e.Retry != 2 {
+			t.Fatalf("unexpected event %+v", e)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("pending event should be claimed")
+	}
+}
@@ -0,0 +1,20 @@
+module github.com/go-cinch/common/eventbus
+
+go 1.20
+
+replace github.com/go-cinch/common/log => ../log
+
+require (
+	github.com/alicebob/miniredis/v2 v2.31.1
+	github.com/go-cinch/common/log v1.0.4
+	github.com/pkg/errors v0.9.1
+	github.com/redis/go-redis/v9 v9.2.1
+)
+
+require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/go-kratos/kratos/v2 v2.7.0 // indirect
+	github.com/yuin/gopher-lua v1.1.0 // indirect
+)
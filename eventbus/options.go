@@ -0,0 +1,116 @@
+package eventbus
+
+import (
+	"github.com/redis/go-redis/v9"
+	"os"
+	"strconv"
+	"time"
+)
+
+type Options struct {
+	redis         redis.UniversalClient
+	prefix        string
+	group         string
+	consumer      string
+	maxLen        int64
+	batch         int64
+	block         time.Duration
+	claimIdle     time.Duration
+	claimInterval time.Duration
+	maxRetry      int64
+}
+
+func WithRedis(rd redis.UniversalClient) func(*Options) {
+	return func(options *Options) {
+		if rd != nil {
+			getOptionsOrSetDefault(options).redis = rd
+		}
+	}
+}
+
+func WithPrefix(prefix string) func(*Options) {
+	return func(options *Options) {
+		if prefix != "" {
+			getOptionsOrSetDefault(options).prefix = prefix
+		}
+	}
+}
+
+// WithGroup consumer group, usually the service name, each group receive all events once
+func WithGroup(group string) func(*Options) {
+	return func(options *Options) {
+		if group != "" {
+			getOptionsOrSetDefault(options).group = group
+		}
+	}
+}
+
+// WithConsumer consumer name in group, must be unique of each instance, default hostname-pid
+func WithConsumer(consumer string) func(*Options) {
+	return func(options *Options) {
+		if consumer != "" {
+			getOptionsOrSetDefault(options).consumer = consumer
+		}
+	}
+}
+
+// WithMaxLen approximate max length of each stream, old events will be trimmed
+func WithMaxLen(count int64) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).maxLen = count
+		}
+	}
+}
+
+func WithBatch(count int64) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).batch = count
+		}
+	}
+}
+
+// WithClaimIdle pending event idle time before claimed by other consumer, handler should finish in it
+func WithClaimIdle(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).claimIdle = time.Duration(second) * time.Second
+		}
+	}
+}
+
+func WithClaimInterval(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).claimInterval = time.Duration(second) * time.Second
+		}
+	}
+}
+
+// WithMaxRetry max delivery count, event will be moved to dead letter stream if exceeds
+func WithMaxRetry(count int64) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).maxRetry = count
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		host, _ := os.Hostname()
+		return &Options{
+			prefix:        "eventbus",
+			group:         "default",
+			consumer:      host + "-" + strconv.Itoa(os.Getpid()),
+			maxLen:        10000,
+			batch:         10,
+			block:         5 * time.Second,
+			claimIdle:     60 * time.Second,
+			claimInterval: 30 * time.Second,
+			maxRetry:      3,
+		}
+	}
+	return options
+}
@@ -0,0 +1,226 @@
+package excel
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const DefaultLayout = "2006-01-02 15:04:05"
+
+// Column define one column of sheet
+type Column struct {
+	Header   string                              // header text
+	Field    string                              // struct field name or map key, nested struct by dot, e.g. Dept.Name
+	Width    float64                             // xlsx column width
+	Layout   string                              // time layout, default 2006-01-02 15:04:05
+	Required bool                                // import: header and value are required
+	Format   func(v interface{}) interface{}     // export: convert field value, e.g. status to text
+	Parse    func(s string) (interface{}, error) // import: convert cell text to field value
+}
+
+func (c Column) layout() string {
+	if c.Layout != "" {
+		return c.Layout
+	}
+	return DefaultLayout
+}
+
+// value get cell value of row, numbers are kept for xlsx
+func (c Column) value(row reflect.Value) interface{} {
+	var v interface{}
+	if rv := lookup(row, c.Field); rv.IsValid() {
+		v = rv.Interface()
+	}
+	if c.Format != nil {
+		v = c.Format(v)
+	}
+	return cell(v, c.layout())
+}
+
+func cell(v interface{}, layout string) interface{} {
+	switch item := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if item.IsZero() {
+			return ""
+		}
+		return item.Format(layout)
+	case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return ""
+		}
+		return cell(rv.Elem().Interface(), layout)
+	}
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// set parse cell text and set it to field of row(pointer of struct)
+func (c Column) set(row reflect.Value, s string) (err error) {
+	field, err := settable(row, c.Field)
+	if err != nil {
+		return
+	}
+	if c.Parse != nil {
+		var v interface{}
+		v, err = c.Parse(s)
+		if err != nil {
+			return
+		}
+		rv := reflect.ValueOf(v)
+		if !rv.IsValid() {
+			return
+		}
+		if !rv.Type().AssignableTo(field.Type()) {
+			if !rv.Type().ConvertibleTo(field.Type()) {
+				err = errors.Wrapf(ErrUnsupportedType, "%s can not be assigned to %s", rv.Type(), field.Type())
+				return
+			}
+			rv = rv.Convert(field.Type())
+		}
+		field.Set(rv)
+		return
+	}
+	err = c.parse(field, s)
+	return
+}
+
+func (c Column) parse(field reflect.Value, s string) (err error) {
+	if field.Kind() == reflect.Ptr {
+		v := reflect.New(field.Type().Elem())
+		err = c.parse(v.Elem(), s)
+		if err == nil {
+			field.Set(v)
+		}
+		return
+	}
+	if _, ok := field.Interface().(time.Time); ok {
+		var t time.Time
+		t, err = time.ParseInLocation(c.layout(), s, time.Local)
+		if err != nil {
+			// date only
+			t, err = time.ParseInLocation("2006-01-02", s, time.Local)
+		}
+		if err != nil {
+			err = errors.Wrapf(ErrInvalidValue, "%s, layout is %s", s, c.layout())
+			return
+		}
+		field.Set(reflect.ValueOf(t))
+		return
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s)
+	case reflect.Bool:
+		var v bool
+		v, err = parseBool(s)
+		if err != nil {
+			return
+		}
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var v int64
+		v, err = strconv.ParseInt(integer(s), 10, field.Type().Bits())
+		if err != nil {
+			err = errors.Wrap(ErrInvalidValue, s)
+			return
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var v uint64
+		v, err = strconv.ParseUint(integer(s), 10, field.Type().Bits())
+		if err != nil {
+			err = errors.Wrap(ErrInvalidValue, s)
+			return
+		}
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		var v float64
+		v, err = strconv.ParseFloat(s, field.Type().Bits())
+		if err != nil {
+			err = errors.Wrap(ErrInvalidValue, s)
+			return
+		}
+		field.SetFloat(v)
+	default:
+		err = errors.Wrap(ErrUnsupportedType, field.Type().String())
+	}
+	return
+}
+
+// integer trim zero decimals, sheet may format 1 as 1.0
+func integer(s string) string {
+	if i := strings.Index(s, "."); i > 0 && strings.Trim(s[i+1:], "0") == "" {
+		return s[:i]
+	}
+	return s
+}
+
+func parseBool(s string) (v bool, err error) {
+	switch strings.ToLower(s) {
+	case "1", "t", "true", "y", "yes", "是":
+		v = true
+	case "0", "f", "false", "n", "no", "否":
+	default:
+		err = errors.Wrap(ErrInvalidValue, s)
+	}
+	return
+}
+
+// lookup get field value by path, struct and map are supported
+func lookup(v reflect.Value, path string) reflect.Value {
+	for _, name := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			v = v.FieldByName(name)
+		case reflect.Map:
+			v = v.MapIndex(reflect.ValueOf(name))
+		default:
+			return reflect.Value{}
+		}
+		if !v.IsValid() {
+			return v
+		}
+	}
+	return v
+}
+
+// settable get field by path, nil pointers are created
+func settable(v reflect.Value, path string) (field reflect.Value, err error) {
+	field = v
+	for _, name := range strings.Split(path, ".") {
+		for field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				field.Set(reflect.New(field.Type().Elem()))
+			}
+			field = field.Elem()
+		}
+		if field.Kind() != reflect.Struct {
+			err = errors.Wrapf(ErrUnsupportedType, "%s of %s", field.Type(), path)
+			return
+		}
+		field = field.FieldByName(name)
+		if !field.IsValid() || !field.CanSet() {
+			err = errors.Errorf("field %s not found", path)
+			return
+		}
+	}
+	return
+}
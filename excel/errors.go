@@ -0,0 +1,14 @@
+package excel
+
+import "github.com/pkg/errors"
+
+var (
+	ErrColumnsNil      = errors.New("columns are empty")
+	ErrInvalidFormat   = errors.New("invalid file format")
+	ErrTooManyRows     = errors.New("too many rows of sheet")
+	ErrHeaderMissing   = errors.New("header is missing")
+	ErrTooManyErrors   = errors.New("too many invalid rows")
+	ErrRequired        = errors.New("value is required")
+	ErrInvalidValue    = errors.New("invalid value")
+	ErrUnsupportedType = errors.New("unsupported field type")
+)
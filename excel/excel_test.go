@@ -0,0 +1,200 @@
+package excel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+	"time"
+)
+
+type dept struct {
+	Name string
+}
+
+type user struct {
+	Id        uint64 `gorm:"primaryKey"`
+	Name      string
+	Age       int
+	Score     float64
+	Active    bool
+	Remark    *string
+	Dept      *dept `gorm:"-"`
+	CreatedAt time.Time
+}
+
+var columns = []Column{
+	{Header: "Name", Field: "Name", Width: 20, Required: true},
+	{Header: "Age", Field: "Age"},
+	{Header: "Score", Field: "Score"},
+	{Header: "Active", Field: "Active", Format: func(v interface{}) interface{} {
+		if v.(bool) {
+			return "yes"
+		}
+		return "no"
+	}},
+	{Header: "Remark", Field: "Remark"},
+	{Header: "Dept", Field: "Dept.Name"},
+	{Header: "Created", Field: "CreatedAt", Layout: "2006-01-02"},
+}
+
+func testUsers() []user {
+	remark := "vip"
+	created := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)
+	return []user{
+		{Name: "alice", Age: 18, Score: 90.5, Active: true, Remark: &remark, Dept: &dept{Name: "dev"}, CreatedAt: created},
+		{Name: "bob", Age: 25, Score: 60, CreatedAt: created},
+	}
+}
+
+func TestCsv(t *testing.T) {
+	var buf bytes.Buffer
+	e, err := NewExporter(&buf, columns, WithFormat(Csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = e.Write(context.Background(), testUsers())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = e.Close()
+	expect := "\xEF\xBB\xBFName,Age,Score,Active,Remark,Dept,Created\nalice,18,90.5,yes,vip,dev,2024-01-02\nbob,25,60,no,,,2024-01-02\n"
+	if buf.String() != expect {
+		t.Fatalf("unexpected csv %q", buf.String())
+	}
+
+	// import with invalid rows
+	data := "Created,name,Age,Active,Dept\n2024-01-02,alice,18,yes,dev\n,,\n2024-01-02,,x,no,\nbad,carol,20,y,\n"
+	list := make([]user, 0)
+	rp, err := Import[user](context.Background(), strings.NewReader(data), columns, func(ctx context.Context, rows []user) error {
+		list = append(list, rows...)
+		return nil
+	}, WithFormat(Csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rp.Total != 3 || rp.Success != 1 || len(rp.Errors) != 3 {
+		t.Fatalf("unexpected result %+v", rp)
+	}
+	// line 4: name is required and age is invalid, line 5: date is invalid
+	if rp.Errors[0].Row != 4 || !errors.Is(rp.Errors[0], ErrRequired) || rp.Errors[1].Column != "Age" || rp.Errors[2].Row != 5 {
+		t.Fatalf("unexpected errors %v", rp.Errors)
+	}
+	if len(list) != 1 || list[0].Name != "alice" || !list[0].Active || list[0].Dept.Name != "dev" || list[0].CreatedAt.Day() != 2 {
+		t.Fatalf("unexpected list %+v", list)
+	}
+
+	_, err = Import[user](context.Background(), strings.NewReader("Age\n1\n"), columns, nil, WithFormat(Csv))
+	if !errors.Is(err, ErrHeaderMissing) {
+		t.Fatalf("expect header missing but got %v", err)
+	}
+}
+
+func TestCsvEscape(t *testing.T) {
+	remark := "@SUM(A1)"
+	rows := []user{{Name: "=1+2", Age: -1, Remark: &remark}}
+	tests := []struct {
+		name    string
+		options []func(*Options)
+		want    string
+	}{
+		{
+			name: "default",
+			want: "Name,Age,Remark\n'=1+2,-1,'@SUM(A1)\n",
+		},
+		{
+			name:    "disabled",
+			options: []func(*Options){WithEscape(false)},
+			want:    "Name,Age,Remark\n=1+2,-1,@SUM(A1)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e, _ := NewExporter(&buf, []Column{columns[0], columns[1], columns[4]}, append(tt.options, WithFormat(Csv), WithBom(false))...)
+			if err := e.Write(context.Background(), rows); err != nil {
+				t.Fatal(err)
+			}
+			_ = e.Close()
+			if buf.String() != tt.want {
+				t.Fatalf("unexpected csv %q", buf.String())
+			}
+		})
+	}
+	for _, item := range []string{"+1", "-a", "\tx", "\rx"} {
+		if escape(item) != "'"+item {
+			t.Fatalf("expect %q escaped", item)
+		}
+	}
+}
+
+func TestXlsx(t *testing.T) {
+	var buf bytes.Buffer
+	e, err := NewExporter(&buf, columns, WithSheet("users"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = e.Write(context.Background(), testUsers())
+	err = e.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list := make([]user, 0)
+	var progress []int64
+	rp, err := Import[user](context.Background(), &buf, columns, func(ctx context.Context, rows []user) error {
+		list = append(list, rows...)
+		return nil
+	}, WithChunk(1), WithProgress(func(ctx context.Context, done, total int64) {
+		progress = append(progress, done)
+	}), WithValidate(func(v interface{}) error {
+		if v.(*user).Age < 20 {
+			return errors.New("too young")
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rp.Total != 2 || rp.Success != 1 || len(rp.Errors) != 1 || rp.Errors[0].Row != 2 || rp.Errors[0].Column != "" {
+		t.Fatalf("unexpected result %+v", rp)
+	}
+	if list[0].Name != "bob" || list[0].Score != 60 || list[0].Remark != nil || len(progress) != 1 {
+		t.Fatalf("unexpected list %+v, progress %v", list, progress)
+	}
+
+	_, err = Import[user](context.Background(), strings.NewReader("invalid"), columns, nil)
+	if !errors.Is(err, ErrInvalidFormat) {
+		t.Fatalf("expect invalid format but got %v", err)
+	}
+}
+
+func TestGorm(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = db.AutoMigrate(&user{})
+	for i := 0; i < 25; i++ {
+		db.Create(&user{Name: "user", Age: i})
+	}
+	var buf bytes.Buffer
+	var progress [][2]int64
+	e, _ := NewExporter(&buf, columns[:2], WithFormat(Csv), WithBom(false), WithChunk(10), WithProgress(func(ctx context.Context, done, total int64) {
+		progress = append(progress, [2]int64{done, total})
+	}))
+	err = e.Gorm(context.Background(), db.Where("age >= ?", 5), &[]user{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = e.Close()
+	if e.Count() != 20 || len(progress) != 2 || progress[1] != [2]int64{20, 20} {
+		t.Fatalf("unexpected count %d, progress %v", e.Count(), progress)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 21 || lines[1] != "user,5" {
+		t.Fatalf("unexpected csv %s", buf.String())
+	}
+}
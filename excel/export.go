@@ -0,0 +1,244 @@
+package excel
+
+import (
+	"context"
+	"encoding/csv"
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// MaxRows is the max data rows of xlsx sheet(header excluded)
+const MaxRows = 1048575
+
+// Exporter stream rows into xlsx/csv, rows are not kept in memory(xlsx stream writer uses temp file for large data)
+type Exporter struct {
+	ops     Options
+	w       io.Writer
+	columns []Column
+	file    *excelize.File
+	stream  *excelize.StreamWriter
+	csv     *csv.Writer
+	count   int64
+	total   int64
+}
+
+// NewExporter create exporter and write header, Close must be called to flush data to w
+func NewExporter(w io.Writer, columns []Column, options ...func(*Options)) (e *Exporter, err error) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if len(columns) == 0 {
+		err = ErrColumnsNil
+		return
+	}
+	e = &Exporter{
+		ops:     *ops,
+		w:       w,
+		columns: columns,
+	}
+	headers := make([]interface{}, len(columns))
+	for i, item := range columns {
+		headers[i] = item.Header
+	}
+	if ops.format == Csv {
+		if ops.bom {
+			_, err = w.Write([]byte("\xEF\xBB\xBF"))
+			if err != nil {
+				err = errors.WithStack(err)
+				return
+			}
+		}
+		e.csv = csv.NewWriter(w)
+		err = e.writeCsv(headers)
+		return
+	}
+	err = e.newXlsx(headers)
+	return
+}
+
+func (e *Exporter) newXlsx(headers []interface{}) (err error) {
+	e.file = excelize.NewFile()
+	sheet := e.ops.sheet
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+	err = e.file.SetSheetName("Sheet1", sheet)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	e.stream, err = e.file.NewStreamWriter(sheet)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	// width must be set before rows
+	for i, item := range e.columns {
+		if item.Width > 0 {
+			err = e.stream.SetColWidth(i+1, i+1, item.Width)
+			if err != nil {
+				err = errors.WithStack(err)
+				return
+			}
+		}
+	}
+	style, err := e.file.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+	})
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	err = e.stream.SetRow("A1", headers, excelize.RowOpts{StyleID: style})
+	err = errors.WithStack(err)
+	return
+}
+
+// Write append rows, rows is slice of struct/pointer/map
+func (e *Exporter) Write(ctx context.Context, rows interface{}) (err error) {
+	rv := reflect.ValueOf(rows)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		err = errors.Wrapf(ErrUnsupportedType, "rows must be slice but got %s", rv.Kind())
+		return
+	}
+	for i := 0; i < rv.Len(); i++ {
+		values := make([]interface{}, len(e.columns))
+		for j, item := range e.columns {
+			values[j] = item.value(rv.Index(i))
+		}
+		if e.csv != nil {
+			err = e.writeCsv(values)
+		} else {
+			err = e.writeXlsx(values)
+		}
+		if err != nil {
+			return
+		}
+		e.count++
+	}
+	if e.csv != nil {
+		e.csv.Flush()
+		err = errors.WithStack(e.csv.Error())
+		if err != nil {
+			return
+		}
+	}
+	if e.ops.progress != nil {
+		e.ops.progress(ctx, e.count, e.total)
+	}
+	return
+}
+
+// Gorm query by chunk(FindInBatches, order by primary key) and write rows, dest is pointer of slice, e.g. &[]User{}
+func (e *Exporter) Gorm(ctx context.Context, db *gorm.DB, dest interface{}) (err error) {
+	db = db.WithContext(ctx)
+	if e.ops.progress != nil {
+		var total int64
+		tx := db.Session(&gorm.Session{})
+		if tx.Statement.Model == nil {
+			tx = tx.Model(dest)
+		}
+		err = tx.Count(&total).Error
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+		e.total = e.count + total
+	}
+	err = db.FindInBatches(dest, e.ops.chunk, func(tx *gorm.DB, batch int) error {
+		return e.Write(ctx, dest)
+	}).Error
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+// Count get written rows(header excluded)
+func (e *Exporter) Count() int64 {
+	return e.count
+}
+
+// Close flush data to writer
+func (e *Exporter) Close() (err error) {
+	if e.csv != nil {
+		e.csv.Flush()
+		err = errors.WithStack(e.csv.Error())
+		return
+	}
+	defer e.file.Close()
+	err = e.stream.Flush()
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	_, err = e.file.WriteTo(e.w)
+	err = errors.WithStack(err)
+	return
+}
+
+func (e *Exporter) writeXlsx(values []interface{}) (err error) {
+	if e.count >= MaxRows {
+		err = ErrTooManyRows
+		return
+	}
+	cell, err := excelize.CoordinatesToCellName(1, int(e.count)+2)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	err = e.stream.SetRow(cell, values)
+	err = errors.WithStack(err)
+	return
+}
+
+func (e *Exporter) writeCsv(values []interface{}) (err error) {
+	record := make([]string, len(values))
+	for i, item := range values {
+		record[i] = text(item)
+		if _, ok := item.(string); ok && e.ops.escape {
+			record[i] = escape(record[i])
+		}
+	}
+	err = e.csv.Write(record)
+	err = errors.WithStack(err)
+	return
+}
+
+// escape formula of csv text cell, numbers are not escaped, e.g. -1
+func escape(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func text(v interface{}) string {
+	switch item := v.(type) {
+	case string:
+		return item
+	case bool:
+		return strconv.FormatBool(item)
+	case float32:
+		return strconv.FormatFloat(float64(item), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(item, 'f', -1, 64)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	}
+	return ""
+}
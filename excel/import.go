@@ -0,0 +1,256 @@
+package excel
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/xuri/excelize/v2"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// RowError is the invalid value of row, Row is the line number of sheet(header is 1), Column is empty if whole row is invalid
+type RowError struct {
+	Row    int    `json:"row"`
+	Column string `json:"column,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Err    error  `json:"-"`
+}
+
+func (e RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d: %v", e.Row, e.Err)
+	}
+	return fmt.Sprintf("row %d column %s: %v", e.Row, e.Column, e.Err)
+}
+
+func (e RowError) Unwrap() error {
+	return e.Err
+}
+
+// Result of import, Total is the count of data rows
+type Result struct {
+	Total   int64      `json:"total"`
+	Success int64      `json:"success"`
+	Errors  []RowError `json:"errors"`
+}
+
+// Import read rows from xlsx/csv and map cells to T by header, valid rows are passed to handler by chunk,
+// invalid rows are recorded to Result.Errors, handler error breaks import
+func Import[T any](ctx context.Context, r io.Reader, columns []Column, handler func(ctx context.Context, rows []T) error, options ...func(*Options)) (rp Result, err error) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if len(columns) == 0 {
+		err = ErrColumnsNil
+		return
+	}
+	if reflect.TypeOf((*T)(nil)).Elem().Kind() != reflect.Struct {
+		err = errors.Wrap(ErrUnsupportedType, "T must be struct")
+		return
+	}
+	rows, err := newRowReader(r, *ops)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	header, err := rows.Next()
+	if err == io.EOF {
+		err = errors.Wrap(ErrHeaderMissing, "sheet is empty")
+		return
+	}
+	if err != nil {
+		return
+	}
+	index, err := mapHeader(header, columns)
+	if err != nil {
+		return
+	}
+	rp.Errors = make([]RowError, 0)
+	batch := make([]T, 0, ops.chunk)
+	flush := func() (e error) {
+		if len(batch) == 0 {
+			return
+		}
+		e = handler(ctx, batch)
+		if e != nil {
+			return
+		}
+		rp.Success += int64(len(batch))
+		batch = make([]T, 0, ops.chunk)
+		if ops.progress != nil {
+			ops.progress(ctx, rp.Total, 0)
+		}
+		return
+	}
+	line := 1
+	for {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+		var record []string
+		record, err = rows.Next()
+		if err == io.EOF {
+			err = nil
+			break
+		}
+		if err != nil {
+			return
+		}
+		line++
+		if blank(record) {
+			continue
+		}
+		rp.Total++
+		item, list := parseRow[T](line, record, columns, index, ops.validate)
+		if len(list) > 0 {
+			rp.Errors = append(rp.Errors, list...)
+			if len(rp.Errors) >= ops.maxErrors {
+				err = errors.Wrapf(ErrTooManyErrors, "exceed %d", ops.maxErrors)
+				return
+			}
+			continue
+		}
+		batch = append(batch, item)
+		if len(batch) >= ops.chunk {
+			if err = flush(); err != nil {
+				return
+			}
+		}
+	}
+	err = flush()
+	return
+}
+
+func parseRow[T any](line int, record []string, columns []Column, index []int, validate func(v interface{}) error) (item T, list []RowError) {
+	rv := reflect.ValueOf(&item).Elem()
+	for i, column := range columns {
+		s := ""
+		if index[i] >= 0 && index[i] < len(record) {
+			s = strings.TrimSpace(record[index[i]])
+		}
+		if s == "" {
+			if column.Required {
+				list = append(list, RowError{Row: line, Column: column.Header, Err: ErrRequired})
+			}
+			continue
+		}
+		if err := column.set(rv, s); err != nil {
+			list = append(list, RowError{Row: line, Column: column.Header, Value: s, Err: err})
+		}
+	}
+	if len(list) == 0 && validate != nil {
+		if err := validate(&item); err != nil {
+			list = append(list, RowError{Row: line, Err: err})
+		}
+	}
+	return
+}
+
+// mapHeader get cell index of columns, -1 means the optional header is missing
+func mapHeader(header []string, columns []Column) (index []int, err error) {
+	index = make([]int, len(columns))
+	for i, column := range columns {
+		index[i] = -1
+		for j, item := range header {
+			if strings.EqualFold(strings.TrimSpace(item), strings.TrimSpace(column.Header)) {
+				index[i] = j
+				break
+			}
+		}
+		if index[i] < 0 && column.Required {
+			err = errors.Wrap(ErrHeaderMissing, column.Header)
+			return
+		}
+	}
+	return
+}
+
+func blank(record []string) bool {
+	for _, item := range record {
+		if strings.TrimSpace(item) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+type rowReader interface {
+	Next() ([]string, error)
+	Close() error
+}
+
+func newRowReader(r io.Reader, ops Options) (rp rowReader, err error) {
+	if ops.format == Csv {
+		br := bufio.NewReader(r)
+		// skip utf-8 bom
+		if bs, e := br.Peek(3); e == nil && bytes.Equal(bs, []byte("\xEF\xBB\xBF")) {
+			_, _ = br.Discard(3)
+		}
+		cr := csv.NewReader(br)
+		cr.FieldsPerRecord = -1
+		rp = &csvReader{r: cr}
+		return
+	}
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		err = errors.Wrap(ErrInvalidFormat, err.Error())
+		return
+	}
+	sheet := ops.sheet
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	}
+	rows, err := f.Rows(sheet)
+	if err != nil {
+		_ = f.Close()
+		err = errors.WithStack(err)
+		return
+	}
+	rp = &xlsxReader{file: f, rows: rows}
+	return
+}
+
+type csvReader struct {
+	r *csv.Reader
+}
+
+func (c *csvReader) Next() (record []string, err error) {
+	record, err = c.r.Read()
+	if err != nil && err != io.EOF {
+		err = errors.Wrap(ErrInvalidFormat, err.Error())
+	}
+	return
+}
+
+func (c *csvReader) Close() error {
+	return nil
+}
+
+type xlsxReader struct {
+	file *excelize.File
+	rows *excelize.Rows
+}
+
+func (x *xlsxReader) Next() (record []string, err error) {
+	if !x.rows.Next() {
+		err = x.rows.Error()
+		if err == nil {
+			err = io.EOF
+		}
+		return
+	}
+	record, err = x.rows.Columns()
+	err = errors.WithStack(err)
+	return
+}
+
+func (x *xlsxReader) Close() error {
+	_ = x.rows.Close()
+	return x.file.Close()
+}
@@ -0,0 +1,100 @@
+package excel
+
+import "context"
+
+const (
+	Xlsx = "xlsx"
+	Csv  = "csv"
+)
+
+type Options struct {
+	format    string
+	sheet     string
+	chunk     int
+	bom       bool
+	escape    bool
+	maxErrors int
+	progress  func(ctx context.Context, done, total int64)
+	validate  func(v interface{}) error
+}
+
+// WithFormat file format, xlsx or csv
+func WithFormat(s string) func(*Options) {
+	return func(options *Options) {
+		if s == Xlsx || s == Csv {
+			getOptionsOrSetDefault(options).format = s
+		}
+	}
+}
+
+// WithSheet xlsx sheet name, default Sheet1 for export and the first sheet for import
+func WithSheet(s string) func(*Options) {
+	return func(options *Options) {
+		if s != "" {
+			getOptionsOrSetDefault(options).sheet = s
+		}
+	}
+}
+
+// WithChunk rows of one batch, gorm query and import handler are called by chunk
+func WithChunk(count int) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).chunk = count
+		}
+	}
+}
+
+// WithBom write utf-8 bom before csv, or excel can not detect chinese
+func WithBom(flag bool) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).bom = flag
+	}
+}
+
+// WithEscape prefix csv text cells starting with = + - @ tab or cr by ', avoid formula injection when opened by excel
+func WithEscape(flag bool) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).escape = flag
+	}
+}
+
+// WithMaxErrors stop import when invalid rows exceed count
+func WithMaxErrors(count int) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).maxErrors = count
+		}
+	}
+}
+
+// WithProgress is called after each chunk, total is 0 if it is unknown, e.g. report by worker.SetProgress
+func WithProgress(f func(ctx context.Context, done, total int64)) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).progress = f
+		}
+	}
+}
+
+// WithValidate validate imported row(pointer of struct), error is recorded as row error
+func WithValidate(f func(v interface{}) error) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).validate = f
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			format:    Xlsx,
+			chunk:     1000,
+			bom:       true,
+			escape:    true,
+			maxErrors: 100,
+		}
+	}
+	return options
+}
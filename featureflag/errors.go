@@ -0,0 +1,10 @@
+package featureflag
+
+import "github.com/pkg/errors"
+
+var (
+	ErrRedisNil          = errors.New("redis is nil")
+	ErrFlagNameNil       = errors.New("flag name is empty")
+	ErrFlagNotFound      = errors.New("flag not found")
+	ErrPercentageInvalid = errors.New("percentage must be between 0 and 100")
+)
@@ -0,0 +1,296 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-cinch/common/log"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Flag is one feature switch, Enabled is the master switch,
+// users/tenants in allowlist are always enabled, others are enabled by percentage rollout
+type Flag struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Enabled     bool      `json:"enabled"`
+	Percentage  int       `json:"percentage"`
+	Users       []string  `json:"users,omitempty"`
+	Tenants     []string  `json:"tenants,omitempty"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Evaluate check flag for user and tenant, percentage is calculated by user(or tenant if user is empty),
+// the same subject always get the same result
+func (f Flag) Evaluate(user, tenant string) bool {
+	if !f.Enabled {
+		return false
+	}
+	if user != "" && contains(f.Users, user) {
+		return true
+	}
+	if tenant != "" && contains(f.Tenants, tenant) {
+		return true
+	}
+	if f.Percentage >= 100 {
+		return true
+	}
+	if f.Percentage <= 0 {
+		return false
+	}
+	subject := user
+	if subject == "" {
+		subject = tenant
+	}
+	if subject == "" {
+		return false
+	}
+	return bucket(f.Name, subject) < f.Percentage
+}
+
+type entry struct {
+	flag    *Flag
+	expires time.Time
+}
+
+// Flags store flags in redis hash, flags are cached locally and invalidated by pub/sub
+type Flags struct {
+	ops   Options
+	lock  sync.RWMutex
+	local map[string]entry
+	ps    *redis.PubSub
+}
+
+func New(options ...func(*Options)) (f *Flags, err error) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, fn := range options {
+		fn(ops)
+	}
+	if ops.redis == nil {
+		err = ErrRedisNil
+		return
+	}
+	f = &Flags{
+		ops:   *ops,
+		local: make(map[string]entry),
+	}
+	f.subscribe()
+	return
+}
+
+// IsEnabled check flag for user and tenant of ctx, missing flag or redis error means disabled
+func (f *Flags) IsEnabled(ctx context.Context, name string) bool {
+	return f.IsEnabledFor(ctx, name, f.ops.user(ctx), f.ops.tenant(ctx))
+}
+
+// IsEnabledFor check flag for specific user and tenant, such as in worker task
+func (f *Flags) IsEnabledFor(ctx context.Context, name, user, tenant string) bool {
+	flag, err := f.load(ctx, name)
+	if err != nil {
+		if !errors.Is(err, ErrFlagNotFound) {
+			log.WithContext(ctx).WithError(err).WithFields(log.Fields{
+				"flag": name,
+			}).Warn("load feature flag failed")
+		}
+		return false
+	}
+	return flag.Evaluate(user, tenant)
+}
+
+// Get get flag from redis, ErrFlagNotFound if missing
+func (f *Flags) Get(ctx context.Context, name string) (flag Flag, err error) {
+	if name == "" {
+		err = ErrFlagNameNil
+		return
+	}
+	v, err := f.ops.redis.HGet(ctx, f.ops.key, name).Result()
+	if err == redis.Nil {
+		err = ErrFlagNotFound
+		return
+	}
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	err = json.Unmarshal([]byte(v), &flag)
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+// List get all flags order by name
+func (f *Flags) List(ctx context.Context) (rp []Flag, err error) {
+	m, err := f.ops.redis.HGetAll(ctx, f.ops.key).Result()
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	rp = make([]Flag, 0, len(m))
+	for _, v := range m {
+		var flag Flag
+		if e := json.Unmarshal([]byte(v), &flag); e != nil {
+			continue
+		}
+		rp = append(rp, flag)
+	}
+	sort.Slice(rp, func(i, j int) bool {
+		return rp[i].Name < rp[j].Name
+	})
+	return
+}
+
+// Set create or replace flag, all instances will reload it
+func (f *Flags) Set(ctx context.Context, flag Flag) (err error) {
+	if flag.Name == "" {
+		err = ErrFlagNameNil
+		return
+	}
+	if flag.Percentage < 0 || flag.Percentage > 100 {
+		err = ErrPercentageInvalid
+		return
+	}
+	flag.UpdatedAt = time.Now()
+	bs, _ := json.Marshal(flag)
+	err = f.ops.redis.HSet(ctx, f.ops.key, flag.Name, bs).Err()
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	f.publish(ctx, flag.Name)
+	return
+}
+
+// Toggle change master switch of flag
+func (f *Flags) Toggle(ctx context.Context, name string, enabled bool) (err error) {
+	flag, err := f.Get(ctx, name)
+	if err != nil {
+		return
+	}
+	flag.Enabled = enabled
+	err = f.Set(ctx, flag)
+	return
+}
+
+// Delete remove flag, it will be disabled for everyone
+func (f *Flags) Delete(ctx context.Context, name string) (err error) {
+	if name == "" {
+		err = ErrFlagNameNil
+		return
+	}
+	err = f.ops.redis.HDel(ctx, f.ops.key, name).Err()
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	f.publish(ctx, name)
+	return
+}
+
+// Close stop subscribing flag changes
+func (f *Flags) Close() (err error) {
+	err = f.ps.Close()
+	return
+}
+
+func (f *Flags) load(ctx context.Context, name string) (flag Flag, err error) {
+	now := time.Now()
+	f.lock.RLock()
+	item, ok := f.local[name]
+	f.lock.RUnlock()
+	if ok && now.Before(item.expires) {
+		if item.flag == nil {
+			err = ErrFlagNotFound
+			return
+		}
+		flag = *item.flag
+		return
+	}
+	flag, err = f.Get(ctx, name)
+	if err != nil && !errors.Is(err, ErrFlagNotFound) {
+		if ok && item.flag != nil {
+			// use stale one if redis is unavailable
+			flag = *item.flag
+			err = nil
+		}
+		return
+	}
+	item = entry{
+		expires: now.Add(time.Duration(f.ops.localExpire) * time.Second),
+	}
+	if err == nil {
+		item.flag = &flag
+	}
+	f.lock.Lock()
+	f.local[name] = item
+	f.lock.Unlock()
+	return
+}
+
+func (f *Flags) del(names ...string) {
+	f.lock.Lock()
+	for _, name := range names {
+		delete(f.local, name)
+	}
+	f.lock.Unlock()
+}
+
+func (f *Flags) purge() {
+	f.lock.Lock()
+	f.local = make(map[string]entry)
+	f.lock.Unlock()
+}
+
+// subscribe receive flag changes, local cache will be purged when reconnect
+func (f *Flags) subscribe() {
+	ctx := context.Background()
+	f.ps = f.ops.redis.Subscribe(ctx, f.ops.channel)
+	go func() {
+		for {
+			msg, err := f.ps.Receive(ctx)
+			if err != nil {
+				if err == redis.ErrClosed {
+					return
+				}
+				// messages may be lost during disconnection
+				f.purge()
+				time.Sleep(time.Second)
+				continue
+			}
+			if m, ok := msg.(*redis.Message); ok {
+				f.del(m.Payload)
+			}
+		}
+	}()
+}
+
+func (f *Flags) publish(ctx context.Context, name string) {
+	// local instance does not wait for message
+	f.del(name)
+	err := f.ops.redis.Publish(ctx, f.ops.channel, name).Err()
+	if err != nil {
+		log.WithContext(ctx).WithError(err).WithFields(log.Fields{
+			"flag": name,
+		}).Warn("publish feature flag change failed")
+	}
+}
+
+// bucket hash name and subject into [0, 100)
+func bucket(name, subject string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name + ":" + subject))
+	return int(h.Sum32() % 100)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package featureflag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-cinch/common/jwt"
+	"github.com/redis/go-redis/v9"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	f := Flag{Name: "a", Enabled: true, Percentage: 30, Users: []string{"u1"}, Tenants: []string{"t1"}}
+	if !f.Evaluate("u1", "") || !f.Evaluate("", "t1") {
+		t.Fatal("allowlist should be enabled")
+	}
+	var count int
+	for i := 0; i < 1000; i++ {
+		user := "user" + strconv.Itoa(i)
+		ok := f.Evaluate(user, "")
+		if ok != f.Evaluate(user, "") {
+			t.Fatal("rollout should be stable")
+		}
+		if ok {
+			count++
+		}
+	}
+	if count < 250 || count > 350 {
+		t.Fatalf("unexpected rollout count %d", count)
+	}
+	if f.Evaluate("", "") {
+		t.Fatal("anonymous should be disabled in partial rollout")
+	}
+	f.Enabled = false
+	if f.Evaluate("u1", "t1") {
+		t.Fatal("master switch off")
+	}
+}
+
+type tenantKey struct{}
+
+func TestFlags(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	f1, err := New(WithRedis(client), WithTenant(func(ctx context.Context) string {
+		v, _ := ctx.Value(tenantKey{}).(string)
+		return v
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f1.Close()
+	f2, _ := New(WithRedis(client))
+	defer f2.Close()
+	// wait subscription
+	time.Sleep(50 * time.Millisecond)
+
+	ctx := jwt.NewServerContextByUser(context.Background(), jwt.User{Code: "u1"})
+	if f1.IsEnabled(ctx, "new.ui") {
+		t.Fatal("missing flag should be disabled")
+	}
+	err = f1.Set(ctx, Flag{Name: "new.ui", Enabled: true, Users: []string{"u1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !f1.IsEnabled(ctx, "new.ui") || f1.IsEnabled(context.Background(), "new.ui") {
+		t.Fatal("unexpected evaluation")
+	}
+	if f1.IsEnabled(context.WithValue(context.Background(), tenantKey{}, "t1"), "new.ui") {
+		t.Fatal("tenant is not in allowlist")
+	}
+	if !f2.IsEnabled(ctx, "new.ui") {
+		t.Fatal("other instance should be enabled")
+	}
+	// other instance is invalidated by pub/sub
+	_ = f1.Toggle(ctx, "new.ui", false)
+	time.Sleep(50 * time.Millisecond)
+	if f2.IsEnabled(ctx, "new.ui") {
+		t.Fatal("other instance should be disabled")
+	}
+	if err = f1.Set(ctx, Flag{Name: "x", Percentage: 101}); err != ErrPercentageInvalid {
+		t.Fatalf("expect percentage invalid but got %v", err)
+	}
+	list, _ := f1.List(ctx)
+	if len(list) != 1 || list[0].Name != "new.ui" {
+		t.Fatalf("unexpected list %+v", list)
+	}
+	_ = f1.Delete(ctx, "new.ui")
+	if _, err = f1.Get(ctx, "new.ui"); err != ErrFlagNotFound {
+		t.Fatalf("expect not found but got %v", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	f, _ := New(WithRedis(client))
+	defer f.Close()
+	srv := httptest.NewServer(http.StripPrefix("/flags", f.Handler()))
+	defer srv.Close()
+
+	do := func(method, path string, body interface{}) *http.Response {
+		var bs []byte
+		if body != nil {
+			bs, _ = json.Marshal(body)
+		}
+		r, _ := http.NewRequest(method, srv.URL+path, bytes.NewReader(bs))
+		res, err := http.DefaultClient.Do(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+	res := do(http.MethodPut, "/flags/beta", Flag{Enabled: true, Percentage: 100})
+	var flag Flag
+	_ = json.NewDecoder(res.Body).Decode(&flag)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || flag.Name != "beta" || !flag.Enabled {
+		t.Fatalf("unexpected put %d %+v", res.StatusCode, flag)
+	}
+	res = do(http.MethodPost, "/flags/beta/disable", nil)
+	res.Body.Close()
+	if f.IsEnabledFor(context.Background(), "beta", "u1", "") {
+		t.Fatal("flag should be disabled")
+	}
+	res = do(http.MethodGet, "/flags/", nil)
+	var list []Flag
+	_ = json.NewDecoder(res.Body).Decode(&list)
+	res.Body.Close()
+	if len(list) != 1 || list[0].Enabled {
+		t.Fatalf("unexpected list %+v", list)
+	}
+	res = do(http.MethodDelete, "/flags/beta", nil)
+	res.Body.Close()
+	res = do(http.MethodGet, "/flags/beta", nil)
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("expect 404 but got %d", res.StatusCode)
+	}
+}
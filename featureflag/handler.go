@@ -0,0 +1,92 @@
+package featureflag
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"net/http"
+	"strings"
+)
+
+// Handler admin api of flags, mount it with http.StripPrefix and protect it by your auth middleware
+//
+//	GET    /               list flags
+//	GET    /{name}         get flag
+//	PUT    /{name}         create or replace flag by json body
+//	DELETE /{name}         delete flag
+//	POST   /{name}/enable  enable flag
+//	POST   /{name}/disable disable flag
+func (f *Flags) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		path := strings.Trim(r.URL.Path, "/")
+		if path == "" {
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			list, err := f.List(ctx)
+			reply(w, list, err)
+			return
+		}
+		name, action, _ := strings.Cut(path, "/")
+		if action != "" {
+			if r.Method != http.MethodPost || (action != "enable" && action != "disable") {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			err := f.Toggle(ctx, name, action == "enable")
+			if err != nil {
+				reply(w, nil, err)
+				return
+			}
+			flag, err := f.Get(ctx, name)
+			reply(w, flag, err)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			flag, err := f.Get(ctx, name)
+			reply(w, flag, err)
+		case http.MethodPut:
+			var flag Flag
+			err := json.NewDecoder(r.Body).Decode(&flag)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			flag.Name = name
+			err = f.Set(ctx, flag)
+			if err != nil {
+				reply(w, nil, err)
+				return
+			}
+			flag, err = f.Get(ctx, name)
+			reply(w, flag, err)
+		case http.MethodDelete:
+			err := f.Delete(ctx, name)
+			if err != nil {
+				reply(w, nil, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func reply(w http.ResponseWriter, v interface{}, err error) {
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrFlagNotFound):
+			status = http.StatusNotFound
+		case errors.Is(err, ErrFlagNameNil), errors.Is(err, ErrPercentageInvalid):
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
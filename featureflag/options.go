@@ -0,0 +1,86 @@
+package featureflag
+
+import (
+	"context"
+	"github.com/go-cinch/common/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+type Options struct {
+	redis       redis.UniversalClient
+	key         string
+	channel     string
+	localExpire int
+	user        func(ctx context.Context) string
+	tenant      func(ctx context.Context) string
+}
+
+func WithRedis(rd redis.UniversalClient) func(*Options) {
+	return func(options *Options) {
+		if rd != nil {
+			getOptionsOrSetDefault(options).redis = rd
+		}
+	}
+}
+
+// WithKey redis hash key of all flags
+func WithKey(key string) func(*Options) {
+	return func(options *Options) {
+		if key != "" {
+			getOptionsOrSetDefault(options).key = key
+		}
+	}
+}
+
+// WithChannel pub/sub channel of flag changes
+func WithChannel(channel string) func(*Options) {
+	return func(options *Options) {
+		if channel != "" {
+			getOptionsOrSetDefault(options).channel = channel
+		}
+	}
+}
+
+// WithLocalExpire local cache seconds, changes are pushed by pub/sub, expire is the fallback if message lost
+func WithLocalExpire(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).localExpire = second
+		}
+	}
+}
+
+// WithUser get user id from ctx, default jwt user code
+func WithUser(f func(ctx context.Context) string) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).user = f
+		}
+	}
+}
+
+// WithTenant get tenant id from ctx, such as tenant.FromContext
+func WithTenant(f func(ctx context.Context) string) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).tenant = f
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			key:         "featureflag",
+			channel:     "featureflag.changed",
+			localExpire: 60,
+			user: func(ctx context.Context) string {
+				return jwt.FromServerContext(ctx).Code
+			},
+			tenant: func(ctx context.Context) string {
+				return ""
+			},
+		}
+	}
+	return options
+}
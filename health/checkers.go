@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"io"
+	"net/http"
+)
+
+// Redis ping redis
+func Redis(rd redis.UniversalClient) Checker {
+	return CheckerFunc(func(ctx context.Context) (err error) {
+		err = rd.Ping(ctx).Err()
+		if err != nil {
+			err = errors.WithStack(err)
+		}
+		return
+	})
+}
+
+// Gorm ping database of gorm
+func Gorm(db *gorm.DB) Checker {
+	return CheckerFunc(func(ctx context.Context) (err error) {
+		sqlDB, err := db.DB()
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+		err = sqlDB.PingContext(ctx)
+		if err != nil {
+			err = errors.WithStack(err)
+		}
+		return
+	})
+}
+
+// Http request url by GET, status code should be 2xx
+func Http(url string) Checker {
+	return CheckerFunc(func(ctx context.Context) (err error) {
+		r, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+		res, err := http.DefaultClient.Do(r)
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+		defer res.Body.Close()
+		_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 1024))
+		if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+			err = errors.Wrapf(ErrInvalidStatusCode, "%d", res.StatusCode)
+		}
+		return
+	})
+}
+
+// WorkerQueue check pending task count of worker queue(worker group), queue is too long if pending > max
+func WorkerQueue(rd redis.UniversalClient, queue string, max int) Checker {
+	inspector := asynq.NewInspector(redisConnOpt{rd: rd})
+	return CheckerFunc(func(ctx context.Context) (err error) {
+		info, err := inspector.GetQueueInfo(queue)
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			// no task enqueued yet
+			err = nil
+			return
+		}
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+		if info.Pending > max {
+			err = errors.Wrapf(ErrQueueTooLong, "%s pending %d > %d", queue, info.Pending, max)
+		}
+		return
+	})
+}
+
+// redisConnOpt reuse redis client for asynq inspector
+type redisConnOpt struct {
+	rd redis.UniversalClient
+}
+
+func (r redisConnOpt) MakeRedisClient() interface{} {
+	return r.rd
+}
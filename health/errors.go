@@ -0,0 +1,11 @@
+package health
+
+import "github.com/pkg/errors"
+
+var (
+	ErrNameNil           = errors.New("checker name is empty")
+	ErrCheckerNil        = errors.New("checker is nil")
+	ErrCheckerExists     = errors.New("checker already exists")
+	ErrInvalidStatusCode = errors.New("invalid status code")
+	ErrQueueTooLong      = errors.New("queue is too long")
+)
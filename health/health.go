@@ -0,0 +1,234 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp       = "up"
+	StatusDown     = "down"
+	StatusDegraded = "degraded"
+)
+
+// Checker check one dependency, return error if it is unavailable
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is func adapter of Checker
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Result is the result of one checker
+type Result struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// Report is the aggregated result, Status is down if any required checker failed
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+	Time   time.Time         `json:"time"`
+}
+
+type checker struct {
+	name    string
+	checker Checker
+	ops     CheckOptions
+}
+
+type cached struct {
+	report  Report
+	expires time.Time
+}
+
+// Health aggregate checkers of all modules, checkers run concurrently with timeout
+type Health struct {
+	ops      Options
+	lock     sync.RWMutex
+	checkers []checker
+	cache    map[bool]cached
+}
+
+func New(options ...func(*Options)) (h *Health) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	h = &Health{
+		ops:   *ops,
+		cache: make(map[bool]cached),
+	}
+	return
+}
+
+// Register add named checker, name must be unique
+func (h *Health) Register(name string, c Checker, options ...func(*CheckOptions)) (err error) {
+	if name == "" {
+		err = ErrNameNil
+		return
+	}
+	if c == nil {
+		err = ErrCheckerNil
+		return
+	}
+	ops := getCheckOptionsOrSetDefault(nil)
+	ops.timeout = h.ops.timeout
+	for _, f := range options {
+		f(ops)
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for _, item := range h.checkers {
+		if item.name == name {
+			err = errors.Wrap(ErrCheckerExists, name)
+			return
+		}
+	}
+	h.checkers = append(h.checkers, checker{
+		name:    name,
+		checker: c,
+		ops:     *ops,
+	})
+	h.cache = make(map[bool]cached)
+	return
+}
+
+// Liveness run checkers registered with WithCheckLiveness, report is up if there is no such checker
+func (h *Health) Liveness(ctx context.Context) Report {
+	return h.report(ctx, true)
+}
+
+// Readiness run all checkers
+func (h *Health) Readiness(ctx context.Context) Report {
+	return h.report(ctx, false)
+}
+
+// LiveHandler is the /healthz handler, srv.Handle("/healthz", h.LiveHandler())
+func (h *Health) LiveHandler() http.Handler {
+	return h.handler(true)
+}
+
+// ReadyHandler is the /readyz handler, srv.Handle("/readyz", h.ReadyHandler())
+func (h *Health) ReadyHandler() http.Handler {
+	return h.handler(false)
+}
+
+func (h *Health) handler(liveness bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := h.report(r.Context(), liveness)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if report.Status == StatusDown {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
+
+func (h *Health) report(ctx context.Context, liveness bool) Report {
+	h.lock.RLock()
+	item, ok := h.cache[liveness]
+	h.lock.RUnlock()
+	if ok && time.Now().Before(item.expires) {
+		return item.report
+	}
+	report := h.run(ctx, liveness)
+	// do not cache the report of canceled request
+	if h.ops.ttl > 0 && ctx.Err() == nil {
+		h.lock.Lock()
+		h.cache[liveness] = cached{
+			report:  report,
+			expires: time.Now().Add(h.ops.ttl),
+		}
+		h.lock.Unlock()
+	}
+	return report
+}
+
+func (h *Health) run(ctx context.Context, liveness bool) (report Report) {
+	h.lock.RLock()
+	list := make([]checker, 0, len(h.checkers))
+	for _, item := range h.checkers {
+		if !liveness || item.ops.liveness {
+			list = append(list, item)
+		}
+	}
+	h.lock.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].name < list[j].name
+	})
+
+	results := make([]Result, len(list))
+	var wg sync.WaitGroup
+	for i, item := range list {
+		wg.Add(1)
+		go func(i int, item checker) {
+			defer wg.Done()
+			results[i] = check(ctx, item)
+		}(i, item)
+	}
+	wg.Wait()
+
+	report.Status = StatusUp
+	report.Time = time.Now()
+	if len(list) > 0 {
+		report.Checks = make(map[string]Result, len(list))
+	}
+	for i, item := range list {
+		report.Checks[item.name] = results[i]
+		if results[i].Status == StatusUp {
+			continue
+		}
+		if item.ops.optional {
+			if report.Status == StatusUp {
+				report.Status = StatusDegraded
+			}
+		} else {
+			report.Status = StatusDown
+		}
+	}
+	return
+}
+
+func check(ctx context.Context, c checker) (rp Result) {
+	ctx, cancel := context.WithTimeout(ctx, c.ops.timeout)
+	defer cancel()
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- errors.Errorf("checker panic: %v", r)
+			}
+		}()
+		done <- c.checker.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// checker ignores ctx
+		err = ctx.Err()
+	}
+	rp.Duration = time.Since(start).String()
+	rp.Optional = c.ops.optional
+	rp.Status = StatusUp
+	if err != nil {
+		rp.Status = StatusDown
+		rp.Error = err.Error()
+	}
+	return
+}
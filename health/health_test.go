@@ -0,0 +1,101 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHealth(t *testing.T) {
+	h := New(WithTimeout(50*time.Millisecond), WithTtl(0))
+	var count int32
+	_ = h.Register("db", CheckerFunc(func(ctx context.Context) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	}), WithCheckLiveness(true))
+	if err := h.Register("db", CheckerFunc(func(ctx context.Context) error { return nil })); !errors.Is(err, ErrCheckerExists) {
+		t.Fatalf("expect exists but got %v", err)
+	}
+	_ = h.Register("search", CheckerFunc(func(ctx context.Context) error {
+		return errors.New("unavailable")
+	}), WithCheckOptional(true))
+	ctx := context.Background()
+
+	report := h.Readiness(ctx)
+	if report.Status != StatusDegraded || report.Checks["search"].Error != "unavailable" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	// slow checker ignores ctx
+	_ = h.Register("slow", CheckerFunc(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}))
+	start := time.Now()
+	report = h.Readiness(ctx)
+	if report.Status != StatusDown || report.Checks["slow"].Error != context.DeadlineExceeded.Error() || time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	// liveness only run db
+	report = h.Liveness(ctx)
+	if report.Status != StatusUp || len(report.Checks) != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+}
+
+func TestHealthCache(t *testing.T) {
+	h := New(WithTtl(time.Minute))
+	var count int32
+	_ = h.Register("a", CheckerFunc(func(ctx context.Context) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	}))
+	h.Readiness(context.Background())
+	h.Readiness(context.Background())
+	if count != 1 {
+		t.Fatalf("unexpected check count %d", count)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	dep := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ok" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer dep.Close()
+	h := New(WithTtl(0))
+	_ = h.Register("redis", Redis(client))
+	_ = h.Register("dep", Http(dep.URL+"/ok"))
+	srv := httptest.NewServer(h.ReadyHandler())
+	defer srv.Close()
+
+	get := func() (code int, report Report) {
+		res, err := http.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		_ = json.NewDecoder(res.Body).Decode(&report)
+		code = res.StatusCode
+		return
+	}
+	code, report := get()
+	if code != http.StatusOK || report.Status != StatusUp {
+		t.Fatalf("unexpected report %d %+v", code, report)
+	}
+	_ = h.Register("dep2", Http(dep.URL+"/fail"))
+	s.Close()
+	code, report = get()
+	if code != http.StatusServiceUnavailable || report.Checks["redis"].Status != StatusDown || report.Checks["dep2"].Status != StatusDown {
+		t.Fatalf("unexpected report %d %+v", code, report)
+	}
+}
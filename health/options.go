@@ -0,0 +1,71 @@
+package health
+
+import "time"
+
+type Options struct {
+	timeout time.Duration
+	ttl     time.Duration
+}
+
+// WithTimeout default timeout of each checker
+func WithTimeout(timeout time.Duration) func(*Options) {
+	return func(options *Options) {
+		if timeout > 0 {
+			getOptionsOrSetDefault(options).timeout = timeout
+		}
+	}
+}
+
+// WithTtl cache report to protect dependencies from frequent probes, 0 means no cache
+func WithTtl(ttl time.Duration) func(*Options) {
+	return func(options *Options) {
+		if ttl >= 0 {
+			getOptionsOrSetDefault(options).ttl = ttl
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			timeout: 3 * time.Second,
+			ttl:     time.Second,
+		}
+	}
+	return options
+}
+
+type CheckOptions struct {
+	timeout  time.Duration
+	liveness bool
+	optional bool
+}
+
+func WithCheckTimeout(timeout time.Duration) func(*CheckOptions) {
+	return func(options *CheckOptions) {
+		if timeout > 0 {
+			getCheckOptionsOrSetDefault(options).timeout = timeout
+		}
+	}
+}
+
+// WithCheckLiveness checker is also used by liveness, by default only readiness runs it
+func WithCheckLiveness(flag bool) func(*CheckOptions) {
+	return func(options *CheckOptions) {
+		getCheckOptionsOrSetDefault(options).liveness = flag
+	}
+}
+
+// WithCheckOptional failure of checker only degrades the report, status code is still 200
+func WithCheckOptional(flag bool) func(*CheckOptions) {
+	return func(options *CheckOptions) {
+		getCheckOptionsOrSetDefault(options).optional = flag
+	}
+}
+
+func getCheckOptionsOrSetDefault(options *CheckOptions) *CheckOptions {
+	if options == nil {
+		return &CheckOptions{}
+	}
+	return options
+}
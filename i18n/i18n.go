@@ -26,14 +26,11 @@ func New(options ...func(*Options)) (rp *I18n) {
 	}
 	bundle := i18n.NewBundle(ops.language)
 	localizer := i18n.NewLocalizer(bundle, ops.language.String())
-	switch ops.format {
-	case "toml":
-		bundle.RegisterUnmarshalFunc("toml", toml.Unmarshal)
-	case "json":
-		bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
-	default:
-		bundle.RegisterUnmarshalFunc("yml", yaml.Unmarshal)
-	}
+	// all formats are registered, files can be mixed
+	bundle.RegisterUnmarshalFunc("toml", toml.Unmarshal)
+	bundle.RegisterUnmarshalFunc("json", json.Unmarshal)
+	bundle.RegisterUnmarshalFunc("yml", yaml.Unmarshal)
+	bundle.RegisterUnmarshalFunc("yaml", yaml.Unmarshal)
 	rp = &I18n{
 		ops:       *ops,
 		bundle:    bundle,
@@ -66,19 +63,58 @@ func (i I18n) Language() language.Tag {
 }
 
 func (i I18n) T(id string) (rp string) {
-	var err error
-	rp, err = i.localizer.Localize(&i18n.LocalizeConfig{
+	return i.localize(&i18n.LocalizeConfig{
 		DefaultMessage: &i18n.Message{
 			ID: id,
 		},
+	}, id)
+}
+
+// Template translate with template params, e.g. 'hello: Hello {{.Name}}!'
+func (i I18n) Template(id string, data interface{}) (rp string) {
+	return i.localize(&i18n.LocalizeConfig{
+		MessageID:    id,
+		TemplateData: data,
+	}, id)
+}
+
+// Plural translate by plural rules of current language, count is also set to template params PluralCount if data is nil
+func (i I18n) Plural(id string, count interface{}, data ...interface{}) (rp string) {
+	var templateData interface{}
+	if len(data) > 0 {
+		templateData = data[0]
+	}
+	return i.localize(&i18n.LocalizeConfig{
+		MessageID:    id,
+		PluralCount:  count,
+		TemplateData: templateData,
+	}, id)
+}
+
+// Exists check whether the id has translation in current language(or default language)
+func (i I18n) Exists(id string) bool {
+	rp, err := i.localizer.Localize(&i18n.LocalizeConfig{
+		MessageID: id,
 	})
-	if err != nil || rp == "" {
+	return rp != "" && (err == nil || isNotFound(err))
+}
+
+// localize use the message of default language when current language has no translation, use id if both have not
+func (i I18n) localize(config *i18n.LocalizeConfig, id string) (rp string) {
+	var err error
+	rp, err = i.localizer.Localize(config)
+	if rp == "" || (err != nil && !isNotFound(err)) {
 		// use id as default message when unable to translate
 		rp = id
 	}
 	return
 }
 
+func isNotFound(err error) bool {
+	var e *i18n.MessageNotFoundErr
+	return errors.As(err, &e)
+}
+
 func (i I18n) E(id string) error {
 	return errors.Errorf(i.T(id))
 }
@@ -24,3 +24,37 @@ func TestNew(t *testing.T) {
 	fmt.Println(i.T("common.hello"))
 	fmt.Println(i.Select(language.Chinese).T("common.hello"))
 }
+
+func TestI18n_Template(t *testing.T) {
+	i := New(WithFile("./locales"))
+	if rp := i.Template("common.welcome", map[string]interface{}{"Name": "cinch"}); rp != "Welcome, cinch!" {
+		t.Fatalf("Template() = %s", rp)
+	}
+	if rp := i.Select(language.Chinese).Template("common.welcome", map[string]string{"Name": "cinch"}); rp != "欢迎, cinch!" {
+		t.Fatalf("Template() = %s", rp)
+	}
+	if rp := i.Template("common.unknown", nil); rp != "common.unknown" {
+		t.Fatalf("Template() = %s", rp)
+	}
+}
+
+func TestI18n_Plural(t *testing.T) {
+	i := New(WithFile("./locales"))
+	if rp := i.Plural("common.apple", 1); rp != "1 apple" {
+		t.Fatalf("Plural() = %s", rp)
+	}
+	if rp := i.Plural("common.apple", 2); rp != "2 apples" {
+		t.Fatalf("Plural() = %s", rp)
+	}
+	if rp := i.Select(language.Chinese).Plural("common.apple", 2); rp != "2个苹果" {
+		t.Fatalf("Plural() = %s", rp)
+	}
+	if !i.Exists("common.hello") || i.Exists("common.unknown") {
+		t.Fatal("Exists() failed")
+	}
+	// fallback to default language
+	zh := i.Select(language.Chinese)
+	if !zh.Exists("common.default") || zh.T("common.default") != "Default" {
+		t.Fatalf("T() = %s", zh.T("common.default"))
+	}
+}
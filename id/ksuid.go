@@ -0,0 +1,93 @@
+package id
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const (
+	ksuidChars      = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	ksuidLen        = 27
+	ksuidPayloadLen = 16
+	// ksuid epoch is 2014-05-13T16:53:20Z, extend the lifetime of 32 bits timestamp
+	ksuidEpoch = 1400000000
+)
+
+var (
+	ErrKsuidInvalidLength = errors.New("invalid ksuid length")
+	ErrKsuidInvalidChar   = errors.New("invalid ksuid char")
+	ErrKsuidOverflow      = errors.New("ksuid overflow")
+)
+
+// Ksuid is 32 bits second timestamp + 128 bits random payload, lexicographically sortable
+type Ksuid [20]byte
+
+var ksuidBase = big.NewInt(int64(len(ksuidChars)))
+
+// NewKsuid get a ksuid string with crypto-rand payload
+func NewKsuid() string {
+	return NewKsuidAt(time.Now()).String()
+}
+
+// NewKsuidAt get a ksuid with the specified time
+func NewKsuidAt(t time.Time) (k Ksuid) {
+	binary.BigEndian.PutUint32(k[:4], uint32(t.Unix()-ksuidEpoch))
+	_, _ = rand.Read(k[4:])
+	return
+}
+
+// ParseKsuid parse ksuid from string
+func ParseKsuid(s string) (k Ksuid, err error) {
+	if len(s) != ksuidLen {
+		err = errors.WithStack(ErrKsuidInvalidLength)
+		return
+	}
+	n := new(big.Int)
+	for i := 0; i < len(s); i++ {
+		idx := strings.IndexByte(ksuidChars, s[i])
+		if idx < 0 {
+			err = errors.WithStack(ErrKsuidInvalidChar)
+			return
+		}
+		n.Mul(n, ksuidBase)
+		n.Add(n, big.NewInt(int64(idx)))
+	}
+	bs := n.Bytes()
+	if len(bs) > len(k) {
+		err = errors.WithStack(ErrKsuidOverflow)
+		return
+	}
+	copy(k[len(k)-len(bs):], bs)
+	return
+}
+
+// ValidKsuid check whether the string is a valid ksuid
+func ValidKsuid(s string) bool {
+	_, err := ParseKsuid(s)
+	return err == nil
+}
+
+func (k Ksuid) String() string {
+	n := new(big.Int).SetBytes(k[:])
+	b := make([]byte, ksuidLen)
+	mod := new(big.Int)
+	for i := ksuidLen - 1; i >= 0; i-- {
+		n.DivMod(n, ksuidBase, mod)
+		b[i] = ksuidChars[mod.Int64()]
+	}
+	return string(b)
+}
+
+// Time get the timestamp of ksuid
+func (k Ksuid) Time() time.Time {
+	return time.Unix(int64(binary.BigEndian.Uint32(k[:4]))+ksuidEpoch, 0)
+}
+
+// Payload get the random payload of ksuid
+func (k Ksuid) Payload() []byte {
+	return k[4:]
+}
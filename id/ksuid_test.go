@@ -0,0 +1,33 @@
+package id
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewKsuid(t *testing.T) {
+	now := time.Unix(time.Now().Unix(), 0)
+	for i := 0; i < 1000; i++ {
+		item := NewKsuid()
+		k, err := ParseKsuid(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if k.String() != item {
+			t.Fatalf("parse mismatch: %s != %s", k.String(), item)
+		}
+		if k.Time().Before(now) {
+			t.Fatalf("ksuid time = %v, want >= %v", k.Time(), now)
+		}
+	}
+
+	if NewKsuidAt(now).String() >= NewKsuidAt(now.Add(time.Second)).String() {
+		t.Fatal("ksuid not sortable")
+	}
+
+	for _, s := range []string{"", "0ujtsYcgvSTl8PAuAdqWYSMnLO", "0ujtsYcgvSTl8PAuAdqWYSMnLO_", "zzzzzzzzzzzzzzzzzzzzzzzzzzz"} {
+		if ValidKsuid(s) {
+			t.Fatalf("ksuid %s should be invalid", s)
+		}
+	}
+}
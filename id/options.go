@@ -1,6 +1,9 @@
 package id
 
-import "time"
+import (
+	"github.com/redis/go-redis/v9"
+	"time"
+)
 
 type CodeOptions struct {
 	chars []rune
@@ -72,8 +75,12 @@ func getCodeOptionsOrSetDefault(options *CodeOptions) *CodeOptions {
 }
 
 type SonyflakeOptions struct {
-	machineId uint16
-	startTime time.Time
+	machineId     uint16
+	startTime     time.Time
+	redis         redis.UniversalClient
+	prefix        string
+	machineExpire int
+	maxMachineId  uint16
 }
 
 func WithSonyflakeMachineId(id uint16) func(*SonyflakeOptions) {
@@ -92,11 +99,47 @@ func WithSonyflakeStartTime(startTime time.Time) func(*SonyflakeOptions) {
 	}
 }
 
+// WithSonyflakeRedis allocate machine id by redis, each pod will get a different machine id
+func WithSonyflakeRedis(rd redis.UniversalClient) func(*SonyflakeOptions) {
+	return func(options *SonyflakeOptions) {
+		if rd != nil {
+			getSonyflakeOptionsOrSetDefault(options).redis = rd
+		}
+	}
+}
+
+func WithSonyflakePrefix(prefix string) func(*SonyflakeOptions) {
+	return func(options *SonyflakeOptions) {
+		if prefix != "" {
+			getSonyflakeOptionsOrSetDefault(options).prefix = prefix
+		}
+	}
+}
+
+func WithSonyflakeMachineExpire(second int) func(*SonyflakeOptions) {
+	return func(options *SonyflakeOptions) {
+		if second > 0 {
+			getSonyflakeOptionsOrSetDefault(options).machineExpire = second
+		}
+	}
+}
+
+func WithSonyflakeMaxMachineId(id uint16) func(*SonyflakeOptions) {
+	return func(options *SonyflakeOptions) {
+		if id > 0 {
+			getSonyflakeOptionsOrSetDefault(options).maxMachineId = id
+		}
+	}
+}
+
 func getSonyflakeOptionsOrSetDefault(options *SonyflakeOptions) *SonyflakeOptions {
 	if options == nil {
 		return &SonyflakeOptions{
-			machineId: 1,
-			startTime: time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC),
+			machineId:     1,
+			startTime:     time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC),
+			prefix:        "sonyflake.machine",
+			machineExpire: 60,
+			maxMachineId:  1023,
 		}
 	}
 	return options
@@ -2,16 +2,55 @@ package id
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"github.com/go-cinch/common/log"
 	"github.com/pkg/errors"
 	"github.com/sony/sonyflake"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+const (
+	// renew the key only if it is still owned by current generator
+	luaRenew = `
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('pexpire', KEYS[1], ARGV[2])
+end
+return 0
+`
+	// release the key only if it is still owned by current generator
+	luaRelease = `
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('del', KEYS[1])
+end
+return 0
+`
+)
+
+var ErrMachineIdLost = errors.New("sonyflake machine id lease is lost, ids may be duplicate")
+
 type Sonyflake struct {
-	ops   SonyflakeOptions
-	sf    *sonyflake.Sonyflake
-	Error error
+	ops       SonyflakeOptions
+	sf        *sonyflake.Sonyflake
+	machineId uint16
+	owner     string       // random value of machine id key
+	deadline  atomic.Int64 // unix nano, the lease may be taken by others after it
+	lost      atomic.Bool
+	stop      chan struct{}
+	once      sync.Once
+	Error     error
+}
+
+// SonyflakeParts is the decomposed snowflake id, convenient for debugging
+type SonyflakeParts struct {
+	Id        uint64    `json:"id"`
+	Time      time.Time `json:"time"`
+	Sequence  uint64    `json:"sequence"`
+	MachineId uint64    `json:"machineId"`
 }
 
 // NewSonyflake can get a unique code by id(You need to ensure that id is unique)
@@ -21,19 +60,27 @@ func NewSonyflake(options ...func(*SonyflakeOptions)) *Sonyflake {
 		f(ops)
 	}
 	sf := &Sonyflake{
-		ops: *ops,
+		ops:       *ops,
+		machineId: ops.machineId,
+	}
+	if ops.redis != nil {
+		sf.Error = sf.allocate(context.Background())
+		if sf.Error != nil {
+			return sf
+		}
 	}
 	st := sonyflake.Settings{
 		StartTime: ops.startTime,
 	}
-	if ops.machineId > 0 {
+	if sf.machineId > 0 {
 		st.MachineID = func() (uint16, error) {
-			return ops.machineId, nil
+			return sf.machineId, nil
 		}
 	}
 	ins := sonyflake.NewSonyflake(st)
 	if ins == nil {
 		sf.Error = errors.Errorf("create snoyflake failed")
+		return sf
 	}
 	_, err := ins.NextID()
 	if err != nil {
@@ -48,6 +95,11 @@ func (s *Sonyflake) Id(ctx context.Context) (id uint64) {
 		log.WithContext(ctx).WithError(s.Error).Warn(s.Error)
 		return
 	}
+	if s.owner != "" && (s.lost.Load() || time.Now().UnixNano() > s.deadline.Load()) {
+		// stop issuing ids, another generator may use the same machine id
+		log.WithContext(ctx).WithError(ErrMachineIdLost).Warn(ErrMachineIdLost)
+		return
+	}
 	var err error
 	id, err = s.sf.NextID()
 	if err == nil {
@@ -64,3 +116,105 @@ func (s *Sonyflake) Id(ctx context.Context) (id uint64) {
 		sleep *= 2
 	}
 }
+
+// MachineId get the machine id of current generator
+func (s *Sonyflake) MachineId() uint16 {
+	return s.machineId
+}
+
+// Decompose split id into time/sequence/machine id
+func (s *Sonyflake) Decompose(id uint64) SonyflakeParts {
+	m := sonyflake.Decompose(id)
+	return SonyflakeParts{
+		Id:        id,
+		Time:      s.ops.startTime.Add(time.Duration(m["time"]) * 10 * time.Millisecond),
+		Sequence:  m["sequence"],
+		MachineId: m["machine-id"],
+	}
+}
+
+// Close release the machine id allocated by redis, recommend to call it when the pod exits
+func (s *Sonyflake) Close(ctx context.Context) {
+	if s.owner == "" {
+		return
+	}
+	s.once.Do(func() {
+		close(s.stop)
+		err := s.ops.redis.Eval(ctx, luaRelease, []string{s.machineKey(s.machineId)}, s.owner).Err()
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Warn("release sonyflake machine id %d failed", s.machineId)
+		}
+	})
+}
+
+// allocate try to set machine id key with random owner one by one, keep the lease alive until Close
+func (s *Sonyflake) allocate(ctx context.Context) (err error) {
+	b := make([]byte, 16)
+	_, err = rand.Read(b)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	owner := hex.EncodeToString(b)
+	expire := time.Duration(s.ops.machineExpire) * time.Second
+	var i uint16
+	for i = 1; i <= s.ops.maxMachineId; i++ {
+		var ok bool
+		ok, err = s.ops.redis.SetNX(ctx, s.machineKey(i), owner, expire).Result()
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+		if ok {
+			s.machineId = i
+			s.owner = owner
+			s.deadline.Store(time.Now().Add(expire).UnixNano())
+			s.stop = make(chan struct{})
+			go s.keepalive()
+			return
+		}
+		if i == s.ops.maxMachineId {
+			break
+		}
+	}
+	err = errors.Errorf("no available machine id, max: %d", s.ops.maxMachineId)
+	return
+}
+
+func (s *Sonyflake) keepalive() {
+	ticker := time.NewTicker(time.Duration(s.ops.machineExpire) * time.Second / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if !s.renew(context.Background()) {
+				return
+			}
+		}
+	}
+}
+
+// renew extend the lease, return false if it is taken by others
+func (s *Sonyflake) renew(ctx context.Context) bool {
+	start := time.Now()
+	expire := time.Duration(s.ops.machineExpire) * time.Second
+	res, err := s.ops.redis.Eval(ctx, luaRenew, []string{s.machineKey(s.machineId)}, s.owner, expire.Milliseconds()).Int64()
+	if err != nil {
+		// retry next tick, Id stops issuing after deadline
+		log.WithContext(ctx).WithError(err).Warn("renew sonyflake machine id %d failed", s.machineId)
+		return true
+	}
+	if res == 0 {
+		s.lost.Store(true)
+		log.WithContext(ctx).Error("sonyflake machine id %d is expired or taken by others, stop issuing ids", s.machineId)
+		return false
+	}
+	s.deadline.Store(start.Add(expire).UnixNano())
+	return true
+}
+
+func (s *Sonyflake) machineKey(machineId uint16) string {
+	return strings.Join([]string{s.ops.prefix, fmt.Sprintf("%d", machineId)}, ".")
+}
@@ -3,6 +3,8 @@ package id
 import (
 	"context"
 	"fmt"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"testing"
 	"time"
 )
@@ -20,3 +22,68 @@ func TestNewSonyflake(t *testing.T) {
 		i++
 	}
 }
+
+func TestSonyflakeRedis(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	sf1 := NewSonyflake(WithSonyflakeRedis(client))
+	sf2 := NewSonyflake(WithSonyflakeRedis(client))
+	if sf1.Error != nil || sf2.Error != nil {
+		t.Fatal(sf1.Error, sf2.Error)
+	}
+	if sf1.MachineId() == sf2.MachineId() {
+		t.Fatalf("duplicate machine id %d", sf1.MachineId())
+	}
+	sf1.Close(context.Background())
+	sf3 := NewSonyflake(WithSonyflakeRedis(client))
+	if sf3.MachineId() != sf1.MachineId() {
+		t.Fatalf("machine id %d not reused, got %d", sf1.MachineId(), sf3.MachineId())
+	}
+	sf4 := NewSonyflake(WithSonyflakeRedis(client), WithSonyflakeMaxMachineId(2))
+	if sf4.Error == nil {
+		t.Fatal("expect no available machine id")
+	}
+
+	id := sf2.Id(context.Background())
+	parts := sf2.Decompose(id)
+	if parts.MachineId != uint64(sf2.MachineId()) || time.Since(parts.Time) > time.Minute {
+		t.Fatalf("invalid decompose: %+v", parts)
+	}
+}
+
+func TestSonyflakeLeaseLost(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	sf1 := NewSonyflake(WithSonyflakeRedis(client), WithSonyflakeMachineExpire(3))
+	if sf1.Error != nil {
+		t.Fatal(sf1.Error)
+	}
+	ctx := context.Background()
+	if !sf1.renew(ctx) || sf1.Id(ctx) == 0 {
+		t.Fatal("expect lease renewed")
+	}
+	// the key is expired and taken by another instance
+	s.FastForward(4 * time.Second)
+	sf2 := NewSonyflake(WithSonyflakeRedis(client), WithSonyflakeMachineExpire(3))
+	if sf2.Error != nil || sf2.MachineId() != sf1.MachineId() {
+		t.Fatalf("expect the same machine id but got %d %v", sf2.MachineId(), sf2.Error)
+	}
+	if sf1.renew(ctx) {
+		t.Fatal("expect lease lost")
+	}
+	if sf1.Id(ctx) != 0 {
+		t.Fatal("expect no id after lease lost")
+	}
+	// close must not delete the key of others
+	sf1.Close(ctx)
+	if !s.Exists(sf2.machineKey(sf2.MachineId())) {
+		t.Fatal("key of others is deleted")
+	}
+	if sf2.Id(ctx) == 0 {
+		t.Fatal("expect id")
+	}
+	sf2.Close(ctx)
+	if s.Exists(sf2.machineKey(sf2.MachineId())) {
+		t.Fatal("key should be deleted")
+	}
+}
@@ -0,0 +1,165 @@
+package id
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"sync"
+	"time"
+)
+
+// crockford base32, exclude I,L,O,U
+const ulidChars = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+const (
+	ulidLen        = 26
+	ulidEntropyLen = 10
+)
+
+var (
+	ErrUlidInvalidLength = errors.New("invalid ulid length")
+	ErrUlidInvalidChar   = errors.New("invalid ulid char")
+	ErrUlidOverflow      = errors.New("ulid overflow")
+)
+
+// Ulid is 48 bits millisecond timestamp + 80 bits random entropy, lexicographically sortable
+type Ulid [16]byte
+
+var ulidDecoding [256]byte
+
+func init() {
+	for i := range ulidDecoding {
+		ulidDecoding[i] = 0xFF
+	}
+	for i := 0; i < len(ulidChars); i++ {
+		ulidDecoding[ulidChars[i]] = byte(i)
+		// lower case is also valid
+		if ulidChars[i] >= 'A' && ulidChars[i] <= 'Z' {
+			ulidDecoding[ulidChars[i]+'a'-'A'] = byte(i)
+		}
+	}
+}
+
+// monotonic entropy, ids generated in the same millisecond keep increasing
+var ulidState = struct {
+	sync.Mutex
+	ms      uint64
+	entropy [ulidEntropyLen]byte
+}{}
+
+// NewUlid get a ulid string with crypto-rand entropy
+func NewUlid() string {
+	return NewUlidAt(time.Now()).String()
+}
+
+// NewUlidAt get a ulid with the specified time
+func NewUlidAt(t time.Time) (u Ulid) {
+	ms := uint64(t.UnixMilli())
+	ulidState.Lock()
+	defer ulidState.Unlock()
+	if ms != ulidState.ms || incEntropy(&ulidState.entropy) {
+		ulidState.ms = ms
+		_, _ = rand.Read(ulidState.entropy[:])
+	}
+	u[0] = byte(ms >> 40)
+	u[1] = byte(ms >> 32)
+	u[2] = byte(ms >> 24)
+	u[3] = byte(ms >> 16)
+	u[4] = byte(ms >> 8)
+	u[5] = byte(ms)
+	copy(u[6:], ulidState.entropy[:])
+	return
+}
+
+// incEntropy add 1 to entropy, return true when overflow
+func incEntropy(entropy *[ulidEntropyLen]byte) (overflow bool) {
+	for i := len(entropy) - 1; i >= 0; i-- {
+		entropy[i]++
+		if entropy[i] != 0 {
+			return
+		}
+	}
+	overflow = true
+	return
+}
+
+// ParseUlid parse ulid from string, lower case is accepted
+func ParseUlid(s string) (u Ulid, err error) {
+	if len(s) != ulidLen {
+		err = errors.WithStack(ErrUlidInvalidLength)
+		return
+	}
+	var v [ulidLen]byte
+	for i := 0; i < ulidLen; i++ {
+		v[i] = ulidDecoding[s[i]]
+		if v[i] == 0xFF {
+			err = errors.WithStack(ErrUlidInvalidChar)
+			return
+		}
+	}
+	// 26 chars * 5 bits = 130 bits, the first char can only use 3 bits
+	if v[0] > 7 {
+		err = errors.WithStack(ErrUlidOverflow)
+		return
+	}
+	u[0] = v[0]<<5 | v[1]
+	u[1] = v[2]<<3 | v[3]>>2
+	u[2] = v[3]<<6 | v[4]<<1 | v[5]>>4
+	u[3] = v[5]<<4 | v[6]>>1
+	u[4] = v[6]<<7 | v[7]<<2 | v[8]>>3
+	u[5] = v[8]<<5 | v[9]
+	u[6] = v[10]<<3 | v[11]>>2
+	u[7] = v[11]<<6 | v[12]<<1 | v[13]>>4
+	u[8] = v[13]<<4 | v[14]>>1
+	u[9] = v[14]<<7 | v[15]<<2 | v[16]>>3
+	u[10] = v[16]<<5 | v[17]
+	u[11] = v[18]<<3 | v[19]>>2
+	u[12] = v[19]<<6 | v[20]<<1 | v[21]>>4
+	u[13] = v[21]<<4 | v[22]>>1
+	u[14] = v[22]<<7 | v[23]<<2 | v[24]>>3
+	u[15] = v[24]<<5 | v[25]
+	return
+}
+
+// ValidUlid check whether the string is a valid ulid
+func ValidUlid(s string) bool {
+	_, err := ParseUlid(s)
+	return err == nil
+}
+
+func (u Ulid) String() string {
+	b := make([]byte, ulidLen)
+	b[0] = ulidChars[(u[0]&224)>>5]
+	b[1] = ulidChars[u[0]&31]
+	b[2] = ulidChars[(u[1]&248)>>3]
+	b[3] = ulidChars[((u[1]&7)<<2)|((u[2]&192)>>6)]
+	b[4] = ulidChars[(u[2]&62)>>1]
+	b[5] = ulidChars[((u[2]&1)<<4)|((u[3]&240)>>4)]
+	b[6] = ulidChars[((u[3]&15)<<1)|((u[4]&128)>>7)]
+	b[7] = ulidChars[(u[4]&124)>>2]
+	b[8] = ulidChars[((u[4]&3)<<3)|((u[5]&224)>>5)]
+	b[9] = ulidChars[u[5]&31]
+	b[10] = ulidChars[(u[6]&248)>>3]
+	b[11] = ulidChars[((u[6]&7)<<2)|((u[7]&192)>>6)]
+	b[12] = ulidChars[(u[7]&62)>>1]
+	b[13] = ulidChars[((u[7]&1)<<4)|((u[8]&240)>>4)]
+	b[14] = ulidChars[((u[8]&15)<<1)|((u[9]&128)>>7)]
+	b[15] = ulidChars[(u[9]&124)>>2]
+	b[16] = ulidChars[((u[9]&3)<<3)|((u[10]&224)>>5)]
+	b[17] = ulidChars[u[10]&31]
+	b[18] = ulidChars[(u[11]&248)>>3]
+	b[19] = ulidChars[((u[11]&7)<<2)|((u[12]&192)>>6)]
+	b[20] = ulidChars[(u[12]&62)>>1]
+	b[21] = ulidChars[((u[12]&1)<<4)|((u[13]&240)>>4)]
+	b[22] = ulidChars[((u[13]&15)<<1)|((u[14]&128)>>7)]
+	b[23] = ulidChars[(u[14]&124)>>2]
+	b[24] = ulidChars[((u[14]&3)<<3)|((u[15]&224)>>5)]
+	b[25] = ulidChars[u[15]&31]
+	return string(b)
+}
+
+// Time get the timestamp of ulid
+func (u Ulid) Time() time.Time {
+	ms := binary.BigEndian.Uint64(append([]byte{0, 0}, u[:6]...))
+	return time.UnixMilli(int64(ms))
+}
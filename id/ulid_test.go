@@ -0,0 +1,39 @@
+package id
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewUlid(t *testing.T) {
+	prev := ""
+	for i := 0; i < 1000; i++ {
+		item := NewUlid()
+		if item <= prev {
+			t.Fatalf("ulid not sortable: %s <= %s", item, prev)
+		}
+		u, err := ParseUlid(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.String() != item {
+			t.Fatalf("parse mismatch: %s != %s", u.String(), item)
+		}
+		prev = item
+	}
+
+	now := time.UnixMilli(time.Now().UnixMilli())
+	u, err := ParseUlid(NewUlidAt(now).String())
+	if err != nil || !u.Time().Equal(now) {
+		t.Fatalf("ulid time = %v, want %v, err = %v", u.Time(), now, err)
+	}
+
+	for _, s := range []string{"", "01ARZ3NDEKTSV4RRFFQ69G5FA", "01ARZ3NDEKTSV4RRFFQ69G5FAU", "81ARZ3NDEKTSV4RRFFQ69G5FAV"} {
+		if ValidUlid(s) {
+			t.Fatalf("ulid %s should be invalid", s)
+		}
+	}
+	if !ValidUlid("01arz3ndektsv4rrffq69g5fav") {
+		t.Fatal("lower case ulid should be valid")
+	}
+}
@@ -35,19 +35,31 @@ func New(options ...func(*Options)) *Idempotent {
 	return &Idempotent{ops: *ops}
 }
 
+// Namespace get a copy with another namespace, share the same redis client
+func (i *Idempotent) Namespace(namespace string) *Idempotent {
+	ops := i.ops
+	ops.namespace = namespace
+	return &Idempotent{ops: ops}
+}
+
+// Token generate a one-shot token, it can be consumed by Check only once in expire time
 func (i *Idempotent) Token(ctx context.Context) (token string) {
 	token = uuid.NewString()
 	if i.ops.redis != nil {
-		i.ops.redis.Set(ctx, strings.Join([]string{i.ops.prefix, token}, "_"), true, time.Duration(i.ops.expire)*time.Minute)
+		err := i.ops.redis.Set(ctx, i.key(token), true, time.Duration(i.ops.expire)*time.Minute).Err()
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Warn("set idempotent token failed")
+		}
 	} else {
 		log.WithContext(ctx).Warn("please enable redis, otherwise the idempotent is invalid")
 	}
 	return
 }
 
+// Check consume the token atomically, false means the token is invalid, expired or already consumed
 func (i *Idempotent) Check(ctx context.Context, token string) (pass bool) {
 	if i.ops.redis != nil {
-		res, err := i.ops.redis.Eval(ctx, lua, []string{strings.Join([]string{i.ops.prefix, token}, "_")}).Result()
+		res, err := i.ops.redis.Eval(ctx, lua, []string{i.key(token)}).Result()
 		if err != nil || res != "1" {
 			return
 		}
@@ -57,3 +69,26 @@ func (i *Idempotent) Check(ctx context.Context, token string) (pass bool) {
 	pass = true
 	return
 }
+
+// Once mark the external id(e.g. webhook delivery id) as processed, only the first call in expire time will pass
+func (i *Idempotent) Once(ctx context.Context, id string) (pass bool) {
+	if i.ops.redis != nil {
+		ok, err := i.ops.redis.SetNX(ctx, i.key(strings.Join([]string{"once", id}, "_")), true, time.Duration(i.ops.expire)*time.Minute).Result()
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Warn("set idempotent once failed")
+			return
+		}
+		pass = ok
+		return
+	}
+	log.WithContext(ctx).Warn("please enable redis, otherwise the idempotent is invalid")
+	pass = true
+	return
+}
+
+func (i *Idempotent) key(token string) string {
+	if i.ops.namespace == "" {
+		return strings.Join([]string{i.ops.prefix, token}, "_")
+	}
+	return strings.Join([]string{i.ops.prefix, i.ops.namespace, token}, "_")
+}
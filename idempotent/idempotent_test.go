@@ -0,0 +1,61 @@
+package idempotent
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestIdempotent_Check(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	ctx := context.Background()
+	i := New(WithRedis(client), WithNamespace("order"))
+
+	token := i.Token(ctx)
+	if !s.Exists("idempotent_order_" + token) {
+		t.Fatal("token not found")
+	}
+	// other namespace can not consume the token
+	if i.Namespace("user").Check(ctx, token) {
+		t.Fatal("Check() other namespace should fail")
+	}
+
+	var pass int32
+	var wg sync.WaitGroup
+	for j := 0; j < 10; j++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i.Check(ctx, token) {
+				atomic.AddInt32(&pass, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if pass != 1 {
+		t.Fatalf("Check() pass %d times, want 1", pass)
+	}
+	if i.Check(ctx, "invalid") {
+		t.Fatal("Check() invalid token should fail")
+	}
+}
+
+func TestIdempotent_Once(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	ctx := context.Background()
+	i := New(WithRedis(client), WithNamespace("webhook"))
+	if !i.Once(ctx, "delivery1") {
+		t.Fatal("Once() first delivery should pass")
+	}
+	if i.Once(ctx, "delivery1") {
+		t.Fatal("Once() duplicate delivery should fail")
+	}
+	if !i.Once(ctx, "delivery2") {
+		t.Fatal("Once() another delivery should pass")
+	}
+}
@@ -3,9 +3,10 @@ package idempotent
 import "github.com/redis/go-redis/v9"
 
 type Options struct {
-	redis  redis.UniversalClient
-	prefix string
-	expire int
+	redis     redis.UniversalClient
+	prefix    string
+	namespace string
+	expire    int
 }
 
 func WithRedis(rd redis.UniversalClient) func(*Options) {
@@ -24,6 +25,15 @@ func WithPrefix(prefix string) func(*Options) {
 	}
 }
 
+// WithNamespace isolate tokens of different business, e.g. order.create, webhook.pay
+func WithNamespace(namespace string) func(*Options) {
+	return func(options *Options) {
+		if namespace != "" {
+			getOptionsOrSetDefault(options).namespace = namespace
+		}
+	}
+}
+
 func WithExpire(min int) func(*Options) {
 	return func(options *Options) {
 		if min > 0 {
@@ -0,0 +1,16 @@
+package jwt
+
+import (
+	"github.com/go-cinch/common/constant"
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+var (
+	ErrMissingToken           = errors.Unauthorized(constant.JwtMissingToken, "token is missing")
+	ErrTokenInvalid           = errors.Unauthorized(constant.JwtTokenInvalid, "token is invalid")
+	ErrTokenExpired           = errors.Unauthorized(constant.JwtTokenExpired, "token has expired")
+	ErrTokenParseFail         = errors.Unauthorized(constant.JwtTokenParseFail, "fail to parse token")
+	ErrUnSupportSigningMethod = errors.Unauthorized(constant.JwtUnSupportSigningMethod, "wrong signing method")
+	ErrTokenRevoked           = errors.Unauthorized(constant.JwtTokenRevoked, "token has been revoked")
+	ErrSignKeyNil             = errors.InternalServer(constant.InternalError, "sign key is empty")
+)
@@ -26,14 +26,7 @@ type user struct{}
 
 func NewServerContext(ctx context.Context, claims jwtV4.Claims) context.Context {
 	if mClaims, ok := claims.(jwtV4.MapClaims); ok {
-		u := new(User)
-		if v, ok2 := mClaims[ClaimCode].(string); ok2 {
-			u.Code = v
-		}
-		if v, ok3 := mClaims[ClaimPlatform].(string); ok3 {
-			u.Platform = v
-		}
-		ctx = NewServerContextByUser(ctx, *u)
+		ctx = NewServerContextByUser(ctx, claimsToUser(mClaims))
 	}
 	return ctx
 }
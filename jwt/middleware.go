@@ -0,0 +1,28 @@
+package jwt
+
+import (
+	"context"
+	"github.com/go-kratos/kratos/v2/middleware"
+)
+
+// Server validate access token and inject user and claims into ctx, use selector middleware to skip white list
+func (j *Jwt) Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (rp interface{}, err error) {
+			token := TokenFromServerContext(ctx)
+			if token == "" {
+				err = ErrMissingToken
+				return
+			}
+			claims, err := j.Verify(ctx, token)
+			if err != nil {
+				return
+			}
+			u := claimsToUser(claims)
+			u.Token = token
+			ctx = NewServerContextByUser(ctx, u)
+			ctx = NewClaimsContext(ctx, claims)
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package jwt
+
+import (
+	jwtV4 "github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
+)
+
+type key struct {
+	sign   interface{}
+	verify interface{}
+}
+
+type Options struct {
+	method        jwtV4.SigningMethod
+	keys          map[string]key
+	kid           string
+	accessExpire  string
+	refreshExpire string
+	issuer        string
+	redis         redis.UniversalClient
+	prefix        string
+}
+
+// WithSigningMethod HS256/HS512/RS256/EdDSA..., default HS512
+func WithSigningMethod(method jwtV4.SigningMethod) func(*Options) {
+	return func(options *Options) {
+		if method != nil {
+			getOptionsOrSetDefault(options).method = method
+		}
+	}
+}
+
+// WithKey add key by kid, the last key which has sign key is used to sign(if WithKid is not set)
+// HMAC: []byte, []byte
+// RSA: *rsa.PrivateKey, *rsa.PublicKey
+// EdDSA: ed25519.PrivateKey, ed25519.PublicKey
+// old key only need verify key, sign key can be nil
+func WithKey(kid string, sign, verify interface{}) func(*Options) {
+	return func(options *Options) {
+		if kid != "" && verify != nil {
+			ops := getOptionsOrSetDefault(options)
+			ops.keys[kid] = key{
+				sign:   sign,
+				verify: verify,
+			}
+			if sign != nil {
+				ops.kid = kid
+			}
+		}
+	}
+}
+
+// WithKid set current sign key id
+func WithKid(kid string) func(*Options) {
+	return func(options *Options) {
+		if kid != "" {
+			getOptionsOrSetDefault(options).kid = kid
+		}
+	}
+}
+
+func WithAccessExpire(duration string) func(*Options) {
+	return func(options *Options) {
+		if duration != "" {
+			getOptionsOrSetDefault(options).accessExpire = duration
+		}
+	}
+}
+
+func WithRefreshExpire(duration string) func(*Options) {
+	return func(options *Options) {
+		if duration != "" {
+			getOptionsOrSetDefault(options).refreshExpire = duration
+		}
+	}
+}
+
+func WithIssuer(issuer string) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).issuer = issuer
+	}
+}
+
+// WithRedis revoked token store, revoke is invalid if redis is empty
+func WithRedis(rd redis.UniversalClient) func(*Options) {
+	return func(options *Options) {
+		if rd != nil {
+			getOptionsOrSetDefault(options).redis = rd
+		}
+	}
+}
+
+func WithPrefix(prefix string) func(*Options) {
+	return func(options *Options) {
+		if prefix != "" {
+			getOptionsOrSetDefault(options).prefix = prefix
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			method:        jwtV4.SigningMethodHS512,
+			keys:          make(map[string]key),
+			accessExpire:  "2h",
+			refreshExpire: "168h",
+			prefix:        "jwt.revoked",
+		}
+	}
+	return options
+}
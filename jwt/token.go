@@ -0,0 +1,230 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	jwtV4 "github.com/golang-jwt/jwt/v4"
+	"github.com/golang-module/carbon/v2"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+const (
+	ClaimId       = "jti"
+	ClaimType     = "type"
+	ClaimIssuer   = "iss"
+	ClaimIssuedAt = "iat"
+	HeaderKid     = "kid"
+	TypeAccess    = "access"
+	TypeRefresh   = "refresh"
+)
+
+// Pair access token is used to call api, refresh token is only used to get a new Pair
+type Pair struct {
+	AccessToken    string
+	AccessExpires  carbon.Carbon
+	RefreshToken   string
+	RefreshExpires carbon.Carbon
+}
+
+type Jwt struct {
+	ops Options
+}
+
+type claimsCtx struct{}
+
+// New is create a jwt token manager, support multiple keys(rotate by kid) and revoke token by redis
+func New(options ...func(*Options)) *Jwt {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	return &Jwt{ops: *ops}
+}
+
+// Issue create access token and refresh token
+func (j *Jwt) Issue(ctx context.Context, u User) (pair Pair, err error) {
+	pair.AccessToken, pair.AccessExpires, err = j.sign(u, TypeAccess, j.ops.accessExpire)
+	if err != nil {
+		return
+	}
+	pair.RefreshToken, pair.RefreshExpires, err = j.sign(u, TypeRefresh, j.ops.refreshExpire)
+	return
+}
+
+// Verify parse and validate access token
+func (j *Jwt) Verify(ctx context.Context, token string) (claims jwtV4.MapClaims, err error) {
+	return j.Parse(ctx, token, TypeAccess)
+}
+
+// Refresh revoke the old refresh token and issue a new Pair, the refresh token can be used only once
+// even if concurrent refreshes pass Parse
+func (j *Jwt) Refresh(ctx context.Context, refreshToken string) (pair Pair, err error) {
+	var claims jwtV4.MapClaims
+	claims, err = j.Parse(ctx, refreshToken, TypeRefresh)
+	if err != nil {
+		return
+	}
+	var ok bool
+	ok, err = j.revoke(ctx, claims)
+	if err != nil {
+		return
+	}
+	if !ok {
+		err = ErrTokenRevoked
+		return
+	}
+	pair, err = j.Issue(ctx, claimsToUser(claims))
+	return
+}
+
+// Revoke add token to blacklist until it expires
+func (j *Jwt) Revoke(ctx context.Context, token string) (err error) {
+	var claims jwtV4.MapClaims
+	claims, err = j.Parse(ctx, token, "")
+	if errors.Is(err, ErrTokenExpired) || errors.Is(err, ErrTokenRevoked) {
+		// no need to revoke again
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	_, err = j.revoke(ctx, claims)
+	return
+}
+
+// Parse parse and validate token, typ is empty means any type
+func (j *Jwt) Parse(ctx context.Context, token, typ string) (claims jwtV4.MapClaims, err error) {
+	claims = make(jwtV4.MapClaims)
+	_, err = jwtV4.ParseWithClaims(token, claims, j.keyFunc)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUnSupportSigningMethod):
+			err = ErrUnSupportSigningMethod
+		case errors.Is(err, jwtV4.ErrTokenExpired):
+			err = ErrTokenExpired
+		case errors.Is(err, jwtV4.ErrTokenMalformed):
+			err = ErrTokenParseFail
+		default:
+			err = ErrTokenInvalid
+		}
+		return
+	}
+	if j.ops.issuer != "" {
+		if v, _ := claims[ClaimIssuer].(string); v != j.ops.issuer {
+			err = ErrTokenInvalid
+			return
+		}
+	}
+	if typ != "" {
+		v, _ := claims[ClaimType].(string)
+		// compatible with token created by User.CreateToken
+		if v != typ && !(v == "" && typ == TypeAccess) {
+			err = ErrTokenInvalid
+			return
+		}
+	}
+	if j.revoked(ctx, claims) {
+		err = ErrTokenRevoked
+	}
+	return
+}
+
+func (j *Jwt) sign(u User, typ, duration string) (token string, expires carbon.Carbon, err error) {
+	k, ok := j.ops.keys[j.ops.kid]
+	if !ok || k.sign == nil {
+		err = ErrSignKeyNil
+		return
+	}
+	now := carbon.Now()
+	expires = now.AddDuration(duration)
+	claims := jwtV4.MapClaims{
+		ClaimId:       uuid.NewString(),
+		ClaimType:     typ,
+		ClaimCode:     u.Code,
+		ClaimPlatform: u.Platform,
+		ClaimIssuedAt: now.Timestamp(),
+		ClaimExpires:  expires.Timestamp(),
+	}
+	if j.ops.issuer != "" {
+		claims[ClaimIssuer] = j.ops.issuer
+	}
+	t := jwtV4.NewWithClaims(j.ops.method, claims)
+	t.Header[HeaderKid] = j.ops.kid
+	token, err = t.SignedString(k.sign)
+	return
+}
+
+func (j *Jwt) keyFunc(t *jwtV4.Token) (interface{}, error) {
+	if t.Method.Alg() != j.ops.method.Alg() {
+		return nil, ErrUnSupportSigningMethod
+	}
+	kid, _ := t.Header[HeaderKid].(string)
+	if kid == "" {
+		kid = j.ops.kid
+	}
+	k, ok := j.ops.keys[kid]
+	if !ok {
+		return nil, ErrTokenInvalid
+	}
+	return k.verify, nil
+}
+
+// revoke add token id to blacklist by SetNX, ok is false if it is already revoked
+func (j *Jwt) revoke(ctx context.Context, claims jwtV4.MapClaims) (ok bool, err error) {
+	ok = true
+	if j.ops.redis == nil {
+		return
+	}
+	id, _ := claims[ClaimId].(string)
+	if id == "" {
+		return
+	}
+	expire := time.Hour
+	if exp, e := claims[ClaimExpires].(float64); e {
+		expire = time.Until(time.Unix(int64(exp), 0))
+	}
+	if expire <= 0 {
+		return
+	}
+	ok, err = j.ops.redis.SetNX(ctx, strings.Join([]string{j.ops.prefix, id}, "."), 1, expire).Result()
+	return
+}
+
+func (j *Jwt) revoked(ctx context.Context, claims jwtV4.MapClaims) (ok bool) {
+	if j.ops.redis == nil {
+		return
+	}
+	id, _ := claims[ClaimId].(string)
+	if id == "" {
+		return
+	}
+	n, _ := j.ops.redis.Exists(ctx, strings.Join([]string{j.ops.prefix, id}, ".")).Result()
+	ok = n > 0
+	return
+}
+
+func claimsToUser(claims jwtV4.MapClaims) (u User) {
+	if v, ok := claims[ClaimCode].(string); ok {
+		u.Code = v
+	}
+	if v, ok := claims[ClaimPlatform].(string); ok {
+		u.Platform = v
+	}
+	return
+}
+
+func NewClaimsContext(ctx context.Context, claims jwtV4.MapClaims) context.Context {
+	return context.WithValue(ctx, claimsCtx{}, claims)
+}
+
+// ClaimsFromContext get all claims, only set by Jwt.Server middleware
+func ClaimsFromContext(ctx context.Context) (claims jwtV4.MapClaims) {
+	claims = make(jwtV4.MapClaims)
+	if v, ok := ctx.Value(claimsCtx{}).(jwtV4.MapClaims); ok {
+		claims = v
+	}
+	return
+}
@@ -0,0 +1,140 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	jwtV4 "github.com/golang-jwt/jwt/v4"
+	"github.com/redis/go-redis/v9"
+	"sync"
+	"testing"
+)
+
+func TestJwt(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	ctx := context.Background()
+	pub, priv, _ := ed25519.GenerateKey(rand.Reader)
+	tests := []struct {
+		name    string
+		options []func(*Options)
+	}{
+		{
+			name: "HS256",
+			options: []func(*Options){
+				WithSigningMethod(jwtV4.SigningMethodHS256),
+				WithKey("k1", []byte("secret"), []byte("secret")),
+			},
+		},
+		{
+			name: "EdDSA",
+			options: []func(*Options){
+				WithSigningMethod(jwtV4.SigningMethodEdDSA),
+				WithKey("k1", priv, pub),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j := New(append(tt.options, WithRedis(client))...)
+			pair, err := j.Issue(ctx, User{Code: "u1", Platform: "pc"})
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+			claims, err := j.Verify(ctx, pair.AccessToken)
+			if err != nil || claimsToUser(claims).Code != "u1" {
+				t.Fatalf("Verify() claims = %v, error = %v", claims, err)
+			}
+			if _, err = j.Verify(ctx, pair.RefreshToken); !errors.Is(err, ErrTokenInvalid) {
+				t.Fatalf("Verify() refresh token error = %v", err)
+			}
+
+			newPair, err := j.Refresh(ctx, pair.RefreshToken)
+			if err != nil {
+				t.Fatalf("Refresh() error = %v", err)
+			}
+			if _, err = j.Refresh(ctx, pair.RefreshToken); !errors.Is(err, ErrTokenRevoked) {
+				t.Fatalf("Refresh() reuse error = %v", err)
+			}
+
+			if err = j.Revoke(ctx, newPair.AccessToken); err != nil {
+				t.Fatalf("Revoke() error = %v", err)
+			}
+			if _, err = j.Verify(ctx, newPair.AccessToken); !errors.Is(err, ErrTokenRevoked) {
+				t.Fatalf("Verify() revoked error = %v", err)
+			}
+		})
+	}
+}
+
+func TestJwt_Rotate(t *testing.T) {
+	ctx := context.Background()
+	old := New(WithKey("k1", []byte("secret1"), []byte("secret1")))
+	pair, _ := old.Issue(ctx, User{Code: "u1"})
+
+	// k2 is used to sign, k1 only verify
+	j := New(
+		WithKey("k1", nil, []byte("secret1")),
+		WithKey("k2", []byte("secret2"), []byte("secret2")),
+	)
+	if _, err := j.Verify(ctx, pair.AccessToken); err != nil {
+		t.Fatalf("Verify() old key error = %v", err)
+	}
+	newPair, _ := j.Issue(ctx, User{Code: "u1"})
+	if _, err := old.Verify(ctx, newPair.AccessToken); !errors.Is(err, ErrTokenInvalid) {
+		t.Fatalf("Verify() unknown kid error = %v", err)
+	}
+
+	legacy, _ := (&User{Code: "u1"}).CreateToken("secret1", "1h")
+	if _, err := old.Verify(ctx, legacy); err != nil {
+		t.Fatalf("Verify() legacy token error = %v", err)
+	}
+	if _, err := New(WithSigningMethod(jwtV4.SigningMethodHS256), WithKey("k1", []byte("secret1"), []byte("secret1"))).Verify(ctx, legacy); !errors.Is(err, ErrUnSupportSigningMethod) {
+		t.Fatalf("Verify() wrong method error = %v", err)
+	}
+}
+
+func TestJwt_RefreshConcurrent(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	ctx := context.Background()
+	j := New(WithKey("k1", []byte("secret"), []byte("secret")), WithRedis(client))
+	pair, _ := j.Issue(ctx, User{Code: "u1"})
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+	var success int
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := j.Refresh(ctx, pair.RefreshToken)
+			if err == nil {
+				lock.Lock()
+				success++
+				lock.Unlock()
+			} else if !errors.Is(err, ErrTokenRevoked) {
+				t.Errorf("Refresh() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if success != 1 {
+		t.Fatalf("refresh token is used %d times", success)
+	}
+}
+
+func TestJwt_Issuer(t *testing.T) {
+	ctx := context.Background()
+	other := New(WithKey("k1", []byte("secret"), []byte("secret")), WithIssuer("other"))
+	pair, _ := other.Issue(ctx, User{Code: "u1"})
+	j := New(WithKey("k1", []byte("secret"), []byte("secret")), WithIssuer("auth"))
+	if _, err := j.Verify(ctx, pair.AccessToken); !errors.Is(err, ErrTokenInvalid) {
+		t.Fatalf("Verify() other issuer error = %v", err)
+	}
+	pair, _ = j.Issue(ctx, User{Code: "u1"})
+	if _, err := j.Verify(ctx, pair.AccessToken); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+}
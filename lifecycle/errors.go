@@ -0,0 +1,13 @@
+package lifecycle
+
+import "github.com/pkg/errors"
+
+var (
+	ErrNameNil       = errors.New("hook name is empty")
+	ErrNameDuplicate = errors.New("hook name is duplicate")
+	ErrDependency    = errors.New("hook dependency not found")
+	ErrCycle         = errors.New("hook dependencies have cycle")
+	ErrStarted       = errors.New("lifecycle is already started")
+	ErrTimeout       = errors.New("component timeout")
+	ErrStraggler     = errors.New("components did not stop in time")
+)
@@ -0,0 +1,12 @@
+module github.com/go-cinch/common/lifecycle
+
+go 1.20
+
+replace github.com/go-cinch/common/log => ../log
+
+require (
+	github.com/go-cinch/common/log v1.0.4
+	github.com/pkg/errors v0.9.1
+)
+
+require github.com/go-kratos/kratos/v2 v2.7.0 // indirect
@@ -0,0 +1,269 @@
+package lifecycle
+
+import (
+	"context"
+	"github.com/go-cinch/common/log"
+	"github.com/pkg/errors"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Hook is one component of service, Start should not block(run loops in background),
+// Stop should return after resources released, both of them are optional
+type Hook struct {
+	Name string
+	// DependsOn names of hooks which start before and stop after this one
+	DependsOn []string
+	Start     func(ctx context.Context) error
+	Stop      func(ctx context.Context) error
+	// Timeout of Start/Stop, default by WithTimeout
+	Timeout time.Duration
+}
+
+// Lifecycle start hooks in dependency order and stop them in reverse order
+type Lifecycle struct {
+	ops     Options
+	lock    sync.Mutex
+	hooks   []Hook
+	started []Hook
+	running bool
+}
+
+func New(options ...func(*Options)) (l *Lifecycle) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	l = &Lifecycle{
+		ops: *ops,
+	}
+	return
+}
+
+// Append register hooks, dependencies can be appended later, but must be appended before Start
+func (l *Lifecycle) Append(hooks ...Hook) (err error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.running {
+		err = ErrStarted
+		return
+	}
+	for _, item := range hooks {
+		if item.Name == "" {
+			err = ErrNameNil
+			return
+		}
+		for _, h := range l.hooks {
+			if h.Name == item.Name {
+				err = errors.Wrap(ErrNameDuplicate, item.Name)
+				return
+			}
+		}
+		l.hooks = append(l.hooks, item)
+	}
+	return
+}
+
+// Start call Start of hooks in dependency order, the started hooks will be stopped if one of them failed
+func (l *Lifecycle) Start(ctx context.Context) (err error) {
+	l.lock.Lock()
+	if l.running {
+		l.lock.Unlock()
+		err = ErrStarted
+		return
+	}
+	hooks, err := sort(l.hooks)
+	if err != nil {
+		l.lock.Unlock()
+		return
+	}
+	l.running = true
+	l.started = make([]Hook, 0, len(hooks))
+	l.lock.Unlock()
+	for _, item := range hooks {
+		// ctx canceled(e.g. signal received) during startup, do not start the remaining
+		err = ctx.Err()
+		if err == nil && item.Start != nil {
+			err = l.call(ctx, item, item.Start)
+		}
+		if err != nil {
+			err = errors.WithMessagef(err, "start %s failed", item.Name)
+			log.WithContext(ctx).WithError(err).Error("start component failed")
+			// ctx may be canceled, stop with a new one
+			_ = l.Stop(context.Background())
+			return
+		}
+		l.lock.Lock()
+		l.started = append(l.started, item)
+		l.lock.Unlock()
+		log.WithContext(ctx).WithFields(log.Fields{
+			"name": item.Name,
+		}).Debug("component started")
+	}
+	return
+}
+
+// Stop call Stop of started hooks in reverse order, each hook has its own timeout and all of them share the shutdown timeout,
+// hooks not stopped in time are stragglers, they are skipped(still running in background) and reported by ErrStraggler
+func (l *Lifecycle) Stop(ctx context.Context) (err error) {
+	l.lock.Lock()
+	started := l.started
+	l.started = nil
+	l.running = false
+	l.lock.Unlock()
+	ctx, cancel := context.WithTimeout(ctx, l.ops.shutdownTimeout)
+	defer cancel()
+	stragglers := make([]string, 0)
+	for i := len(started) - 1; i >= 0; i-- {
+		item := started[i]
+		if item.Stop == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			// shutdown timeout, skip the remaining
+			stragglers = append(stragglers, item.Name)
+			log.WithContext(ctx).WithFields(log.Fields{
+				"name": item.Name,
+			}).Warn("component is skipped because of shutdown timeout")
+			continue
+		}
+		start := time.Now()
+		e := l.call(ctx, item, item.Stop)
+		fields := log.Fields{
+			"name":    item.Name,
+			"latency": time.Since(start).String(),
+		}
+		switch {
+		case errors.Is(e, ErrTimeout):
+			stragglers = append(stragglers, item.Name)
+			log.WithContext(ctx).WithFields(fields).Warn("component did not stop in time")
+		case e != nil:
+			if err == nil {
+				err = errors.WithMessagef(e, "stop %s failed", item.Name)
+			}
+			log.WithContext(ctx).WithError(e).WithFields(fields).Warn("stop component failed")
+		default:
+			log.WithContext(ctx).WithFields(fields).Debug("component stopped")
+		}
+	}
+	if len(stragglers) > 0 {
+		err = errors.Wrap(ErrStraggler, strings.Join(stragglers, ","))
+	}
+	return
+}
+
+// Run start hooks and block until ctx done or signal received, then stop hooks,
+// signals are listened before start, so a signal received during startup also stops the started hooks
+func (l *Lifecycle) Run(ctx context.Context) (err error) {
+	sig, stop := signal.NotifyContext(ctx, l.ops.signals...)
+	defer stop()
+	err = l.Start(sig)
+	if err != nil {
+		if ctx.Err() == nil && errors.Is(err, context.Canceled) {
+			// signal received during startup, started hooks are already stopped
+			log.WithContext(ctx).Info("shutting down")
+			err = nil
+		}
+		return
+	}
+	<-sig.Done()
+	stop()
+	log.WithContext(ctx).Info("shutting down")
+	// ctx may be canceled, stop with a new one
+	err = l.Stop(context.Background())
+	return
+}
+
+// call f with hook timeout, return ErrTimeout if timeout
+func (l *Lifecycle) call(ctx context.Context, h Hook, f func(ctx context.Context) error) (err error) {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = l.ops.timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- errors.Errorf("panic: %v", r)
+			}
+		}()
+		done <- f(ctx)
+	}()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// f may return at the same time, prefer its result
+		select {
+		case err = <-done:
+		default:
+			err = ctx.Err()
+		}
+	}
+	// canceled by parent is not timeout
+	if err != nil && errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = errors.Wrap(ErrTimeout, h.Name)
+	}
+	return
+}
+
+// sort hooks by dependencies, keep append order if no dependency between them
+func sort(hooks []Hook) (rp []Hook, err error) {
+	names := make(map[string]struct{}, len(hooks))
+	for _, item := range hooks {
+		names[item.Name] = struct{}{}
+	}
+	for _, item := range hooks {
+		for _, dep := range item.DependsOn {
+			if _, ok := names[dep]; !ok {
+				err = errors.Wrapf(ErrDependency, "%s depends on %s", item.Name, dep)
+				return
+			}
+		}
+	}
+	placed := make(map[string]struct{}, len(hooks))
+	rp = make([]Hook, 0, len(hooks))
+	for len(rp) < len(hooks) {
+		progress := false
+		for _, item := range hooks {
+			if _, ok := placed[item.Name]; ok {
+				continue
+			}
+			ready := true
+			for _, dep := range item.DependsOn {
+				if _, ok := placed[dep]; !ok {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				placed[item.Name] = struct{}{}
+				rp = append(rp, item)
+				progress = true
+			}
+		}
+		if !progress {
+			err = ErrCycle
+			return
+		}
+	}
+	return
+}
+
+// Func adapt stop func without ctx and error, e.g. outbox.Stop, eventbus.Close, worker.Stop
+func Func(f func()) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		f()
+		return nil
+	}
+}
+
+// Closer adapt close func without ctx, e.g. cache.Close, ws.Close
+func Closer(f func() error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return f()
+	}
+}
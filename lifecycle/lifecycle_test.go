@@ -0,0 +1,233 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type recorder struct {
+	lock sync.Mutex
+	list []string
+}
+
+func (r *recorder) hook(name string, deps ...string) Hook {
+	return Hook{
+		Name:      name,
+		DependsOn: deps,
+		Start: func(ctx context.Context) error {
+			r.add("start " + name)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			r.add("stop " + name)
+			return nil
+		},
+	}
+}
+
+func (r *recorder) add(s string) {
+	r.lock.Lock()
+	r.list = append(r.list, s)
+	r.lock.Unlock()
+}
+
+func (r *recorder) String() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return strings.Join(r.list, ",")
+}
+
+func TestOrder(t *testing.T) {
+	r := &recorder{}
+	l := New()
+	err := l.Append(
+		r.hook("worker", "cache"),
+		r.hook("outbox", "worker"),
+		r.hook("log"),
+		r.hook("cache", "log"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = l.Append(r.hook("log")); !errors.Is(err, ErrNameDuplicate) {
+		t.Fatalf("expect duplicate but got %v", err)
+	}
+	ctx := context.Background()
+	if err = l.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err = l.Start(ctx); err != ErrStarted {
+		t.Fatalf("expect started but got %v", err)
+	}
+	if err = l.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	expect := "start log,start cache,start worker,start outbox,stop outbox,stop worker,stop cache,stop log"
+	if r.String() != expect {
+		t.Fatalf("unexpected order %s", r)
+	}
+
+	l = New()
+	_ = l.Append(r.hook("a", "b"), r.hook("b", "a"))
+	if err = l.Start(ctx); err != ErrCycle {
+		t.Fatalf("expect cycle but got %v", err)
+	}
+	l = New()
+	_ = l.Append(r.hook("a", "x"))
+	if err = l.Start(ctx); !errors.Is(err, ErrDependency) {
+		t.Fatalf("expect dependency not found but got %v", err)
+	}
+}
+
+func TestStartFailed(t *testing.T) {
+	r := &recorder{}
+	l := New()
+	failed := r.hook("ws")
+	failed.Start = func(ctx context.Context) error {
+		return errors.New("port in use")
+	}
+	_ = l.Append(r.hook("cache"), failed, r.hook("outbox"))
+	err := l.Start(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "start ws failed: port in use") {
+		t.Fatalf("expect start failed but got %v", err)
+	}
+	// started hooks are stopped
+	if r.String() != "start cache,stop cache" {
+		t.Fatalf("unexpected order %s", r)
+	}
+}
+
+func TestStraggler(t *testing.T) {
+	r := &recorder{}
+	l := New(WithTimeout(20 * time.Millisecond))
+	slow := r.hook("slow")
+	slow.Stop = Func(func() {
+		time.Sleep(time.Second)
+	})
+	ctxAware := r.hook("aware")
+	ctxAware.Stop = func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	failed := r.hook("failed")
+	failed.Stop = Closer(func() error {
+		return errors.New("flush failed")
+	})
+	_ = l.Append(r.hook("log"), failed, slow, ctxAware)
+	ctx := context.Background()
+	_ = l.Start(ctx)
+	start := time.Now()
+	err := l.Stop(ctx)
+	if !errors.Is(err, ErrStraggler) || !strings.Contains(err.Error(), "aware,slow") {
+		t.Fatalf("expect stragglers but got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatal("stop should not wait stragglers")
+	}
+	// the others are stopped
+	if !strings.HasSuffix(r.String(), "stop log") {
+		t.Fatalf("unexpected order %s", r)
+	}
+
+	// shutdown timeout
+	l = New(WithTimeout(time.Second), WithShutdownTimeout(30*time.Millisecond))
+	_ = l.Append(r.hook("cache"), slow)
+	_ = l.Start(ctx)
+	err = l.Stop(ctx)
+	if !errors.Is(err, ErrStraggler) || !strings.Contains(err.Error(), "cache") {
+		t.Fatalf("expect stragglers but got %v", err)
+	}
+}
+
+func TestRun(t *testing.T) {
+	r := &recorder{}
+	l := New(WithSignals(syscall.SIGUSR1))
+	_ = l.Append(r.hook("cache"))
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Run(context.Background())
+	}()
+	time.Sleep(50 * time.Millisecond)
+	_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("run should return after signal")
+	}
+	if r.String() != "start cache,stop cache" {
+		t.Fatalf("unexpected order %s", r)
+	}
+
+	// ctx done
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := l.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if r.String() != "start cache,stop cache,start cache,stop cache" {
+		t.Fatalf("unexpected order %s", r)
+	}
+}
+
+func TestStartCanceled(t *testing.T) {
+	r := &recorder{}
+	l := New()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := r.hook("db")
+	db.Start = func(ctx context.Context) error {
+		r.add("start db")
+		cancel()
+		return nil
+	}
+	_ = l.Append(db, r.hook("server", "db"))
+	err := l.Start(ctx)
+	if !errors.Is(err, context.Canceled) || errors.Is(err, ErrTimeout) {
+		t.Fatalf("expect canceled but got %v", err)
+	}
+	// started hooks are stopped even if ctx is canceled
+	if r.String() != "start db,stop db" {
+		t.Fatalf("unexpected order %s", r)
+	}
+}
+
+func TestRunSignalDuringStart(t *testing.T) {
+	r := &recorder{}
+	l := New(WithSignals(syscall.SIGUSR1))
+	_ = l.Append(r.hook("db"), Hook{
+		Name:      "server",
+		DependsOn: []string{"db"},
+		Start: func(ctx context.Context) error {
+			r.add("start server")
+			_ = syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			r.add("stop server")
+			return nil
+		},
+	})
+	done := make(chan error, 1)
+	go func() {
+		done <- l.Run(context.Background())
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("run should return after signal during start")
+	}
+	if r.String() != "start db,start server,stop server,stop db" {
+		t.Fatalf("unexpected order %s", r)
+	}
+}
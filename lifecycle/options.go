@@ -0,0 +1,51 @@
+package lifecycle
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+type Options struct {
+	timeout         time.Duration
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+}
+
+// WithTimeout default start/stop timeout of each component, Hook.Timeout has higher priority
+func WithTimeout(timeout time.Duration) func(*Options) {
+	return func(options *Options) {
+		if timeout > 0 {
+			getOptionsOrSetDefault(options).timeout = timeout
+		}
+	}
+}
+
+// WithShutdownTimeout max duration of stopping all components, components not stopped are stragglers
+func WithShutdownTimeout(timeout time.Duration) func(*Options) {
+	return func(options *Options) {
+		if timeout > 0 {
+			getOptionsOrSetDefault(options).shutdownTimeout = timeout
+		}
+	}
+}
+
+// WithSignals signals to trigger shutdown in Run
+func WithSignals(signals ...os.Signal) func(*Options) {
+	return func(options *Options) {
+		if len(signals) > 0 {
+			getOptionsOrSetDefault(options).signals = signals
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			timeout:         10 * time.Second,
+			shutdownTimeout: 30 * time.Second,
+			signals:         []os.Signal{syscall.SIGTERM, syscall.SIGINT},
+		}
+	}
+	return options
+}
@@ -58,6 +58,9 @@ func newKratosLog(ops *Options) *kratosLog {
 			Caller(*ops),
 		)
 	}
+	if len(ops.valuers) > 0 {
+		ops.logger = log.With(ops.logger, ops.valuers...)
+	}
 	// override default kratos log
 	log.SetLogger(ops.logger)
 	helper := log.NewHelper(ops.logger)
@@ -12,6 +12,7 @@ type Options struct {
 	callerPrefix     string
 	callerLevel      int
 	callerVersion    bool
+	valuers          []interface{}
 }
 
 func (o Options) Level() Level {
@@ -40,6 +41,15 @@ func WithLoggerMessageKey(key string) func(*Options) {
 	}
 }
 
+// WithValuer add kratos log.Valuer, the value will be got from ctx which is passed by WithContext
+func WithValuer(key string, v log.Valuer) func(*Options) {
+	return func(options *Options) {
+		if key != "" && v != nil {
+			getOptionsOrSetDefault(options).valuers = append(getOptionsOrSetDefault(options).valuers, key, v)
+		}
+	}
+}
+
 func getOptionsOrSetDefault(options *Options) *Options {
 	if options == nil {
 		return &Options{
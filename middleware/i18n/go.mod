@@ -2,10 +2,14 @@ module github.com/go-cinch/common/middleware/i18n
 
 go 1.20
 
-replace github.com/go-cinch/common/i18n => ../../i18n
+replace (
+	github.com/go-cinch/common/i18n => ../../i18n
+	github.com/go-cinch/common/middleware/locale => ../locale
+)
 
 require (
 	github.com/go-cinch/common/i18n v1.0.6
+	github.com/go-cinch/common/middleware/locale v1.0.0
 	github.com/go-kratos/kratos/v2 v2.7.0
 	golang.org/x/text v0.11.0
 	google.golang.org/grpc v1.56.1
@@ -13,9 +17,11 @@ require (
 
 require (
 	github.com/BurntSushi/toml v1.3.2 // indirect
+	github.com/go-kratos/aegis v0.2.0 // indirect
 	github.com/go-playground/form/v4 v4.2.1 // indirect
 	github.com/golang/protobuf v1.5.3 // indirect
-	github.com/kr/text v0.2.0 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/gorilla/mux v1.8.0 // indirect
 	github.com/nicksnyder/go-i18n/v2 v2.2.1 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20230629202037-9506855d4529 // indirect
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-cinch/common/i18n"
+	"github.com/go-cinch/common/middleware/locale"
 	"github.com/go-kratos/kratos/v2/errors"
 	"github.com/go-kratos/kratos/v2/middleware"
 	"github.com/go-kratos/kratos/v2/transport"
@@ -20,10 +21,11 @@ func Translator(options ...func(*i18n.Options)) middleware.Middleware {
 	i = i18n.New(options...)
 	return func(handler middleware.Handler) middleware.Handler {
 		return func(ctx context.Context, req interface{}) (rp interface{}, err error) {
-			var lang language.Tag
 			header := make(metadata.MD)
 			key := "accept-language"
-			if tr, ok := transport.FromServerContext(ctx); ok {
+			// use the language normalized by locale middleware first
+			lang := locale.FromContext(ctx)
+			if tr, ok := transport.FromServerContext(ctx); ok && lang == language.Und {
 				accept := tr.RequestHeader().Get(key)
 				lang = language.Make(accept)
 			}
@@ -31,7 +33,9 @@ func Translator(options ...func(*i18n.Options)) middleware.Middleware {
 			header.Set(key, ii.Language().String())
 			ctx = metadata.NewOutgoingContext(ctx, header)
 			ctx = NewContext(ctx, ii)
-			return handler(ctx, req)
+			rp, err = handler(ctx, req)
+			err = TranslateError(ctx, err)
+			return
 		}
 	}
 }
@@ -41,14 +45,40 @@ func NewContext(ctx context.Context, i *i18n.I18n) context.Context {
 	return ctx
 }
 
+// FromContext get translator from context, the language of locale middleware will be selected if translator not found(e.g. worker task)
 func FromContext(ctx context.Context) (rp *i18n.I18n) {
 	rp = i
 	if v, ok := ctx.Value(translator{}).(*i18n.I18n); ok {
 		rp = v
+		return
+	}
+	if lang := locale.FromContext(ctx); lang != language.Und {
+		rp = i.Select(lang)
 	}
 	return
 }
 
+// TranslateError translate kratos error message by reason, metadata will be used as template params,
+// the error will not be changed if reason has no translation
+func TranslateError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	e := errors.FromError(err)
+	if e.Reason == "" {
+		return err
+	}
+	ii := FromContext(ctx)
+	if !ii.Exists(e.Reason) {
+		return err
+	}
+	var data map[string]string
+	if len(e.Metadata) > 0 {
+		data = e.Metadata
+	}
+	return errors.New(int(e.Code), e.Reason, ii.Template(e.Reason, data)).WithMetadata(e.Metadata).WithCause(e.Unwrap())
+}
+
 func NewError(ctx context.Context, text string, f func(string, ...interface{}) *errors.Error, args ...string) error {
 	text = FromContext(ctx).T(text)
 	if len(args) == 0 {
@@ -0,0 +1,30 @@
+package i18n
+
+import (
+	"context"
+	"github.com/go-cinch/common/i18n"
+	"github.com/go-cinch/common/middleware/locale"
+	"github.com/go-kratos/kratos/v2/errors"
+	"golang.org/x/text/language"
+	"testing"
+)
+
+func TestTranslateError(t *testing.T) {
+	Translator(i18n.WithFile("../../i18n/locales"))
+	ctx := locale.NewContext(context.Background(), language.Chinese)
+
+	err := TranslateError(ctx, errors.BadRequest("common.welcome", "welcome").WithMetadata(map[string]string{"Name": "cinch"}))
+	e := errors.FromError(err)
+	if e.Message != "欢迎, cinch!" || e.Code != 400 || e.Metadata["Name"] != "cinch" {
+		t.Fatalf("TranslateError() = %v", e)
+	}
+
+	err = TranslateError(ctx, errors.BadRequest("common.unknown", "unknown"))
+	if errors.FromError(err).Message != "unknown" {
+		t.Fatalf("TranslateError() = %v", err)
+	}
+
+	if TranslateError(ctx, nil) != nil {
+		t.Fatal("TranslateError(nil) should be nil")
+	}
+}
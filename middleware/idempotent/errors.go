@@ -0,0 +1,8 @@
+package idempotent
+
+import "github.com/go-kratos/kratos/v2/errors"
+
+var (
+	ErrKeyMissing = errors.BadRequest("idempotent.key.missing", "idempotency key is missing")
+	ErrProcessing = errors.Conflict("idempotent.processing", "request is processing, please retry later")
+)
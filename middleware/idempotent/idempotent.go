@@ -0,0 +1,67 @@
+package idempotent
+
+import (
+	"context"
+	"github.com/go-cinch/common/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"strings"
+)
+
+const ReplayedHeader = "Idempotent-Replayed"
+
+// Idempotent reserve the idempotency key before handler, duplicate requests will get the cached reply
+func Idempotent(options ...func(*Options)) middleware.Middleware {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (rp interface{}, err error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			key := tr.RequestHeader().Get(ops.header)
+			if key == "" {
+				if ops.required {
+					err = ErrKeyMissing
+					return
+				}
+				return handler(ctx, req)
+			}
+			if ops.redis == nil {
+				log.WithContext(ctx).Warn("please enable redis, otherwise the idempotent is invalid")
+				return handler(ctx, req)
+			}
+			// different operation or user can use the same key
+			t := newToken(*ops, strings.Join([]string{tr.Operation(), ops.keyFunc(ctx), key}, "."))
+			if v, replayed := t.Replay(ctx); replayed {
+				tr.ReplyHeader().Set(ReplayedHeader, "true")
+				rp = v
+				return
+			}
+			if !t.Reserve(ctx) {
+				err = ErrProcessing
+				return
+			}
+			// the previous holder may finish between Replay and Reserve
+			if v, replayed := t.Replay(ctx); replayed {
+				t.Release(ctx)
+				tr.ReplyHeader().Set(ReplayedHeader, "true")
+				rp = v
+				return
+			}
+			rp, err = handler(ctx, req)
+			if err != nil {
+				// failed request can be retried with the same key
+				t.Release(ctx)
+				return
+			}
+			if e := t.Done(ctx, rp); e != nil {
+				log.WithContext(ctx).WithError(e).Warn("cache idempotent reply failed")
+			}
+			return
+		}
+	}
+}
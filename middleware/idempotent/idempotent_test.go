@@ -0,0 +1,150 @@
+package idempotent
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-cinch/common/jwt"
+	"github.com/go-cinch/common/tenant"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+
+type testTransport struct {
+	request headerCarrier
+	reply   headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "/test.v1.Test/Create" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.request }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.reply }
+
+func newTestCtx(key string) (context.Context, *testTransport) {
+	tr := &testTransport{
+		request: headerCarrier{},
+		reply:   headerCarrier{},
+	}
+	if key != "" {
+		tr.request.Set("Idempotency-Key", key)
+	}
+	return transport.NewServerContext(context.Background(), tr), tr
+}
+
+func TestIdempotent(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+
+	var count int
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		count++
+		return wrapperspb.String("ok"), nil
+	}
+	h := Idempotent(WithRedis(client))(handler)
+
+	ctx, _ := newTestCtx("key1")
+	rp, err := h(ctx, nil)
+	if err != nil || count != 1 {
+		t.Fatalf("first request: err = %v, count = %d", err, count)
+	}
+
+	ctx, tr := newTestCtx("key1")
+	rp, err = h(ctx, nil)
+	if err != nil || count != 1 {
+		t.Fatalf("duplicate request: err = %v, count = %d", err, count)
+	}
+	if v, ok := rp.(*wrapperspb.StringValue); !ok || v.Value != "ok" {
+		t.Fatalf("duplicate request: reply = %v", rp)
+	}
+	if tr.reply.Get(ReplayedHeader) != "true" {
+		t.Fatalf("duplicate request: missing %s header", ReplayedHeader)
+	}
+
+	// the same key of other user is not replayed
+	ctx, tr = newTestCtx("key1")
+	_, err = h(jwt.NewServerContextByUser(ctx, jwt.User{Code: "u2"}), nil)
+	if err != nil || count != 2 || tr.reply.Get(ReplayedHeader) != "" {
+		t.Fatalf("other user request: err = %v, count = %d", err, count)
+	}
+	ctx, tr = newTestCtx("key1")
+	_, err = h(tenant.SetTenant(ctx, "t2"), nil)
+	if err != nil || count != 3 || tr.reply.Get(ReplayedHeader) != "" {
+		t.Fatalf("other tenant request: err = %v, count = %d", err, count)
+	}
+
+	ctx, _ = newTestCtx("")
+	_, err = h(ctx, nil)
+	if err != nil || count != 4 {
+		t.Fatalf("request without key: err = %v, count = %d", err, count)
+	}
+
+	ctx, _ = newTestCtx("")
+	_, err = Idempotent(WithRedis(client), WithRequired(true))(handler)(ctx, nil)
+	if err != ErrKeyMissing {
+		t.Fatalf("required key: err = %v", err)
+	}
+}
+
+func TestToken(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	ctx := context.Background()
+
+	t1 := NewToken("task1", WithRedis(client))
+	if !t1.Reserve(ctx) {
+		t.Fatal("first reserve failed")
+	}
+	t2 := NewToken("task1", WithRedis(client))
+	if t2.Reserve(ctx) {
+		t.Fatal("second reserve should fail while processing")
+	}
+	if err := t1.Done(ctx, map[string]string{"status": "done"}); err != nil {
+		t.Fatal(err)
+	}
+	rp, ok := t2.Replay(ctx)
+	if !ok || string(rp.(json.RawMessage)) != `{"status":"done"}` {
+		t.Fatalf("replay = %v, %v", rp, ok)
+	}
+
+	// slow holder must not release the reservation of others after its lock expired
+	t3 := NewToken("task2", WithRedis(client), WithLockExpire(1))
+	if !t3.Reserve(ctx) {
+		t.Fatal("first reserve failed")
+	}
+	s.FastForward(2 * time.Second)
+	t4 := NewToken("task2", WithRedis(client), WithLockExpire(1))
+	if !t4.Reserve(ctx) {
+		t.Fatal("reserve after expired failed")
+	}
+	t3.Release(ctx)
+	if NewToken("task2", WithRedis(client)).Reserve(ctx) {
+		t.Fatal("reserve should fail while t4 is processing")
+	}
+	t4.Release(ctx)
+	if !NewToken("task2", WithRedis(client)).Reserve(ctx) {
+		t.Fatal("reserve after release failed")
+	}
+}
@@ -0,0 +1,93 @@
+package idempotent
+
+import (
+	"context"
+	"github.com/go-cinch/common/jwt"
+	"github.com/go-cinch/common/tenant"
+	"github.com/redis/go-redis/v9"
+	"strings"
+)
+
+type Options struct {
+	redis      redis.UniversalClient
+	header     string
+	prefix     string
+	expire     int
+	lockExpire int
+	required   bool
+	keyFunc    func(ctx context.Context) string
+}
+
+func WithRedis(rd redis.UniversalClient) func(*Options) {
+	return func(options *Options) {
+		if rd != nil {
+			getOptionsOrSetDefault(options).redis = rd
+		}
+	}
+}
+
+func WithHeader(header string) func(*Options) {
+	return func(options *Options) {
+		if header != "" {
+			getOptionsOrSetDefault(options).header = header
+		}
+	}
+}
+
+func WithPrefix(prefix string) func(*Options) {
+	return func(options *Options) {
+		if prefix != "" {
+			getOptionsOrSetDefault(options).prefix = prefix
+		}
+	}
+}
+
+func WithExpire(min int) func(*Options) {
+	return func(options *Options) {
+		if min > 0 {
+			getOptionsOrSetDefault(options).expire = min
+		}
+	}
+}
+
+func WithLockExpire(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).lockExpire = second
+		}
+	}
+}
+
+func WithRequired(flag bool) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).required = flag
+	}
+}
+
+// WithKeyFunc scope of idempotency key, default is tenant id and jwt user code,
+// so that the reply can not be replayed by others with the same key
+func WithKeyFunc(f func(ctx context.Context) string) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).keyFunc = f
+		}
+	}
+}
+
+// ByUser scope idempotency key by tenant id and jwt user code
+func ByUser(ctx context.Context) string {
+	return strings.Join([]string{tenant.FromContext(ctx), jwt.FromServerContext(ctx).Code}, ".")
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			header:     "Idempotency-Key",
+			prefix:     "idempotent.middleware",
+			expire:     60,
+			lockExpire: 60,
+			keyFunc:    ByUser,
+		}
+	}
+	return options
+}
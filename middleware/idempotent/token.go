@@ -0,0 +1,142 @@
+package idempotent
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/go-cinch/common/log"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"strings"
+	"time"
+)
+
+// release the lock only if it is still owned by current token
+const luaRelease = `
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('del', KEYS[1])
+end
+return 0
+`
+
+// Token reserve a key and cache the reply, can be used without middleware(worker task, mq consumer...)
+type Token struct {
+	ops     Options
+	key     string
+	lockKey string
+	owner   string // random value of lock key, the lock expired and reserved by others will not be released
+}
+
+type cache struct {
+	Type string          `json:"type,omitempty"` // proto message full name, empty means plain json
+	Data json.RawMessage `json:"data"`
+}
+
+func NewToken(key string, options ...func(*Options)) *Token {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	return newToken(*ops, key)
+}
+
+func newToken(ops Options, key string) *Token {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return &Token{
+		ops:     ops,
+		key:     strings.Join([]string{ops.prefix, key}, "."),
+		lockKey: strings.Join([]string{ops.prefix, "lock", key}, "."),
+		owner:   hex.EncodeToString(b),
+	}
+}
+
+// Reserve try to reserve the key, false means the key is processing by others
+func (t *Token) Reserve(ctx context.Context) (ok bool) {
+	if t.ops.redis == nil {
+		log.WithContext(ctx).Warn("please enable redis, otherwise the idempotent is invalid")
+		ok = true
+		return
+	}
+	ok, err := t.ops.redis.SetNX(ctx, t.lockKey, t.owner, time.Duration(t.ops.lockExpire)*time.Second).Result()
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("reserve idempotent key failed")
+	}
+	return
+}
+
+// Release give up the reservation without caching reply, the same key can be processed again,
+// the reservation of others(after lock expired) is kept
+func (t *Token) Release(ctx context.Context) {
+	if t.ops.redis == nil {
+		return
+	}
+	err := t.ops.redis.Eval(ctx, luaRelease, []string{t.lockKey}, t.owner).Err()
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("release idempotent key failed")
+	}
+}
+
+// Done cache the reply in expire time and release the reservation
+func (t *Token) Done(ctx context.Context, reply interface{}) (err error) {
+	defer t.Release(ctx)
+	if t.ops.redis == nil {
+		return
+	}
+	var c cache
+	if m, ok := reply.(proto.Message); ok {
+		c.Type = string(m.ProtoReflect().Descriptor().FullName())
+		c.Data, err = protojson.Marshal(m)
+	} else {
+		c.Data, err = json.Marshal(reply)
+	}
+	if err != nil {
+		return
+	}
+	var bs []byte
+	bs, err = json.Marshal(c)
+	if err != nil {
+		return
+	}
+	err = t.ops.redis.Set(ctx, t.key, bs, time.Duration(t.ops.expire)*time.Minute).Err()
+	return
+}
+
+// Replay get the cached reply, proto message will be restored to the origin type
+func (t *Token) Replay(ctx context.Context) (reply interface{}, ok bool) {
+	if t.ops.redis == nil {
+		return
+	}
+	bs, err := t.ops.redis.Get(ctx, t.key).Bytes()
+	if err != nil {
+		return
+	}
+	var c cache
+	err = json.Unmarshal(bs, &c)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("invalid idempotent cache")
+		return
+	}
+	if c.Type == "" {
+		reply = c.Data
+		ok = true
+		return
+	}
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(c.Type))
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("unknown idempotent cache type %s", c.Type)
+		return
+	}
+	m := mt.New().Interface()
+	err = protojson.Unmarshal(c.Data, m)
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("invalid idempotent cache data")
+		return
+	}
+	reply = m
+	ok = true
+	return
+}
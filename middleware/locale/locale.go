@@ -0,0 +1,91 @@
+package locale
+
+import (
+	"context"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"golang.org/x/text/language"
+	"google.golang.org/grpc/metadata"
+)
+
+const AcceptLanguage = "accept-language"
+
+type localeCtx struct{}
+
+type Parser struct {
+	ops     Options
+	matcher language.Matcher
+}
+
+func NewParser(options ...func(*Options)) *Parser {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	return &Parser{
+		ops:     *ops,
+		matcher: language.NewMatcher(ops.supported),
+	}
+}
+
+// Locale parse language from query > custom header > accept-language, normalize by supported languages
+func Locale(options ...func(*Options)) middleware.Middleware {
+	l := NewParser(options...)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (rp interface{}, err error) {
+			lang := l.Default()
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				var override string
+				if ht, ok2 := tr.(http.Transporter); ok2 && l.ops.query != "" {
+					override = ht.Request().URL.Query().Get(l.ops.query)
+				}
+				if override == "" && l.ops.header != "" {
+					override = tr.RequestHeader().Get(l.ops.header)
+				}
+				lang = l.Parse(override, tr.RequestHeader().Get(AcceptLanguage))
+				tr.ReplyHeader().Set("content-language", lang.String())
+			}
+			ctx = NewContext(ctx, lang)
+			// pass to downstream grpc service
+			ctx = metadata.AppendToOutgoingContext(ctx, AcceptLanguage, lang.String())
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Default get default language
+func (l Parser) Default() language.Tag {
+	return l.ops.supported[0]
+}
+
+// Parse get the best supported language, the first valid str has the highest priority
+func (l Parser) Parse(str ...string) language.Tag {
+	for _, item := range str {
+		if item == "" {
+			continue
+		}
+		tags, _, err := language.ParseAcceptLanguage(item)
+		if err != nil || len(tags) == 0 {
+			continue
+		}
+		_, index, confidence := l.matcher.Match(tags...)
+		if confidence != language.No {
+			return l.ops.supported[index]
+		}
+	}
+	return l.Default()
+}
+
+func NewContext(ctx context.Context, lang language.Tag) context.Context {
+	return context.WithValue(ctx, localeCtx{}, lang)
+}
+
+// FromContext get language from context, return language.Und if not found
+func FromContext(ctx context.Context) (lang language.Tag) {
+	lang = language.Und
+	if v, ok := ctx.Value(localeCtx{}).(language.Tag); ok {
+		lang = v
+	}
+	return
+}
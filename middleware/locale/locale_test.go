@@ -0,0 +1,53 @@
+package locale
+
+import (
+	"golang.org/x/text/language"
+	"testing"
+)
+
+func TestParser_Parse(t *testing.T) {
+	p := NewParser(WithSupported(language.English, language.SimplifiedChinese, language.Japanese))
+	tests := []struct {
+		name string
+		args []string
+		want language.Tag
+	}{
+		{
+			name: "empty",
+			args: []string{"", ""},
+			want: language.English,
+		},
+		{
+			name: "accept language",
+			args: []string{"", "zh-CN,zh;q=0.9,en;q=0.8"},
+			want: language.SimplifiedChinese,
+		},
+		{
+			name: "quality",
+			args: []string{"", "fr;q=0.9,ja;q=0.8"},
+			want: language.Japanese,
+		},
+		{
+			name: "override",
+			args: []string{"ja", "zh-CN,zh;q=0.9"},
+			want: language.Japanese,
+		},
+		{
+			name: "invalid override",
+			args: []string{"!!", "zh"},
+			want: language.SimplifiedChinese,
+		},
+		{
+			name: "unsupported",
+			args: []string{"", "ko"},
+			want: language.English,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Parse(tt.args...); got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
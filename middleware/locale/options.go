@@ -0,0 +1,43 @@
+package locale
+
+import "golang.org/x/text/language"
+
+type Options struct {
+	supported []language.Tag
+	query     string
+	header    string
+}
+
+// WithSupported set supported languages, the first one is default
+func WithSupported(tags ...language.Tag) func(*Options) {
+	return func(options *Options) {
+		if len(tags) > 0 {
+			getOptionsOrSetDefault(options).supported = tags
+		}
+	}
+}
+
+// WithQuery http query param name which can override accept-language, empty means disable
+func WithQuery(name string) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).query = name
+	}
+}
+
+// WithHeader custom header name which can override accept-language, empty means disable
+func WithHeader(name string) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).header = name
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			supported: []language.Tag{language.English, language.Chinese},
+			query:     "lang",
+			header:    "x-lang",
+		}
+	}
+	return options
+}
@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-cinch/common/log"
+	"github.com/go-cinch/common/middleware/ratelimit"
+	"github.com/go-cinch/common/middleware/requestid"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"math/rand"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Logging print access log by common log, error request use warn(4xx)/error(5xx) level, slow request use warn level
+func Logging(options ...func(*Options)) middleware.Middleware {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	// the same client ip as rate limit
+	clientIP := ratelimit.ByIP(ops.trustedProxies...)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (rp interface{}, err error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			fields := log.Fields{
+				"kind":      tr.Kind().String(),
+				"operation": tr.Operation(),
+			}
+			var p string
+			if ht, ok := tr.(http.Transporter); ok {
+				fields["method"] = ht.Request().Method
+				p = ht.Request().URL.Path
+				fields["path"] = p
+			}
+			if !ops.match(tr.Operation(), p) {
+				return handler(ctx, req)
+			}
+			start := time.Now()
+			rp, err = handler(ctx, req)
+			latency := time.Since(start)
+
+			fields["latency"] = latency.String()
+			fields["peer"] = clientIP(ctx)
+			// generated id is also in ctx if header is missing
+			if v := requestid.FromContext(ctx); v != "" {
+				fields["request.id"] = v
+			}
+			code := 200
+			if se := errors.FromError(err); se != nil {
+				code = int(se.Code)
+				fields["reason"] = se.Reason
+			}
+			fields["code"] = code
+			if ops.body && (ops.bodySample >= 1 || rand.Float64() < ops.bodySample) {
+				fields["req"] = body(req, ops.bodyMaxLength)
+				if err == nil {
+					fields["rp"] = body(rp, ops.bodyMaxLength)
+				}
+			}
+
+			l := log.WithContext(ctx).WithFields(fields)
+			switch {
+			case code >= 500:
+				l.WithError(err).Error("access")
+			case code >= 400:
+				l.WithError(err).Warn("access")
+			case ops.slow > 0 && latency > ops.slow:
+				l.Warn("access(slow)")
+			default:
+				l.Info("access")
+			}
+			return
+		}
+	}
+}
+
+func (ops Options) match(operation, path string) bool {
+	for _, item := range ops.exclude {
+		if matchPattern(item, operation) || matchPattern(item, path) {
+			return false
+		}
+	}
+	if len(ops.include) == 0 {
+		return true
+	}
+	for _, item := range ops.include {
+		if matchPattern(item, operation) || matchPattern(item, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPattern(pattern, s string) bool {
+	if s == "" {
+		return false
+	}
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(s, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == s
+}
+
+func body(v interface{}, max int) (rp string) {
+	if v == nil {
+		return
+	}
+	var bs []byte
+	if m, ok := v.(proto.Message); ok {
+		bs, _ = protojson.Marshal(m)
+	} else {
+		bs, _ = json.Marshal(v)
+	}
+	rp = string(bs)
+	if len(rp) > max {
+		// do not cut in the middle of utf-8 rune
+		for max > 0 && !utf8.RuneStart(rp[max]) {
+			max--
+		}
+		rp = strings.Join([]string{rp[:max], "...(truncated)"}, "")
+	}
+	return
+}
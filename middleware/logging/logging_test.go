@@ -0,0 +1,76 @@
+package logging
+
+import "testing"
+
+func TestOptions_match(t *testing.T) {
+	type args struct {
+		operation string
+		path      string
+	}
+	tests := []struct {
+		name    string
+		options []func(*Options)
+		args    args
+		want    bool
+	}{
+		{
+			name: "default",
+			args: args{operation: "/auth.v1.Auth/Login", path: "/auth/login"},
+			want: true,
+		},
+		{
+			name:    "exclude operation",
+			options: []func(*Options){WithExclude("/auth.v1.Auth/*")},
+			args:    args{operation: "/auth.v1.Auth/Login", path: "/auth/login"},
+			want:    false,
+		},
+		{
+			name:    "exclude path",
+			options: []func(*Options){WithExclude("/healthz")},
+			args:    args{operation: "", path: "/healthz"},
+			want:    false,
+		},
+		{
+			name:    "include",
+			options: []func(*Options){WithInclude("/auth/*")},
+			args:    args{operation: "/auth.v1.Auth/Login", path: "/auth/login"},
+			want:    true,
+		},
+		{
+			name:    "not include",
+			options: []func(*Options){WithInclude("/auth/*")},
+			args:    args{operation: "/game.v1.Game/Find", path: "/game/list"},
+			want:    false,
+		},
+		{
+			name:    "exclude has higher priority",
+			options: []func(*Options){WithInclude("/auth/*"), WithExclude("/auth/login")},
+			args:    args{operation: "/auth.v1.Auth/Login", path: "/auth/login"},
+			want:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := getOptionsOrSetDefault(nil)
+			for _, f := range tt.options {
+				f(ops)
+			}
+			if got := ops.match(tt.args.operation, tt.args.path); got != tt.want {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_body(t *testing.T) {
+	if got := body(map[string]string{"k": "v"}, 100); got != `{"k":"v"}` {
+		t.Errorf("body() = %v", got)
+	}
+	if got := body("0123456789", 5); got != `"0123...(truncated)` {
+		t.Errorf("body() = %v", got)
+	}
+	// "中文" is 3 bytes each, cut at rune boundary
+	if got := body("中文", 5); got != `"中...(truncated)` {
+		t.Errorf("body() = %v", got)
+	}
+}
@@ -0,0 +1,82 @@
+package logging
+
+import "time"
+
+type Options struct {
+	include        []string
+	exclude        []string
+	slow           time.Duration
+	body           bool
+	bodySample     float64
+	bodyMaxLength  int
+	trustedProxies []string
+}
+
+// WithInclude only log matched routes, match operation or http path, suffix * means prefix match
+func WithInclude(patterns ...string) func(*Options) {
+	return func(options *Options) {
+		if len(patterns) > 0 {
+			getOptionsOrSetDefault(options).include = append(getOptionsOrSetDefault(options).include, patterns...)
+		}
+	}
+}
+
+// WithExclude skip matched routes, it has higher priority than include
+func WithExclude(patterns ...string) func(*Options) {
+	return func(options *Options) {
+		if len(patterns) > 0 {
+			getOptionsOrSetDefault(options).exclude = append(getOptionsOrSetDefault(options).exclude, patterns...)
+		}
+	}
+}
+
+func WithSlow(milli int64) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).slow = time.Duration(milli) * time.Millisecond
+	}
+}
+
+func WithBody(flag bool) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).body = flag
+	}
+}
+
+// WithBodySample body sample rate, 0 < rate <= 1
+func WithBodySample(rate float64) func(*Options) {
+	return func(options *Options) {
+		if rate > 0 && rate <= 1 {
+			getOptionsOrSetDefault(options).bodySample = rate
+		}
+	}
+}
+
+func WithBodyMaxLength(length int) func(*Options) {
+	return func(options *Options) {
+		if length > 0 {
+			getOptionsOrSetDefault(options).bodyMaxLength = length
+		}
+	}
+}
+
+// WithTrustedProxies forwarding headers are read only if the peer is one of trusted proxies(ip or cidr), default peer address
+func WithTrustedProxies(proxies ...string) func(*Options) {
+	return func(options *Options) {
+		if len(proxies) > 0 {
+			getOptionsOrSetDefault(options).trustedProxies = append(getOptionsOrSetDefault(options).trustedProxies, proxies...)
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			include:       []string{},
+			exclude:       []string{},
+			slow:          time.Second,
+			bodySample:    1,
+			bodyMaxLength: 1024,
+		}
+	}
+	return options
+}
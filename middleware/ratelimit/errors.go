@@ -0,0 +1,16 @@
+package ratelimit
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrRateLimited is returned when key exceeds the limit, RetryAfter is the minimum wait time before next request
+type ErrRateLimited struct {
+	Key        string
+	RetryAfter time.Duration
+}
+
+func (e ErrRateLimited) Error() string {
+	return fmt.Sprintf("rate limited, retry after %s", e.RetryAfter)
+}
@@ -0,0 +1,36 @@
+module github.com/go-cinch/common/middleware/ratelimit
+
+go 1.20
+
+replace (
+	github.com/go-cinch/common/constant => ../../constant
+	github.com/go-cinch/common/jwt => ../../jwt
+	github.com/go-cinch/common/log => ../../log
+)
+
+require (
+	github.com/alicebob/miniredis/v2 v2.31.1
+	github.com/go-cinch/common/constant v1.0.3
+	github.com/go-cinch/common/jwt v1.0.3
+	github.com/go-cinch/common/log v1.0.4
+	github.com/go-kratos/kratos/v2 v2.7.0
+	github.com/google/uuid v1.3.1
+	github.com/redis/go-redis/v9 v9.2.1
+	google.golang.org/grpc v1.56.1
+)
+
+require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
+	github.com/go-kratos/aegis v0.2.0 // indirect
+	github.com/go-playground/form/v4 v4.2.1 // indirect
+	github.com/golang-jwt/jwt/v4 v4.5.0 // indirect
+	github.com/golang-module/carbon/v2 v2.2.8 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/gorilla/mux v1.8.0 // indirect
+	github.com/yuin/gopher-lua v1.1.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230629202037-9506855d4529 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
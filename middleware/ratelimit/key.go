@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/go-cinch/common/jwt"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+	"google.golang.org/grpc/peer"
+	"net"
+	"strings"
+)
+
+// ByIP use client ip as key, default is the transport peer address(RemoteAddr),
+// X-Forwarded-For and X-Real-Ip are read only if the peer is one of trusted proxies(ip or cidr),
+// the right-most untrusted hop of X-Forwarded-For is the client ip
+func ByIP(trustedProxies ...string) func(ctx context.Context) string {
+	trusted := parseNets(trustedProxies)
+	return func(ctx context.Context) (ip string) {
+		ip = peerIP(ctx)
+		if !contains(trusted, ip) {
+			return
+		}
+		tr, ok := transport.FromServerContext(ctx)
+		if !ok {
+			return
+		}
+		if v := tr.RequestHeader().Get("X-Forwarded-For"); v != "" {
+			hops := strings.Split(v, ",")
+			for i := len(hops) - 1; i >= 0; i-- {
+				hop := strings.TrimSpace(hops[i])
+				if net.ParseIP(hop) == nil {
+					break
+				}
+				ip = hop
+				if !contains(trusted, hop) {
+					break
+				}
+			}
+			return
+		}
+		if v := strings.TrimSpace(tr.RequestHeader().Get("X-Real-Ip")); net.ParseIP(v) != nil {
+			ip = v
+		}
+		return
+	}
+}
+
+// ByUser use jwt user code as key, anonymous request will skip limit
+func ByUser() func(ctx context.Context) string {
+	return func(ctx context.Context) string {
+		return jwt.FromServerContext(ctx).Code
+	}
+}
+
+// ByHeader use request header value as key
+func ByHeader(name string) func(ctx context.Context) string {
+	return func(ctx context.Context) (v string) {
+		if tr, ok := transport.FromServerContext(ctx); ok {
+			v = tr.RequestHeader().Get(name)
+		}
+		return
+	}
+}
+
+// ByOperation combine operation and other key, limit each api separately
+func ByOperation(f func(ctx context.Context) string) func(ctx context.Context) string {
+	return func(ctx context.Context) (v string) {
+		v = f(ctx)
+		if v == "" {
+			return
+		}
+		if tr, ok := transport.FromServerContext(ctx); ok {
+			v = strings.Join([]string{tr.Operation(), v}, ".")
+		}
+		return
+	}
+}
+
+func peerIP(ctx context.Context) (ip string) {
+	if r, ok := http.RequestFromServerContext(ctx); ok {
+		ip = r.RemoteAddr
+	} else if p, ok := peer.FromContext(ctx); ok {
+		ip = p.Addr.String()
+	}
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return
+}
+
+func parseNets(arr []string) (nets []*net.IPNet) {
+	for _, item := range arr {
+		item = strings.TrimSpace(item)
+		if _, n, err := net.ParseCIDR(item); err == nil {
+			nets = append(nets, n)
+			continue
+		}
+		if ip := net.ParseIP(item); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+	return
+}
+
+func contains(nets []*net.IPNet, s string) bool {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/go-cinch/common/constant"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"math"
+	"strconv"
+)
+
+// RateLimit limit requests by key, exceeded request will get 429 error with Retry-After header
+func RateLimit(options ...func(*Options)) middleware.Middleware {
+	l := New(options...)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (rp interface{}, err error) {
+			key := l.ops.key(ctx)
+			if key == "" {
+				return handler(ctx, req)
+			}
+			err = l.Allow(ctx, key)
+			var e ErrRateLimited
+			if errors.As(err, &e) {
+				retryAfter := strconv.FormatInt(int64(math.Ceil(e.RetryAfter.Seconds())), 10)
+				if tr, ok := transport.FromServerContext(ctx); ok {
+					tr.ReplyHeader().Set("Retry-After", retryAfter)
+				}
+				err = errors.New(429, constant.TooManyRequests, e.Error()).WithMetadata(map[string]string{
+					"retryAfter": retryAfter,
+				})
+				return
+			}
+			return handler(ctx, req)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+)
+
+type Algorithm string
+
+const (
+	SlidingWindow Algorithm = "sliding_window"
+	TokenBucket   Algorithm = "token_bucket"
+)
+
+type Options struct {
+	redis     redis.UniversalClient
+	prefix    string
+	algorithm Algorithm
+	limit     int
+	window    int
+	burst     int
+	key       func(ctx context.Context) string
+}
+
+func WithRedis(rd redis.UniversalClient) func(*Options) {
+	return func(options *Options) {
+		if rd != nil {
+			getOptionsOrSetDefault(options).redis = rd
+		}
+	}
+}
+
+func WithPrefix(prefix string) func(*Options) {
+	return func(options *Options) {
+		if prefix != "" {
+			getOptionsOrSetDefault(options).prefix = prefix
+		}
+	}
+}
+
+func WithAlgorithm(algorithm Algorithm) func(*Options) {
+	return func(options *Options) {
+		if algorithm == SlidingWindow || algorithm == TokenBucket {
+			getOptionsOrSetDefault(options).algorithm = algorithm
+		}
+	}
+}
+
+// WithLimit max requests in one window, token bucket will refill limit tokens per window
+func WithLimit(count int) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).limit = count
+		}
+	}
+}
+
+func WithWindow(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).window = second
+		}
+	}
+}
+
+// WithBurst token bucket capacity, only token bucket, default same as limit
+func WithBurst(count int) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).burst = count
+		}
+	}
+}
+
+// WithKey custom key extractor, only middleware, empty key will skip limit
+func WithKey(f func(ctx context.Context) string) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).key = f
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			prefix:    "ratelimit",
+			algorithm: SlidingWindow,
+			limit:     100,
+			window:    60,
+			key:       ByIP(),
+		}
+	}
+	return options
+}
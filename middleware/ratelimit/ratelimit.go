@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/go-cinch/common/log"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// redis lua script
+const (
+	// return 0 if allowed, otherwise return retry after milliseconds
+	luaSlidingWindow string = `
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], 0, now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if count < limit then
+    redis.call('ZADD', KEYS[1], now, ARGV[4])
+    redis.call('PEXPIRE', KEYS[1], window)
+    return 0
+end
+local first = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return math.max(1, tonumber(first[2]) + window - now)
+`
+	luaTokenBucket string = `
+local now = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local burst = tonumber(ARGV[3])
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil or ts == nil then
+    tokens = burst
+    ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local wait = 0
+if tokens < 1 then
+    wait = math.ceil((1 - tokens) / rate)
+else
+    tokens = tokens - 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate) + 1000)
+return wait
+`
+)
+
+type Limiter struct {
+	ops Options
+}
+
+func New(options ...func(*Options)) *Limiter {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.burst <= 0 {
+		ops.burst = ops.limit
+	}
+	return &Limiter{ops: *ops}
+}
+
+// Allow consume one request of key, return ErrRateLimited if exceeds the limit
+func (l *Limiter) Allow(ctx context.Context, key string) (err error) {
+	if l.ops.redis == nil {
+		log.WithContext(ctx).Warn("please enable redis, otherwise the rate limit is invalid")
+		return
+	}
+	now := time.Now().UnixMilli()
+	window := int64(l.ops.window) * 1000
+	k := strings.Join([]string{l.ops.prefix, string(l.ops.algorithm), key}, ".")
+	var res int64
+	switch l.ops.algorithm {
+	case TokenBucket:
+		// tokens per millisecond
+		rate := float64(l.ops.limit) / float64(window)
+		res, err = l.ops.redis.Eval(ctx, luaTokenBucket, []string{k}, now, rate, l.ops.burst).Int64()
+	default:
+		res, err = l.ops.redis.Eval(ctx, luaSlidingWindow, []string{k}, now, window, l.ops.limit, uuid.NewString()).Int64()
+	}
+	if err != nil {
+		// fail open, redis is unavailable should not block all requests
+		log.WithContext(ctx).WithError(err).Warn("rate limit failed")
+		err = nil
+		return
+	}
+	if res > 0 {
+		err = ErrRateLimited{
+			Key:        key,
+			RetryAfter: time.Duration(res) * time.Millisecond,
+		}
+	}
+	return
+}
+
+// Reset clear the limit status of key
+func (l *Limiter) Reset(ctx context.Context, key string) {
+	if l.ops.redis == nil {
+		return
+	}
+	l.ops.redis.Del(ctx, strings.Join([]string{l.ops.prefix, string(l.ops.algorithm), key}, "."))
+}
@@ -0,0 +1,197 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	kerrors "github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc/peer"
+	"net"
+	"net/http"
+	"testing"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+
+type testTransport struct {
+	request headerCarrier
+	reply   headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "/test.v1.Test/Create" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.request }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.reply }
+
+func newContext(addr string, header map[string]string) (context.Context, *testTransport) {
+	tr := &testTransport{request: headerCarrier{}, reply: headerCarrier{}}
+	for k, v := range header {
+		tr.request.Set(k, v)
+	}
+	ctx := transport.NewServerContext(context.Background(), tr)
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 8080}})
+	return ctx, tr
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	tests := []struct {
+		name      string
+		algorithm Algorithm
+	}{
+		{
+			name:      "sliding window",
+			algorithm: SlidingWindow,
+		},
+		{
+			name:      "token bucket",
+			algorithm: TokenBucket,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(
+				WithRedis(client),
+				WithAlgorithm(tt.algorithm),
+				WithLimit(3),
+				WithWindow(60),
+			)
+			ctx := context.Background()
+			for i := 0; i < 3; i++ {
+				if err := l.Allow(ctx, "user1"); err != nil {
+					t.Fatalf("Allow() %d error = %v", i, err)
+				}
+			}
+			err := l.Allow(ctx, "user1")
+			var e ErrRateLimited
+			if !errors.As(err, &e) || e.RetryAfter <= 0 {
+				t.Fatalf("Allow() error = %v, want ErrRateLimited", err)
+			}
+			if err = l.Allow(ctx, "user2"); err != nil {
+				t.Fatalf("Allow() other key error = %v", err)
+			}
+			l.Reset(ctx, "user1")
+			if err = l.Allow(ctx, "user1"); err != nil {
+				t.Fatalf("Allow() after reset error = %v", err)
+			}
+		})
+	}
+}
+
+func TestByIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		addr    string
+		header  map[string]string
+		want    string
+	}{
+		{
+			name:   "peer",
+			addr:   "1.1.1.1",
+			header: map[string]string{"X-Forwarded-For": "2.2.2.2", "X-Real-Ip": "3.3.3.3"},
+			want:   "1.1.1.1",
+		},
+		{
+			name:    "untrusted peer",
+			proxies: []string{"10.0.0.0/8"},
+			addr:    "1.1.1.1",
+			header:  map[string]string{"X-Forwarded-For": "2.2.2.2"},
+			want:    "1.1.1.1",
+		},
+		{
+			name:    "right-most untrusted hop",
+			proxies: []string{"10.0.0.0/8", "192.168.1.1"},
+			addr:    "10.0.0.1",
+			header:  map[string]string{"X-Forwarded-For": "6.6.6.6, 2.2.2.2, 192.168.1.1"},
+			want:    "2.2.2.2",
+		},
+		{
+			name:    "all hops trusted",
+			proxies: []string{"10.0.0.0/8"},
+			addr:    "10.0.0.1",
+			header:  map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"},
+			want:    "10.0.0.3",
+		},
+		{
+			name:    "invalid hop",
+			proxies: []string{"10.0.0.0/8"},
+			addr:    "10.0.0.1",
+			header:  map[string]string{"X-Forwarded-For": "2.2.2.2, unknown, 10.0.0.2"},
+			want:    "10.0.0.2",
+		},
+		{
+			name:    "real ip",
+			proxies: []string{"10.0.0.1"},
+			addr:    "10.0.0.1",
+			header:  map[string]string{"X-Real-Ip": "3.3.3.3"},
+			want:    "3.3.3.3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := newContext(tt.addr, tt.header)
+			if got := ByIP(tt.proxies...)(ctx); got != tt.want {
+				t.Fatalf("ByIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	m := RateLimit(
+		WithRedis(client),
+		WithLimit(2),
+		WithWindow(60),
+		WithKey(ByOperation(ByIP("10.0.0.0/8"))),
+	)
+	handler := m(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	})
+	for i := 0; i < 2; i++ {
+		ctx, _ := newContext("10.0.0.1", map[string]string{"X-Forwarded-For": "2.2.2.2"})
+		if _, err := handler(ctx, nil); err != nil {
+			t.Fatalf("handler() %d error = %v", i, err)
+		}
+	}
+	if !s.Exists("ratelimit.sliding_window./test.v1.Test/Create.2.2.2.2") {
+		t.Fatalf("key not found, keys = %v", s.Keys())
+	}
+
+	ctx, tr := newContext("10.0.0.2", map[string]string{"X-Forwarded-For": "2.2.2.2"})
+	_, err := handler(ctx, nil)
+	if e := kerrors.FromError(err); e.Code != 429 || e.Metadata["retryAfter"] == "" {
+		t.Fatalf("handler() error = %v, want 429", err)
+	}
+	if tr.reply.Get("Retry-After") == "" {
+		t.Fatal("Retry-After header not found")
+	}
+
+	// forged header from untrusted peer is ignored
+	ctx, _ = newContext("1.1.1.1", map[string]string{"X-Forwarded-For": "2.2.2.2"})
+	if _, err = handler(ctx, nil); err != nil {
+		t.Fatalf("handler() untrusted peer error = %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/go-cinch/common/middleware/requestid
+
+go 1.20
+
+require (
+	github.com/go-kratos/kratos/v2 v2.7.0
+	github.com/google/uuid v1.3.1
+)
+
+require (
+	github.com/go-playground/form/v4 v4.2.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,34 @@
+package requestid
+
+import "github.com/google/uuid"
+
+type Options struct {
+	header    string
+	generator func() string
+}
+
+func WithHeader(header string) func(*Options) {
+	return func(options *Options) {
+		if header != "" {
+			getOptionsOrSetDefault(options).header = header
+		}
+	}
+}
+
+func WithGenerator(f func() string) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).generator = f
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			header:    "X-Request-Id",
+			generator: uuid.NewString,
+		}
+	}
+	return options
+}
@@ -0,0 +1,89 @@
+package requestid
+
+import (
+	"context"
+	"github.com/go-kratos/kratos/v2/log"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+)
+
+// HeaderKey is the key of worker task header
+const HeaderKey = "request.id"
+
+type requestIdCtx struct{}
+
+// Server get request id from request header or generate a new one, set it to ctx and response header
+func Server(options ...func(*Options)) middleware.Middleware {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (rp interface{}, err error) {
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				id := tr.RequestHeader().Get(ops.header)
+				if id == "" {
+					id = FromContext(ctx)
+				}
+				if id == "" {
+					id = ops.generator()
+				}
+				ctx = NewContext(ctx, id)
+				tr.ReplyHeader().Set(ops.header, id)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Client pass request id to downstream(http header or grpc metadata)
+func Client(options ...func(*Options)) middleware.Middleware {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (rp interface{}, err error) {
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				if id := FromContext(ctx); id != "" {
+					tr.RequestHeader().Set(ops.header, id)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIdCtx{}, id)
+}
+
+func FromContext(ctx context.Context) (id string) {
+	if v, ok := ctx.Value(requestIdCtx{}).(string); ok {
+		id = v
+	}
+	return
+}
+
+// ID is log valuer, common log: log.WithValuer("request.id", requestid.ID())
+func ID() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		return FromContext(ctx)
+	}
+}
+
+// Carrier is worker carrier, worker.WithCarrier(requestid.Carrier{})
+type Carrier struct{}
+
+func (Carrier) Inject(ctx context.Context, header map[string]string) {
+	if id := FromContext(ctx); id != "" {
+		header[HeaderKey] = id
+	}
+}
+
+func (Carrier) Extract(ctx context.Context, header map[string]string) context.Context {
+	if id, ok := header[HeaderKey]; ok && id != "" {
+		ctx = NewContext(ctx, id)
+	}
+	return ctx
+}
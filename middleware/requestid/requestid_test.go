@@ -0,0 +1,111 @@
+package requestid
+
+import (
+	"context"
+	"github.com/go-kratos/kratos/v2/transport"
+	"net/http"
+	"testing"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+
+type testTransport struct {
+	request headerCarrier
+	reply   headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "/test.v1.Test/Get" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.request }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.reply }
+
+func newTestTransport(id string) *testTransport {
+	tr := &testTransport{
+		request: headerCarrier{},
+		reply:   headerCarrier{},
+	}
+	if id != "" {
+		tr.request.Set("X-Request-Id", id)
+	}
+	return tr
+}
+
+func TestServer(t *testing.T) {
+	var got string
+	h := Server(WithGenerator(func() string {
+		return "generated"
+	}))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = FromContext(ctx)
+		return nil, nil
+	})
+
+	// generate id if header is absent
+	tr := newTestTransport("")
+	_, _ = h(transport.NewServerContext(context.Background(), tr), nil)
+	if got != "generated" || tr.reply.Get("X-Request-Id") != "generated" {
+		t.Fatalf("generated id = %s, reply = %v", got, tr.reply)
+	}
+
+	// reuse incoming header
+	tr = newTestTransport("id1")
+	_, _ = h(transport.NewServerContext(context.Background(), tr), nil)
+	if got != "id1" || tr.reply.Get("X-Request-Id") != "id1" {
+		t.Fatalf("incoming id = %s, reply = %v", got, tr.reply)
+	}
+}
+
+func TestClient(t *testing.T) {
+	h := Client()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	tr := newTestTransport("")
+	_, _ = h(transport.NewClientContext(NewContext(context.Background(), "id1"), tr), nil)
+	if tr.request.Get("X-Request-Id") != "id1" {
+		t.Fatalf("request header = %v", tr.request)
+	}
+
+	// id is not set if ctx has no id
+	tr = newTestTransport("")
+	_, _ = h(transport.NewClientContext(context.Background(), tr), nil)
+	if len(tr.request) != 0 {
+		t.Fatalf("request header = %v", tr.request)
+	}
+}
+
+func TestCarrier(t *testing.T) {
+	header := make(map[string]string)
+	Carrier{}.Inject(context.Background(), header)
+	if len(header) != 0 {
+		t.Fatalf("Inject() without id, header = %v", header)
+	}
+
+	Carrier{}.Inject(NewContext(context.Background(), "id1"), header)
+	if header[HeaderKey] != "id1" {
+		t.Fatalf("Inject() header = %v", header)
+	}
+
+	ctx := Carrier{}.Extract(context.Background(), header)
+	if got := FromContext(ctx); got != "id1" {
+		t.Fatalf("Extract() id = %v, want id1", got)
+	}
+	if got := ID()(ctx); got != "id1" {
+		t.Fatalf("ID() = %v, want id1", got)
+	}
+}
@@ -0,0 +1,11 @@
+package tenant
+
+import (
+	"github.com/go-cinch/common/constant"
+	"github.com/go-kratos/kratos/v2/errors"
+)
+
+var (
+	ErrMissingTenant  = errors.BadRequest(constant.IllegalParameter, "missing tenant id")
+	ErrTenantMismatch = errors.Forbidden(constant.NoPermission, "tenant id mismatch")
+)
@@ -0,0 +1,85 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-cinch/common/jwt"
+	"github.com/go-cinch/common/tenant"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"strconv"
+)
+
+// Tenant set tenant id from x-tenant-id header to ctx
+//
+// Deprecated: use Server, header is trusted here without verifying, it is the same as Server(WithTrustHeader(true))
+func Tenant() middleware.Middleware {
+	return Server(WithTrustHeader(true))
+}
+
+// Server get tenant id from jwt claims and set it to ctx, header can be forged by client,
+// so it is rejected if it disagrees with the claim, and it is trusted only by WithTrustHeader(for service-to-service calls)
+func Server(options ...func(*Options)) middleware.Middleware {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (rp interface{}, err error) {
+			var header string
+			if tr, ok := transport.FromServerContext(ctx); ok {
+				header = tr.RequestHeader().Get(ops.header)
+			}
+			id := tenant.FromContext(ctx)
+			if claims := jwt.ClaimsFromContext(ctx); len(claims) > 0 {
+				// authenticated user, only the claim is trusted
+				id = fromClaims(claims, ops.claim)
+				if header != "" && header != id {
+					err = ErrTenantMismatch
+					return
+				}
+			} else if ops.trustHeader && header != "" {
+				id = header
+			}
+			if id == "" && ops.required {
+				err = ErrMissingTenant
+				return
+			}
+			if id != "" {
+				ctx = tenant.SetTenant(ctx, id)
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+// Client pass tenant id to downstream(http header or grpc metadata)
+func Client(options ...func(*Options)) middleware.Middleware {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (rp interface{}, err error) {
+			if tr, ok := transport.FromClientContext(ctx); ok {
+				if id := tenant.FromContext(ctx); id != "" {
+					tr.RequestHeader().Set(ops.header, id)
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func fromClaims(claims map[string]interface{}, claim string) (id string) {
+	switch v := claims[claim].(type) {
+	case string:
+		id = v
+	case float64:
+		// json number of MapClaims
+		id = strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		id = v.String()
+	}
+	return
+}
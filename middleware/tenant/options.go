@@ -0,0 +1,50 @@
+package tenant
+
+type Options struct {
+	header      string
+	claim       string
+	required    bool
+	trustHeader bool
+}
+
+// WithHeader tenant id header, it is passed to downstream by Client
+func WithHeader(header string) func(*Options) {
+	return func(options *Options) {
+		if header != "" {
+			getOptionsOrSetDefault(options).header = header
+		}
+	}
+}
+
+// WithClaim jwt claim of tenant id, the claims are set by jwt.Server middleware
+func WithClaim(claim string) func(*Options) {
+	return func(options *Options) {
+		if claim != "" {
+			getOptionsOrSetDefault(options).claim = claim
+		}
+	}
+}
+
+// WithTrustHeader trust header if jwt claims are missing, only for service-to-service calls in trusted network
+func WithTrustHeader(flag bool) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).trustHeader = flag
+	}
+}
+
+// WithRequired reject request without tenant id
+func WithRequired(flag bool) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).required = flag
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			header: "X-Tenant-Id",
+			claim:  "tenant",
+		}
+	}
+	return options
+}
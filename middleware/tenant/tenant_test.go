@@ -0,0 +1,98 @@
+package tenant
+
+import (
+	"context"
+	"github.com/go-cinch/common/jwt"
+	"github.com/go-cinch/common/tenant"
+	"github.com/go-kratos/kratos/v2/transport"
+	jwtV4 "github.com/golang-jwt/jwt/v4"
+	"net/http"
+	"testing"
+)
+
+type headerCarrier http.Header
+
+func (hc headerCarrier) Get(key string) string { return http.Header(hc).Get(key) }
+
+func (hc headerCarrier) Set(key string, value string) { http.Header(hc).Set(key, value) }
+
+func (hc headerCarrier) Add(key string, value string) { http.Header(hc).Add(key, value) }
+
+func (hc headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(hc))
+	for k := range http.Header(hc) {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func (hc headerCarrier) Values(key string) []string { return http.Header(hc).Values(key) }
+
+type testTransport struct {
+	request headerCarrier
+	reply   headerCarrier
+}
+
+func (tr *testTransport) Kind() transport.Kind            { return transport.KindHTTP }
+func (tr *testTransport) Endpoint() string                { return "" }
+func (tr *testTransport) Operation() string               { return "/test.v1.Test/Create" }
+func (tr *testTransport) RequestHeader() transport.Header { return tr.request }
+func (tr *testTransport) ReplyHeader() transport.Header   { return tr.reply }
+
+func TestServer(t *testing.T) {
+	var got string
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		got = tenant.FromContext(ctx)
+		return nil, nil
+	}
+	tr := &testTransport{request: headerCarrier{}, reply: headerCarrier{}}
+	tr.request.Set("X-Tenant-Id", "t1")
+	ctx := transport.NewServerContext(context.Background(), tr)
+
+	// header is not trusted by default
+	_, err := Server()(handler)(ctx, nil)
+	if err != nil || got != "" {
+		t.Fatalf("Server() from untrusted header = %s, %v", got, err)
+	}
+	_, err = Server(WithTrustHeader(true))(handler)(ctx, nil)
+	if err != nil || got != "t1" {
+		t.Fatalf("Server() from trusted header = %s, %v", got, err)
+	}
+
+	// only claim is trusted if authenticated, even WithTrustHeader
+	claimsCtx := jwt.NewClaimsContext(ctx, jwtV4.MapClaims{"tenant": float64(2)})
+	_, err = Server(WithTrustHeader(true))(handler)(claimsCtx, nil)
+	if err != ErrTenantMismatch {
+		t.Fatalf("expect mismatch but got %v", err)
+	}
+	tr.request.Set("X-Tenant-Id", "2")
+	got = ""
+	_, err = Server()(handler)(claimsCtx, nil)
+	if err != nil || got != "2" {
+		t.Fatalf("Server() from claim = %s, %v", got, err)
+	}
+	// authenticated user without claim can't choose tenant
+	noClaimCtx := jwt.NewClaimsContext(ctx, jwtV4.MapClaims{"sub": "1"})
+	_, err = Server(WithTrustHeader(true))(handler)(noClaimCtx, nil)
+	if err != ErrTenantMismatch {
+		t.Fatalf("expect mismatch but got %v", err)
+	}
+
+	got = ""
+	tr.request = headerCarrier{}
+	_, err = Server(WithRequired(true))(handler)(transport.NewServerContext(context.Background(), tr), nil)
+	if err != ErrMissingTenant || got != "" {
+		t.Fatalf("expect missing tenant but got %v", err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	tr := &testTransport{request: headerCarrier{}, reply: headerCarrier{}}
+	ctx := transport.NewClientContext(tenant.SetTenant(context.Background(), "t1"), tr)
+	_, _ = Client(WithHeader("X-Md-Global-Tenant"))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})(ctx, nil)
+	if tr.request.Get("X-Md-Global-Tenant") != "t1" {
+		t.Fatalf("unexpected request header %v", tr.request)
+	}
+}
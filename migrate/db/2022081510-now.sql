@@ -1,3 +1,5 @@
 -- +migrate Up
 -- SQL in section 'Up' is executed when this migration is applied
-SELECT NOW();
\ No newline at end of file
+SELECT NOW();
+-- +migrate Down
+SELECT NOW();
@@ -1,13 +1,34 @@
 package migrate
 
 import (
+	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"github.com/go-cinch/common/log"
 	m "github.com/go-sql-driver/mysql"
 	migrate "github.com/rubenv/sql-migrate"
 	"os"
 	"strconv"
 	"strings"
+	"time"
+)
+
+const (
+	// renew the lock only if it is still owned by current instance
+	luaRenew = `
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('pexpire', KEYS[1], ARGV[2])
+end
+return 0
+`
+	// release the lock only if it is still owned by current instance
+	luaRelease = `
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('del', KEYS[1])
+end
+return 0
+`
 )
 
 func Do(options ...func(*Options)) (err error) {
@@ -16,47 +37,27 @@ func Do(options ...func(*Options)) (err error) {
 		f(ops)
 	}
 
-	err = database(ops)
+	var db *sql.DB
+	var dialect string
+	db, dialect, err = open(ops)
 	if err != nil {
 		return
 	}
+	defer closeDb(ops, db)
 
-	var db *sql.DB
-	db, err = sql.Open(ops.driver, ops.uri)
+	release, err := lock(ops, db, dialect)
 	if err != nil {
-		log.
-			WithContext(ops.ctx).
-			WithError(err).
-			Error("open %s(%s) failed", ops.driver, ops.uri)
 		return
 	}
-
 	defer func() {
-		releaseErr := releaseLock(ops, db)
+		releaseErr := release()
 		if releaseErr != nil && err == nil {
 			err = releaseErr
 		}
 	}()
 
-	var lockAcquired bool
-	for {
-		lockAcquired, err = acquireLock(ops, db)
-		if err != nil {
-			return
-		}
-		if lockAcquired {
-			break
-		} else {
-			log.
-				WithContext(ops.ctx).
-				WithFields(log.Fields{
-					"migrate.lock": ops.lockName,
-				}).
-				Info("cannot acquire advisory lock, retrying...")
-		}
-	}
-
-	if ops.before != nil {
+	// dry run only print the plan, the before callback may change data
+	if ops.before != nil && !ops.dryRun {
 		err = ops.before(ops.ctx)
 		if err != nil {
 			log.
@@ -68,7 +69,7 @@ func Do(options ...func(*Options)) (err error) {
 	}
 
 	rollback := os.Getenv("SQL_MIGRATE_ROLLBACK")
-	if rollback != "" {
+	if rollback != "" && !ops.dryRun {
 		log.
 			WithContext(ops.ctx).
 			WithField("sql", rollback).
@@ -99,7 +100,7 @@ func Do(options ...func(*Options)) (err error) {
 		FileSystem: ops.fs,
 		Root:       ops.fsRoot,
 	}
-	err = status(ops, db, source)
+	_, _, err = status(ops, db, dialect, source)
 	if err != nil {
 		log.
 			WithContext(ops.ctx).
@@ -108,17 +109,327 @@ func Do(options ...func(*Options)) (err error) {
 		return
 	}
 
-	_, err = migrate.Exec(db, ops.driver, source, migrate.Up)
+	err = exec(ops, db, dialect, source)
+	return
+}
+
+// Status get applied and pending migration ids, can be used to check current version
+func Status(options ...func(*Options)) (applied, pending []string, err error) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	var db *sql.DB
+	var dialect string
+	db, dialect, err = open(ops)
+	if err != nil {
+		return
+	}
+	defer closeDb(ops, db)
+	migrate.SetTable(ops.changeTable)
+	source := &migrate.EmbedFileSystemMigrationSource{
+		FileSystem: ops.fs,
+		Root:       ops.fsRoot,
+	}
+	applied, pending, err = status(ops, db, dialect, source)
+	return
+}
+
+func exec(ops *Options, db *sql.DB, dialect string, source migrate.MigrationSource) (err error) {
+	direction := migrate.Up
+	limit := 0
+	if ops.down > 0 {
+		direction = migrate.Down
+		limit = ops.down
+	}
+	var planned []*migrate.PlannedMigration
+	if ops.version > 0 {
+		// direction is detected by current version
+		direction, err = versionDirection(ops, db, dialect, source)
+		if err != nil {
+			return
+		}
+		planned, _, err = migrate.PlanMigrationToVersion(db, dialect, source, direction, ops.version)
+	} else {
+		planned, _, err = migrate.PlanMigration(db, dialect, source, direction, limit)
+	}
 	if err != nil {
 		log.
 			WithContext(ops.ctx).
 			WithError(err).
+			Error("plan migration failed")
+		return
+	}
+	ids := make([]string, len(planned))
+	for i, item := range planned {
+		ids[i] = item.Id
+	}
+	fields := log.Fields{
+		"migrate.direction": directionName(direction),
+		"migrate.planned":   strings.Join(ids, ","),
+	}
+
+	if ops.dryRun {
+		for _, item := range planned {
+			log.
+				WithContext(ops.ctx).
+				WithFields(fields).
+				WithField("sql", strings.Join(item.Queries, "; ")).
+				Info("dry run migration: %s", item.Id)
+		}
+		log.
+			WithContext(ops.ctx).
+			WithFields(fields).
+			Info("dry run success, planned: %d", len(planned))
+		return
+	}
+
+	var n int
+	if ops.version > 0 {
+		n, err = migrate.ExecVersionContext(ops.ctx, db, dialect, source, direction, ops.version)
+	} else {
+		n, err = migrate.ExecMaxContext(ops.ctx, db, dialect, source, direction, limit)
+	}
+	if err != nil {
+		log.
+			WithContext(ops.ctx).
+			WithError(err).
+			WithFields(fields).
 			Error("migrate failed")
 		return
 	}
 	log.
 		WithContext(ops.ctx).
-		Info("migrate success")
+		WithFields(fields).
+		Info("migrate success, applied: %d", n)
+	return
+}
+
+// versionDirection up if target version is greater than the latest applied version, otherwise down
+func versionDirection(ops *Options, db *sql.DB, dialect string, source migrate.MigrationSource) (direction migrate.MigrationDirection, err error) {
+	direction = migrate.Up
+	var migrations []*migrate.Migration
+	migrations, err = source.FindMigrations()
+	if err != nil {
+		return
+	}
+	var records []*migrate.MigrationRecord
+	records, err = migrate.GetMigrationRecords(db, dialect)
+	if err != nil {
+		return
+	}
+	applied := make(map[string]bool)
+	for _, item := range records {
+		applied[item.Id] = true
+	}
+	var current int64
+	for _, item := range migrations {
+		if applied[item.Id] && item.VersionInt() > current {
+			current = item.VersionInt()
+		}
+	}
+	if ops.version < current {
+		direction = migrate.Down
+	}
+	return
+}
+
+func directionName(direction migrate.MigrationDirection) string {
+	if direction == migrate.Down {
+		return "down"
+	}
+	return "up"
+}
+
+// open get sql.DB and sql-migrate dialect
+func open(ops *Options) (db *sql.DB, dialect string, err error) {
+	if ops.db != nil {
+		db, err = ops.db.DB()
+		if err != nil {
+			log.
+				WithContext(ops.ctx).
+				WithError(err).
+				Error("get sql.DB from gorm failed")
+			return
+		}
+		dialect = gormDialect(ops.db.Dialector.Name())
+		return
+	}
+	dialect = ops.driver
+	// dry run never create database
+	if ops.driver == "mysql" && !ops.dryRun {
+		err = database(ops)
+		if err != nil {
+			return
+		}
+	}
+	db, err = sql.Open(ops.driver, ops.uri)
+	if err != nil {
+		log.
+			WithContext(ops.ctx).
+			WithError(err).
+			Error("open %s(%s) failed", ops.driver, ops.uri)
+	}
+	return
+}
+
+// closeDb close db opened by uri, db of gorm is managed by caller
+func closeDb(ops *Options, db *sql.DB) {
+	if ops.db == nil {
+		_ = db.Close()
+	}
+}
+
+// gormDialect convert gorm dialector name to sql-migrate dialect
+func gormDialect(name string) string {
+	switch name {
+	case "sqlite":
+		return "sqlite3"
+	case "sqlserver":
+		return "mssql"
+	}
+	return name
+}
+
+// lock only one instance can migrate at the same time, redis lock is preferred, mysql advisory lock is used by default
+func lock(ops *Options, db *sql.DB, dialect string) (release func() error, err error) {
+	release = func() error { return nil }
+	if ops.redis != nil {
+		return redisLock(ops)
+	}
+	if dialect != "mysql" {
+		log.
+			WithContext(ops.ctx).
+			Warn("advisory lock is only supported by mysql, please enable redis to lock migration")
+		return
+	}
+	// advisory lock belongs to session, acquire and release it by the same connection
+	var conn *sql.Conn
+	conn, err = db.Conn(ops.ctx)
+	if err != nil {
+		log.
+			WithContext(ops.ctx).
+			WithError(err).
+			Error("get connection for advisory lock failed")
+		return
+	}
+	var lockAcquired bool
+	for {
+		lockAcquired, err = acquireLock(ops, conn)
+		if err != nil {
+			_ = conn.Close()
+			return
+		}
+		if lockAcquired {
+			break
+		}
+		log.
+			WithContext(ops.ctx).
+			WithFields(log.Fields{
+				"migrate.lock": ops.lockName,
+			}).
+			Info("cannot acquire advisory lock, retrying...")
+		if err = ops.ctx.Err(); err != nil {
+			_ = conn.Close()
+			return
+		}
+	}
+	release = func() error {
+		defer conn.Close()
+		return releaseLock(ops, conn)
+	}
+	return
+}
+
+// redisLock set lock key with random owner and keep it alive until release,
+// so a long migration is not taken over by others and release never deletes the lock of others
+func redisLock(ops *Options) (release func() error, err error) {
+	b := make([]byte, 16)
+	_, err = rand.Read(b)
+	if err != nil {
+		return
+	}
+	owner := hex.EncodeToString(b)
+	expire := time.Duration(ops.lockExpire) * time.Second
+	for {
+		var ok bool
+		ok, err = ops.redis.SetNX(ops.ctx, ops.lockName, owner, expire).Result()
+		if err != nil {
+			log.
+				WithContext(ops.ctx).
+				WithError(err).
+				WithFields(log.Fields{
+					"migrate.lock": ops.lockName,
+				}).
+				Error("acquire redis lock for migration failed")
+			return
+		}
+		if ok {
+			break
+		}
+		log.
+			WithContext(ops.ctx).
+			WithFields(log.Fields{
+				"migrate.lock": ops.lockName,
+			}).
+			Info("cannot acquire redis lock, retrying...")
+		select {
+		case <-ops.ctx.Done():
+			err = ops.ctx.Err()
+			return
+		case <-time.After(time.Second):
+		}
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(expire / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				res, e := ops.redis.Eval(context.Background(), luaRenew, []string{ops.lockName}, owner, expire.Milliseconds()).Int64()
+				if e != nil {
+					// retry next tick
+					log.
+						WithContext(ops.ctx).
+						WithError(e).
+						WithFields(log.Fields{
+							"migrate.lock": ops.lockName,
+						}).
+						Warn("renew redis lock failed")
+					continue
+				}
+				if res == 0 {
+					log.
+						WithContext(ops.ctx).
+						WithFields(log.Fields{
+							"migrate.lock": ops.lockName,
+						}).
+						Error("redis lock is expired or taken by others")
+					return
+				}
+			}
+		}
+	}()
+	release = func() (e error) {
+		close(stop)
+		<-done
+		e = ops.redis.Eval(context.Background(), luaRelease, []string{ops.lockName}, owner).Err()
+		if e != nil {
+			log.
+				WithContext(ops.ctx).
+				WithError(e).
+				WithFields(log.Fields{
+					"migrate.lock": ops.lockName,
+				}).
+				Error("release redis lock for migration failed")
+		}
+		return
+	}
 	return
 }
 
@@ -138,6 +449,7 @@ func database(ops *Options) (err error) {
 	if err != nil {
 		return
 	}
+	defer db.Close()
 	_, err = db.Exec(strings.Join([]string{"CREATE DATABASE IF NOT EXISTS `", dbname, "`"}, ""))
 	if err != nil {
 		log.
@@ -148,11 +460,11 @@ func database(ops *Options) (err error) {
 	return
 }
 
-func acquireLock(ops *Options, db *sql.DB) (f bool, err error) {
+func acquireLock(ops *Options, conn *sql.Conn) (f bool, err error) {
 	// GET_LOCK will be blocked if another session already acquired the lock
 	// timeout 5s
 	q := strings.Join([]string{"SELECT GET_LOCK('", "', 5)"}, ops.lockName)
-	err = db.QueryRow(q).Scan(&f)
+	err = conn.QueryRowContext(ops.ctx, q).Scan(&f)
 
 	if err != nil {
 		log.
@@ -174,9 +486,10 @@ func acquireLock(ops *Options, db *sql.DB) (f bool, err error) {
 	return
 }
 
-func releaseLock(ops *Options, db *sql.DB) (err error) {
+func releaseLock(ops *Options, conn *sql.Conn) (err error) {
 	q := strings.Join([]string{"SELECT RELEASE_LOCK('", "')"}, ops.lockName)
-	_, err = db.Exec(q)
+	// release even if ctx is canceled, otherwise the lock is held until connection closed
+	_, err = conn.ExecContext(context.Background(), q)
 
 	if err != nil {
 		log.
@@ -198,7 +511,7 @@ func releaseLock(ops *Options, db *sql.DB) (err error) {
 	return
 }
 
-func status(ops *Options, db *sql.DB, source *migrate.EmbedFileSystemMigrationSource) (err error) {
+func status(ops *Options, db *sql.DB, dialect string, source migrate.MigrationSource) (applied, pending []string, err error) {
 	var migrations []*migrate.Migration
 	migrations, err = source.FindMigrations()
 	if err != nil {
@@ -210,7 +523,7 @@ func status(ops *Options, db *sql.DB, source *migrate.EmbedFileSystemMigrationSo
 	}
 
 	var records []*migrate.MigrationRecord
-	records, err = migrate.GetMigrationRecords(db, ops.driver)
+	records, err = migrate.GetMigrationRecords(db, dialect)
 	if err != nil {
 		log.
 			WithContext(ops.ctx).
@@ -219,8 +532,8 @@ func status(ops *Options, db *sql.DB, source *migrate.EmbedFileSystemMigrationSo
 		return
 	}
 	rows := make(map[string]bool)
-	pending := make([]string, 0)
-	applied := make([]string, 0)
+	pending = make([]string, 0)
+	applied = make([]string, 0)
 	for _, item := range migrations {
 		rows[item.Id] = false
 	}
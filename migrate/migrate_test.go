@@ -3,8 +3,12 @@ package migrate
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"testing"
+	"time"
 )
 
 //go:embed db/*.sql
@@ -25,3 +29,51 @@ func beforeMigrate(ctx context.Context) (err error) {
 	fmt.Println(ctx)
 	return
 }
+
+func TestRedisLock(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	ops := getOptionsOrSetDefault(nil)
+	WithRedis(client)(ops)
+	WithLockExpire(1)(ops)
+	release, err := lock(ops, nil, "mysql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner, _ := s.Get(ops.lockName)
+
+	// lock is renewed while migrating
+	s.SetTTL(ops.lockName, 100*time.Millisecond)
+	time.Sleep(500 * time.Millisecond)
+	if ttl := s.TTL(ops.lockName); ttl <= 100*time.Millisecond {
+		t.Fatalf("expect lock renewed but ttl is %s", ttl)
+	}
+
+	// other instance waits until ctx done
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	other := getOptionsOrSetDefault(nil)
+	WithCtx(ctx)(other)
+	WithRedis(client)(other)
+	if _, err = lock(other, nil, "mysql"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect deadline exceeded but got %v", err)
+	}
+
+	// lock taken by others is not released
+	_ = s.Set(ops.lockName, "other")
+	if err = release(); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := s.Get(ops.lockName); v != "other" {
+		t.Fatalf("unexpected lock owner %s", v)
+	}
+	s.Del(ops.lockName)
+	release, _ = lock(ops, nil, "mysql")
+	if v, _ := s.Get(ops.lockName); v == owner {
+		t.Fatal("expect new owner")
+	}
+	_ = release()
+	if s.Exists(ops.lockName) {
+		t.Fatal("expect lock released")
+	}
+}
@@ -3,6 +3,8 @@ package migrate
 import (
 	"context"
 	"embed"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 	"reflect"
 )
 
@@ -15,6 +17,12 @@ type Options struct {
 	changeTable string
 	fs          embed.FS
 	fsRoot      string
+	db          *gorm.DB
+	redis       redis.UniversalClient
+	lockExpire  int
+	dryRun      bool
+	down        int
+	version     int64
 }
 
 func WithCtx(ctx context.Context) func(*Options) {
@@ -69,13 +77,67 @@ func WithFsRoot(s string) func(*Options) {
 	}
 }
 
+// WithDB use the connection of gorm, mysql/postgres/sqlite/sqlserver are supported, WithDriver/WithUri will be ignored
+func WithDB(db *gorm.DB) func(*Options) {
+	return func(options *Options) {
+		if db != nil {
+			getOptionsOrSetDefault(options).db = db
+		}
+	}
+}
+
+// WithRedis use redis lock instead of mysql advisory lock, only one instance can migrate at the same time
+func WithRedis(rd redis.UniversalClient) func(*Options) {
+	return func(options *Options) {
+		if rd != nil {
+			getOptionsOrSetDefault(options).redis = rd
+		}
+	}
+}
+
+// WithLockExpire redis lock expire seconds, the lock is renewed every 1/3 expire until migration finished
+func WithLockExpire(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).lockExpire = second
+		}
+	}
+}
+
+// WithDryRun only print the planned migrations and sql, nothing will be executed
+func WithDryRun(flag bool) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).dryRun = flag
+	}
+}
+
+// WithDown rollback the latest n applied migrations by 'migrate Down' section
+func WithDown(n int) func(*Options) {
+	return func(options *Options) {
+		if n > 0 {
+			getOptionsOrSetDefault(options).down = n
+		}
+	}
+}
+
+// WithVersion migrate up or down to the specified version(numeric prefix of file name, e.g. 2022120710)
+func WithVersion(v int64) func(*Options) {
+	return func(options *Options) {
+		if v > 0 {
+			getOptionsOrSetDefault(options).version = v
+		}
+	}
+}
+
 func getOptionsOrSetDefault(options *Options) *Options {
 	if options == nil {
 		return &Options{
+			ctx:         context.Background(),
 			driver:      "mysql",
 			uri:         "root:root@tcp(127.0.0.1:3306)/test?charset=utf8mb4&parseTime=True&timeout=10000ms",
 			lockName:    "MigrationLock",
 			changeTable: "schema_migrations",
+			lockExpire:  600,
 		}
 	}
 	return options
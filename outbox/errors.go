@@ -0,0 +1,10 @@
+package outbox
+
+import "github.com/pkg/errors"
+
+var (
+	ErrDBNil     = errors.New("db is nil")
+	ErrWorkerNil = errors.New("worker is nil")
+	ErrTxNil     = errors.New("transaction is nil")
+	ErrGroupNil  = errors.New("task group is empty")
+)
@@ -0,0 +1,134 @@
+package outbox
+
+import (
+	"github.com/go-cinch/common/worker"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Enqueuer is implemented by worker.Worker
+type Enqueuer interface {
+	Once(options ...func(*worker.RunOptions)) error
+}
+
+type Options struct {
+	db          *gorm.DB
+	worker      Enqueuer
+	redis       redis.UniversalClient
+	table       string
+	lockKey     string
+	lockExpire  int
+	interval    int
+	batch       int
+	maxAttempts int
+	retention   int
+	carriers    []worker.Carrier
+}
+
+func WithDB(db *gorm.DB) func(*Options) {
+	return func(options *Options) {
+		if db != nil {
+			getOptionsOrSetDefault(options).db = db
+		}
+	}
+}
+
+func WithWorker(wk Enqueuer) func(*Options) {
+	return func(options *Options) {
+		if wk != nil {
+			getOptionsOrSetDefault(options).worker = wk
+		}
+	}
+}
+
+// WithRedis enable leader lock, only one relay instance polls the table at the same time
+func WithRedis(rd redis.UniversalClient) func(*Options) {
+	return func(options *Options) {
+		if rd != nil {
+			getOptionsOrSetDefault(options).redis = rd
+		}
+	}
+}
+
+func WithTable(table string) func(*Options) {
+	return func(options *Options) {
+		if table != "" {
+			getOptionsOrSetDefault(options).table = table
+		}
+	}
+}
+
+func WithLockKey(key string) func(*Options) {
+	return func(options *Options) {
+		if key != "" {
+			getOptionsOrSetDefault(options).lockKey = key
+		}
+	}
+}
+
+// WithLockExpire leader lock expire seconds, each batch stops at half of it and the lock is renewed between batches
+func WithLockExpire(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).lockExpire = second
+		}
+	}
+}
+
+func WithInterval(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).interval = second
+		}
+	}
+}
+
+func WithBatch(count int) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).batch = count
+		}
+	}
+}
+
+// WithMaxAttempts max enqueue attempts, the message will be marked as failed if exceeds, Retry can requeue it
+func WithMaxAttempts(count int) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).maxAttempts = count
+		}
+	}
+}
+
+// WithRetention done messages will be deleted after retention seconds, 0 means keep forever
+func WithRetention(second int) func(*Options) {
+	return func(options *Options) {
+		if second >= 0 {
+			getOptionsOrSetDefault(options).retention = second
+		}
+	}
+}
+
+// WithCarrier carry ctx values(request id, tenant id...) from Enqueue to task handler, the same as worker.WithCarrier
+func WithCarrier(c ...worker.Carrier) func(*Options) {
+	return func(options *Options) {
+		if len(c) > 0 {
+			getOptionsOrSetDefault(options).carriers = append(getOptionsOrSetDefault(options).carriers, c...)
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			table:       "outbox",
+			lockKey:     "outbox.relay",
+			lockExpire:  30,
+			interval:    1,
+			batch:       100,
+			maxAttempts: 10,
+			retention:   7 * 24 * 3600,
+		}
+	}
+	return options
+}
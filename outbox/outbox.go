@@ -0,0 +1,411 @@
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/go-cinch/common/log"
+	"github.com/go-cinch/common/worker"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"sync"
+	"time"
+)
+
+const (
+	StatusPending int8 = iota
+	StatusDone
+	StatusFailed
+)
+
+const maxErrorLen = 500
+
+const (
+	// renew the leader lock only if it is still owned by current relay
+	luaRenew = `
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('pexpire', KEYS[1], ARGV[2])
+end
+return 0
+`
+	// release the leader lock only if it is still owned by current relay
+	luaRelease = `
+if redis.call('get', KEYS[1]) == ARGV[1] then
+	return redis.call('del', KEYS[1])
+end
+return 0
+`
+)
+
+// Message is one row of outbox table
+type Message struct {
+	Id        uint64     `json:"id" gorm:"primaryKey;autoIncrement"`
+	Uid       string     `json:"uid" gorm:"size:64;uniqueIndex"`
+	Group     string     `json:"group" gorm:"size:100"`
+	Payload   string     `json:"payload" gorm:"type:text"`
+	Header    string     `json:"header" gorm:"type:text"`
+	RunAt     *time.Time `json:"runAt"`
+	MaxRetry  int        `json:"maxRetry"`
+	Timeout   int        `json:"timeout"`
+	Status    int8       `json:"status" gorm:"index:idx_outbox_status_next"`
+	Attempts  int        `json:"attempts"`
+	Error     string     `json:"error" gorm:"size:500"`
+	NextAt    time.Time  `json:"nextAt" gorm:"index:idx_outbox_status_next"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// Task is the worker once task written to outbox
+type Task struct {
+	// Uid is the worker task id, uuid will be generated if empty, the same uid will be only enqueued once
+	Uid     string
+	Group   string
+	Payload string
+	// In/At delay the task, At is used if both are set
+	In       time.Duration
+	At       *time.Time
+	MaxRetry int
+	Timeout  int
+}
+
+// Outbox write tasks in business transaction and relay them to worker,
+// the task will be enqueued at least once after transaction committed
+type Outbox struct {
+	ops       Options
+	owner     string // random value of leader lock
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	start     sync.Once
+	cleanLock sync.Mutex
+	cleanAt   time.Time
+}
+
+func New(options ...func(*Options)) (o *Outbox, err error) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.db == nil {
+		err = ErrDBNil
+		return
+	}
+	if ops.worker == nil {
+		err = ErrWorkerNil
+		return
+	}
+	b := make([]byte, 16)
+	_, err = rand.Read(b)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	o = &Outbox{
+		ops:    *ops,
+		owner:  hex.EncodeToString(b),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	return
+}
+
+// Migrate create or update outbox table
+func (o *Outbox) Migrate() (err error) {
+	err = o.ops.db.Table(o.ops.table).AutoMigrate(&Message{})
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+// Enqueue write task to outbox table, tx should be the business transaction,
+// ctx values are carried by tx.Statement.Context
+func (o *Outbox) Enqueue(tx *gorm.DB, task Task) (err error) {
+	if tx == nil {
+		err = ErrTxNil
+		return
+	}
+	if task.Group == "" {
+		err = ErrGroupNil
+		return
+	}
+	now := time.Now()
+	m := Message{
+		Uid:      task.Uid,
+		Group:    task.Group,
+		Payload:  task.Payload,
+		RunAt:    task.At,
+		MaxRetry: task.MaxRetry,
+		Timeout:  task.Timeout,
+		Status:   StatusPending,
+		NextAt:   now,
+	}
+	if m.Uid == "" {
+		m.Uid = uuid.NewString()
+	}
+	if m.RunAt == nil && task.In > 0 {
+		at := now.Add(task.In)
+		m.RunAt = &at
+	}
+	m.Header = o.inject(tx.Statement.Context)
+	err = tx.Table(o.ops.table).Create(&m).Error
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+// Start relay messages in background
+func (o *Outbox) Start() {
+	o.start.Do(func() {
+		o.wg.Add(1)
+		go o.loop()
+	})
+}
+
+// Stop relay and wait the running batch
+func (o *Outbox) Stop() {
+	o.cancel()
+	o.wg.Wait()
+}
+
+func (o *Outbox) loop() {
+	defer o.wg.Done()
+	ticker := time.NewTicker(time.Duration(o.ops.interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.ctx.Done():
+			return
+		case <-ticker.C:
+			o.tick()
+		}
+	}
+}
+
+// Retry requeue failed messages by id, all failed messages are requeued if ids is empty,
+// e.g. call it after worker redis is recovered
+func (o *Outbox) Retry(ctx context.Context, ids ...uint64) (count int64, err error) {
+	db := o.ops.db.
+		WithContext(ctx).
+		Table(o.ops.table).
+		Where("status = ?", StatusFailed)
+	if len(ids) > 0 {
+		db = db.Where("id IN ?", ids)
+	}
+	db = db.Updates(map[string]interface{}{
+		"status":   StatusPending,
+		"attempts": 0,
+		"next_at":  time.Now(),
+	})
+	err = db.Error
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	count = db.RowsAffected
+	return
+}
+
+func (o *Outbox) tick() {
+	ctx := o.ctx
+	if o.ops.redis != nil {
+		// only the leader relay
+		if !o.lock(ctx) {
+			return
+		}
+		defer o.unlock()
+	}
+	expire := time.Duration(o.ops.lockExpire) * time.Second
+	for ctx.Err() == nil {
+		// each batch stops before the leader lock expired
+		batchCtx, cancel := context.WithTimeout(ctx, expire/2)
+		count, err := o.Relay(batchCtx)
+		cancel()
+		if err != nil {
+			log.WithContext(ctx).WithError(err).Warn("relay outbox failed")
+			return
+		}
+		// continue if there may be more
+		if count < o.ops.batch {
+			break
+		}
+		if o.ops.redis != nil && !o.renew(ctx) {
+			return
+		}
+	}
+	o.clean(ctx)
+}
+
+func (o *Outbox) lock(ctx context.Context) bool {
+	ok, err := o.ops.redis.SetNX(ctx, o.ops.lockKey, o.owner, time.Duration(o.ops.lockExpire)*time.Second).Result()
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("acquire outbox relay lock failed")
+	}
+	return ok
+}
+
+// renew extend the leader lock, return false if it is expired or taken by others
+func (o *Outbox) renew(ctx context.Context) bool {
+	res, err := o.ops.redis.Eval(ctx, luaRenew, []string{o.ops.lockKey}, o.owner, o.ops.lockExpire*1000).Int64()
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("renew outbox relay lock failed")
+		return false
+	}
+	if res == 0 {
+		log.WithContext(ctx).Warn("outbox relay lock is expired or taken by others")
+		return false
+	}
+	return true
+}
+
+func (o *Outbox) unlock() {
+	err := o.ops.redis.Eval(context.Background(), luaRelease, []string{o.ops.lockKey}, o.owner).Err()
+	if err != nil {
+		log.WithError(err).Warn("release outbox relay lock failed")
+	}
+}
+
+// Relay enqueue one batch of pending messages to worker, return the count of messages read
+func (o *Outbox) Relay(ctx context.Context) (count int, err error) {
+	list := make([]Message, 0)
+	err = o.ops.db.
+		WithContext(ctx).
+		Table(o.ops.table).
+		Where("status = ? AND next_at <= ?", StatusPending, time.Now()).
+		Order("id").
+		Limit(o.ops.batch).
+		Find(&list).Error
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	count = len(list)
+	for _, item := range list {
+		if ctx.Err() != nil {
+			return
+		}
+		o.relay(ctx, item)
+	}
+	return
+}
+
+func (o *Outbox) relay(ctx context.Context, m Message) {
+	options := []func(*worker.RunOptions){
+		worker.WithRunUuid(m.Uid),
+		worker.WithRunGroup(m.Group),
+		worker.WithRunPayload(m.Payload),
+		worker.WithRunMaxRetry(m.MaxRetry),
+		worker.WithRunTimeout(m.Timeout),
+		worker.WithRunCtx(o.extract(ctx, m.Header)),
+	}
+	if m.RunAt != nil && m.RunAt.After(time.Now()) {
+		options = append(options, worker.WithRunAt(*m.RunAt))
+	} else {
+		options = append(options, worker.WithRunNow(true))
+	}
+	err := o.ops.worker.Once(options...)
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		// enqueued by last relay but not marked
+		err = nil
+	}
+	updates := map[string]interface{}{
+		"attempts": m.Attempts + 1,
+	}
+	if err == nil {
+		updates["status"] = StatusDone
+		updates["error"] = ""
+	} else {
+		msg := err.Error()
+		if len(msg) > maxErrorLen {
+			msg = msg[:maxErrorLen]
+		}
+		updates["error"] = msg
+		updates["next_at"] = time.Now().Add(backoff(m.Attempts + 1))
+		if m.Attempts+1 >= o.ops.maxAttempts {
+			updates["status"] = StatusFailed
+		}
+		log.WithContext(ctx).WithError(err).WithFields(log.Fields{
+			"uid":      m.Uid,
+			"group":    m.Group,
+			"attempts": m.Attempts + 1,
+		}).Warn("enqueue outbox message failed")
+	}
+	e := o.ops.db.
+		WithContext(context.Background()).
+		Table(o.ops.table).
+		Where("id = ? AND status = ?", m.Id, StatusPending).
+		Updates(updates).Error
+	if e != nil {
+		log.WithContext(ctx).WithError(e).WithFields(log.Fields{
+			"uid": m.Uid,
+		}).Warn("update outbox message failed")
+	}
+}
+
+// clean delete done messages which exceeded retention, at most once per minute
+func (o *Outbox) clean(ctx context.Context) {
+	if o.ops.retention == 0 {
+		return
+	}
+	o.cleanLock.Lock()
+	defer o.cleanLock.Unlock()
+	if time.Since(o.cleanAt) < time.Minute {
+		return
+	}
+	o.cleanAt = time.Now()
+	err := o.ops.db.
+		WithContext(ctx).
+		Table(o.ops.table).
+		Where("status = ? AND updated_at < ?", StatusDone, time.Now().Add(-time.Duration(o.ops.retention)*time.Second)).
+		Delete(&Message{}).Error
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("clean outbox failed")
+	}
+}
+
+func (o *Outbox) inject(ctx context.Context) (rp string) {
+	if ctx == nil || len(o.ops.carriers) == 0 {
+		return
+	}
+	header := make(map[string]string)
+	for _, item := range o.ops.carriers {
+		item.Inject(ctx, header)
+	}
+	if len(header) == 0 {
+		return
+	}
+	bs, _ := json.Marshal(header)
+	rp = string(bs)
+	return
+}
+
+func (o *Outbox) extract(ctx context.Context, h string) context.Context {
+	if h == "" {
+		return ctx
+	}
+	header := make(map[string]string)
+	if err := json.Unmarshal([]byte(h), &header); err != nil {
+		return ctx
+	}
+	for _, item := range o.ops.carriers {
+		ctx = item.Extract(ctx, header)
+	}
+	return ctx
+}
+
+// backoff 2^n seconds, max 10 minutes
+func backoff(attempts int) time.Duration {
+	if attempts > 10 {
+		return 10 * time.Minute
+	}
+	d := time.Duration(1<<attempts) * time.Second
+	if d > 10*time.Minute {
+		d = 10 * time.Minute
+	}
+	return d
+}
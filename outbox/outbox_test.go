@@ -0,0 +1,216 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-cinch/common/worker"
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"sync"
+	"testing"
+)
+
+type ctxKey struct{}
+
+// tenantCarrier record extracted values
+type tenantCarrier struct {
+	lock    sync.Mutex
+	tenants []string
+}
+
+func (c *tenantCarrier) Inject(ctx context.Context, header map[string]string) {
+	if v, ok := ctx.Value(ctxKey{}).(string); ok {
+		header["tenant"] = v
+	}
+}
+
+func (c *tenantCarrier) Extract(ctx context.Context, header map[string]string) context.Context {
+	c.lock.Lock()
+	c.tenants = append(c.tenants, header["tenant"])
+	c.lock.Unlock()
+	return context.WithValue(ctx, ctxKey{}, header["tenant"])
+}
+
+type memoryWorker struct {
+	lock  sync.Mutex
+	count int
+	err   error
+}
+
+func (m *memoryWorker) Once(options ...func(*worker.RunOptions)) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.count++
+	return nil
+}
+
+func newDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file:"+t.Name()+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestOutbox(t *testing.T) {
+	db := newDB(t)
+	wk := &memoryWorker{}
+	carrier := &tenantCarrier{}
+	_, err := New(WithDB(db))
+	if err != ErrWorkerNil {
+		t.Fatalf("expect worker nil but got %v", err)
+	}
+	o, err := New(WithDB(db), WithWorker(wk), WithCarrier(carrier), WithMaxAttempts(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = o.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "t1")
+
+	// rollback
+	_ = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		_ = o.Enqueue(tx, Task{Group: "order.created", Payload: "1"})
+		return errors.New("rollback")
+	})
+	// commit
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if e := o.Enqueue(tx, Task{Group: "order.created", Payload: "2"}); e != nil {
+			return e
+		}
+		return o.Enqueue(tx, Task{Uid: "order.3", Group: "order.created", Payload: "3"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = o.Enqueue(db, Task{}); err != ErrGroupNil {
+		t.Fatalf("expect group nil but got %v", err)
+	}
+
+	count, err := o.Relay(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 || wk.count != 2 {
+		t.Fatalf("unexpected relay count %d %d", count, wk.count)
+	}
+	if len(carrier.tenants) != 2 || carrier.tenants[0] != "t1" {
+		t.Fatalf("unexpected tenants %v", carrier.tenants)
+	}
+	var done int64
+	db.Table("outbox").Where("status = ?", StatusDone).Count(&done)
+	if done != 2 {
+		t.Fatalf("unexpected done count %d", done)
+	}
+	// done messages are not relayed again
+	count, _ = o.Relay(context.Background())
+	if count != 0 {
+		t.Fatalf("unexpected relay count %d", count)
+	}
+}
+
+func TestOutboxRetry(t *testing.T) {
+	db := newDB(t)
+	wk := &memoryWorker{err: errors.New("redis down")}
+	o, _ := New(WithDB(db), WithWorker(wk), WithMaxAttempts(2))
+	_ = o.Migrate()
+	_ = o.Enqueue(db, Task{Uid: "a", Group: "g"})
+	_ = o.Enqueue(db, Task{Uid: "b", Group: "g"})
+
+	_, _ = o.Relay(context.Background())
+	var m Message
+	db.Table("outbox").Where("uid = ?", "a").First(&m)
+	if m.Status != StatusPending || m.Attempts != 1 || m.Error != "redis down" {
+		t.Fatalf("unexpected message %+v", m)
+	}
+	// backoff
+	count, _ := o.Relay(context.Background())
+	if count != 0 {
+		t.Fatalf("unexpected relay count %d", count)
+	}
+	db.Table("outbox").Where("1 = 1").Update("next_at", m.CreatedAt)
+
+	// duplicate task id means enqueued but not marked
+	wk.err = asynq.ErrTaskIDConflict
+	_, _ = o.Relay(context.Background())
+	var list []Message
+	db.Table("outbox").Order("id").Find(&list)
+	if list[0].Status != StatusDone || list[1].Status != StatusDone {
+		t.Fatalf("unexpected messages %+v", list)
+	}
+
+	wk.err = errors.New("redis down")
+	_ = o.Enqueue(db, Task{Uid: "c", Group: "g"})
+	db.Table("outbox").Where("uid = ?", "c").Update("attempts", 1)
+	_, _ = o.Relay(context.Background())
+	var c Message
+	db.Table("outbox").Where("uid = ?", "c").First(&c)
+	if c.Status != StatusFailed || c.Attempts != 2 {
+		t.Fatalf("expect failed but got %+v", c)
+	}
+
+	// requeue failed messages after redis recovered
+	wk.err = nil
+	if n, err := o.Retry(context.Background(), c.Id+1); err != nil || n != 0 {
+		t.Fatalf("unexpected retry count %d, error = %v", n, err)
+	}
+	if n, err := o.Retry(context.Background()); err != nil || n != 1 {
+		t.Fatalf("unexpected retry count %d, error = %v", n, err)
+	}
+	_, _ = o.Relay(context.Background())
+	db.Table("outbox").Where("uid = ?", "c").First(&c)
+	if c.Status != StatusDone || c.Attempts != 1 {
+		t.Fatalf("expect done but got %+v", c)
+	}
+}
+
+func TestOutboxLeader(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	db := newDB(t)
+	wk := &memoryWorker{}
+	o, _ := New(WithDB(db), WithWorker(wk), WithRedis(client))
+	_ = o.Migrate()
+	_ = o.Enqueue(db, Task{Group: "g"})
+
+	// other instance is leader
+	s.Set("outbox.relay", "1")
+	o.tick()
+	if wk.count != 0 {
+		t.Fatalf("unexpected relay count %d", wk.count)
+	}
+	s.Del("outbox.relay")
+	o.tick()
+	if wk.count != 1 {
+		t.Fatalf("unexpected relay count %d", wk.count)
+	}
+	if s.Exists("outbox.relay") {
+		t.Fatal("lock should be released")
+	}
+
+	// lock taken by others is neither renewed nor released
+	if !o.lock(context.Background()) {
+		t.Fatal("expect lock acquired")
+	}
+	if !o.renew(context.Background()) {
+		t.Fatal("expect lock renewed")
+	}
+	s.Set("outbox.relay", "other")
+	if o.renew(context.Background()) {
+		t.Fatal("expect lock lost")
+	}
+	o.unlock()
+	if v, _ := s.Get("outbox.relay"); v != "other" {
+		t.Fatalf("unexpected lock owner %s", v)
+	}
+}
@@ -0,0 +1,102 @@
+package page
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Params is the page params from request, params.Page in github.com/go-cinch/common/proto/params is supported
+type Params interface {
+	GetNum() uint64
+	GetSize() uint64
+	GetTotal() int64
+	GetDisable() bool
+}
+
+// New create page with default num and size
+func New() *Page {
+	return &Page{
+		Num:  MinNum,
+		Size: Size,
+	}
+}
+
+// FromParams create page from request params(e.g. proto message)
+func FromParams(params Params) (page *Page) {
+	page = New()
+	if params == nil {
+		return
+	}
+	page.Bind(params)
+	return
+}
+
+// FromQuery create page from url query, both 'page.num' and 'num' style keys are supported
+func FromQuery(values url.Values) (page *Page) {
+	page = New()
+	page.BindQuery(values)
+	return
+}
+
+// Bind copy request params to page, zero values are ignored
+func (page *Page) Bind(params Params) *Page {
+	if params.GetNum() > 0 {
+		page.Num = params.GetNum()
+	}
+	if params.GetSize() > 0 {
+		page.Size = params.GetSize()
+	}
+	if params.GetTotal() > 0 {
+		page.Total = params.GetTotal()
+	}
+	page.Disable = params.GetDisable()
+	return page
+}
+
+// BindQuery copy url query to page, invalid values are ignored(Primary is not bound, it is concatenated into sql)
+func (page *Page) BindQuery(values url.Values) *Page {
+	if v, ok := queryUint(values, "num"); ok && v > 0 {
+		page.Num = v
+	}
+	if v, ok := queryUint(values, "size"); ok && v > 0 {
+		page.Size = v
+	}
+	if v, ok := queryUint(values, "total"); ok && v > 0 {
+		page.Total = int64(v)
+	}
+	if v, ok := queryBool(values, "disable"); ok {
+		page.Disable = v
+	}
+	if v, ok := queryBool(values, "count"); ok {
+		page.Count = v
+	}
+	return page
+}
+
+func query(values url.Values, key string) string {
+	if v := values.Get(strings.Join([]string{"page", key}, ".")); v != "" {
+		return v
+	}
+	return values.Get(key)
+}
+
+func queryUint(values url.Values, key string) (v uint64, ok bool) {
+	s := query(values, key)
+	if s == "" {
+		return
+	}
+	v, err := strconv.ParseUint(s, 10, 64)
+	ok = err == nil
+	return
+}
+
+func queryBool(values url.Values, key string) (v bool, ok bool) {
+	s := query(values, key)
+	if s == "" {
+		return
+	}
+	v, err := strconv.ParseBool(s)
+	ok = err == nil
+	return
+}
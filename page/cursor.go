@@ -0,0 +1,212 @@
+package page
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/go-cinch/common/log"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+	"reflect"
+)
+
+var (
+	ErrCursorInvalid = errors.New("invalid cursor")
+	ErrSortEmpty     = errors.New("sort columns are empty")
+	ErrSortColumn    = errors.New("sort column not found in model")
+)
+
+// Sort is the keyset column, the last one should be unique(e.g. primary key), otherwise rows may be skipped
+type Sort struct {
+	Column string `json:"column"`
+	Desc   bool   `json:"desc"`
+}
+
+// Cursor keyset pagination info, suitable for deep paging where OFFSET becomes slow
+type Cursor struct {
+	ctx     context.Context
+	sorts   []Sort
+	Size    uint64 `json:"size"`    // page per count
+	After   string `json:"after"`   // query rows after this cursor(use Next of last response)
+	Before  string `json:"before"`  // query rows before this cursor(use Prev of last response)
+	Next    string `json:"next"`    // cursor of next page, empty means no more data
+	Prev    string `json:"prev"`    // cursor of prev page, empty means no more data
+	HasNext bool   `json:"hasNext"` // has next page
+	HasPrev bool   `json:"hasPrev"` // has prev page
+}
+
+func (c *Cursor) WithContext(ctx context.Context) *Cursor {
+	c.ctx = ctx
+	return c
+}
+
+// OrderBy set the keyset columns, columns come from developer, do not pass request params directly
+func (c *Cursor) OrderBy(sorts ...Sort) *Cursor {
+	c.sorts = sorts
+	return c
+}
+
+// Find query one page by cursor, fill Next/Prev/HasNext/HasPrev
+func (c *Cursor) Find(db *gorm.DB, model interface{}) (err error) {
+	if c.ctx == nil {
+		c.ctx = context.Background()
+	}
+	ctx := c.ctx
+	rv := reflect.ValueOf(model)
+	if rv.Kind() != reflect.Ptr || (rv.IsNil() || rv.Elem().Kind() != reflect.Slice) {
+		log.WithContext(ctx).Warn("model must be a pointer")
+		err = errors.Errorf("model must be a pointer of slice")
+		return
+	}
+	if len(c.sorts) == 0 {
+		err = errors.WithStack(ErrSortEmpty)
+		return
+	}
+	stmt := &gorm.Statement{DB: db}
+	err = stmt.Parse(model)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	fields := make([]*schema.Field, len(c.sorts))
+	for i, item := range c.sorts {
+		// column can be field name or db name, always use db name in sql
+		fields[i] = stmt.Schema.LookUpField(item.Column)
+		if fields[i] == nil || fields[i].DBName == "" {
+			err = errors.Wrapf(ErrSortColumn, "column: %s", item.Column)
+			return
+		}
+	}
+
+	size := c.Size
+	if size < MinSize || size > MaxSize {
+		size = Size
+	}
+	c.Size = size
+	backward := c.Before != "" && c.After == ""
+	cursor := c.After
+	if backward {
+		cursor = c.Before
+	}
+	var values []interface{}
+	if cursor != "" {
+		values, err = decodeCursor(cursor, fields)
+		if err != nil {
+			return
+		}
+	}
+
+	tx := db.WithContext(ctx)
+	if len(values) > 0 {
+		tx = tx.Where(keyset(c.sorts, fields, values, backward))
+	}
+	for i, item := range c.sorts {
+		tx = tx.Order(clause.OrderByColumn{
+			Column: clause.Column{Name: fields[i].DBName},
+			// reverse order when query backward
+			Desc: item.Desc != backward,
+		})
+	}
+	// query one more row to check whether there is more data
+	tx = tx.Limit(int(size) + 1).Find(model)
+	if tx.Error != nil {
+		err = errors.WithStack(tx.Error)
+		return
+	}
+
+	list := rv.Elem()
+	more := uint64(list.Len()) > size
+	if more {
+		list.Set(list.Slice(0, int(size)))
+	}
+	if backward {
+		reverse(list)
+	}
+	c.HasNext, c.HasPrev = more, cursor != ""
+	if backward {
+		c.HasNext, c.HasPrev = cursor != "", more
+	}
+	c.Next, c.Prev = "", ""
+	if list.Len() == 0 {
+		return
+	}
+	if c.HasNext {
+		c.Next, err = encodeCursor(ctx, fields, list.Index(list.Len()-1))
+		if err != nil {
+			return
+		}
+	}
+	if c.HasPrev {
+		c.Prev, err = encodeCursor(ctx, fields, list.Index(0))
+	}
+	return
+}
+
+// keyset build where clause, e.g. sort by a desc, b asc:
+// (a < ?) OR (a = ? AND b > ?)
+func keyset(sorts []Sort, fields []*schema.Field, values []interface{}, backward bool) clause.Expression {
+	or := make([]clause.Expression, 0, len(sorts))
+	for i := range sorts {
+		and := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			and = append(and, clause.Eq{Column: clause.Column{Name: fields[j].DBName}, Value: values[j]})
+		}
+		column := clause.Column{Name: fields[i].DBName}
+		if sorts[i].Desc != backward {
+			and = append(and, clause.Lt{Column: column, Value: values[i]})
+		} else {
+			and = append(and, clause.Gt{Column: column, Value: values[i]})
+		}
+		or = append(or, clause.And(and...))
+	}
+	return clause.Or(or...)
+}
+
+func encodeCursor(ctx context.Context, fields []*schema.Field, row reflect.Value) (cursor string, err error) {
+	values := make([]interface{}, len(fields))
+	for i, field := range fields {
+		values[i], _ = field.ValueOf(ctx, reflect.Indirect(row))
+	}
+	bs, err := json.Marshal(values)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	cursor = base64.RawURLEncoding.EncodeToString(bs)
+	return
+}
+
+// decodeCursor restore values to the origin field type, avoid precision loss of big int
+func decodeCursor(cursor string, fields []*schema.Field) (values []interface{}, err error) {
+	bs, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		err = errors.WithStack(ErrCursorInvalid)
+		return
+	}
+	var raws []json.RawMessage
+	err = json.Unmarshal(bs, &raws)
+	if err != nil || len(raws) != len(fields) {
+		err = errors.WithStack(ErrCursorInvalid)
+		return
+	}
+	values = make([]interface{}, len(fields))
+	for i, field := range fields {
+		v := reflect.New(field.FieldType)
+		err = json.Unmarshal(raws[i], v.Interface())
+		if err != nil {
+			err = errors.WithStack(ErrCursorInvalid)
+			return
+		}
+		values[i] = v.Elem().Interface()
+	}
+	return
+}
+
+func reverse(list reflect.Value) {
+	swap := reflect.Swapper(list.Interface())
+	for i, j := 0, list.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
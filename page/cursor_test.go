@@ -0,0 +1,120 @@
+package page
+
+import (
+	"context"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type user struct {
+	Id        uint64
+	Name      string
+	CreatedAt time.Time
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		DSN:                       "root:root@tcp(127.0.0.1:3306)/test?parseTime=true",
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestKeyset(t *testing.T) {
+	db := newTestDB(t)
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&user{}); err != nil {
+		t.Fatal(err)
+	}
+	// field name or db name
+	sorts := []Sort{
+		{Column: "CreatedAt", Desc: true},
+		{Column: "id"},
+	}
+	fields := []*schema.Field{stmt.Schema.LookUpField("CreatedAt"), stmt.Schema.LookUpField("id")}
+	tests := []struct {
+		name     string
+		backward bool
+		want     string
+	}{
+		{
+			name: "forward",
+			want: "SELECT * FROM `users` WHERE (`created_at` < '2023-01-01 00:00:00' OR (`created_at` = '2023-01-01 00:00:00' AND `id` > 10))",
+		},
+		{
+			name:     "backward",
+			backward: true,
+			want:     "SELECT * FROM `users` WHERE (`created_at` > '2023-01-01 00:00:00' OR (`created_at` = '2023-01-01 00:00:00' AND `id` < 10))",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+				return tx.Where(keyset(sorts, fields, []interface{}{time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 10}, tt.backward)).Find(&[]user{})
+			})
+			if sql != tt.want {
+				t.Fatalf("keyset() = %s, want %s", sql, tt.want)
+			}
+		})
+	}
+}
+
+func TestCursor_Encode(t *testing.T) {
+	db := newTestDB(t)
+	c := &Cursor{}
+	c.OrderBy(Sort{Column: "created_at", Desc: true}, Sort{Column: "id"})
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&user{}); err != nil {
+		t.Fatal(err)
+	}
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	row := user{Id: 1<<60 + 1, Name: "a", CreatedAt: created}
+	fields := []*schema.Field{stmt.Schema.LookUpField("created_at"), stmt.Schema.LookUpField("id")}
+	cursor, err := encodeCursor(context.Background(), fields, reflect.ValueOf(row))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := decodeCursor(cursor, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !values[0].(time.Time).Equal(created) || values[1].(uint64) != row.Id {
+		t.Fatalf("decodeCursor() = %v", values)
+	}
+	if _, err = decodeCursor("invalid", fields); err == nil {
+		t.Fatal("decodeCursor() invalid cursor should fail")
+	}
+
+	list := make([]user, 0)
+	c.After = cursor
+	if err = c.Find(db.Model(&user{}), &list); err != nil {
+		t.Fatal(err)
+	}
+	if !c.HasPrev || c.HasNext || c.Size != Size {
+		t.Fatalf("Find() = %+v", c)
+	}
+	var sql string
+	_ = db.Callback().Query().After("gorm:query").Register("test:sql", func(tx *gorm.DB) {
+		sql = tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+	})
+	c = &Cursor{Size: 2}
+	if err = c.OrderBy(Sort{Column: "CreatedAt", Desc: true}, Sort{Column: "Id"}).Find(db.Model(&user{}), &list); err != nil {
+		t.Fatal(err)
+	}
+	if want := "SELECT * FROM `users` ORDER BY `created_at` DESC,`id` LIMIT 3"; sql != want {
+		t.Fatalf("Find() sql = %s, want %s", sql, want)
+	}
+	if err = c.OrderBy(Sort{Column: "unknown"}).Find(db, &list); err == nil {
+		t.Fatal("Find() unknown column should fail")
+	}
+}
@@ -6,11 +6,14 @@ replace github.com/go-cinch/common/log => ../log
 
 require (
 	github.com/go-cinch/common/log v1.0.4
+	github.com/pkg/errors v0.9.1
+	gorm.io/driver/mysql v1.5.1
 	gorm.io/gorm v1.25.2
 )
 
 require (
 	github.com/go-kratos/kratos/v2 v2.7.0 // indirect
+	github.com/go-sql-driver/mysql v1.7.0 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
 )
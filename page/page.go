@@ -33,11 +33,10 @@ func (page *Page) WithContext(ctx context.Context) *Page {
 
 func (page *Page) Query(db *gorm.DB) (rp *Query) {
 	rp = new(Query)
-	rp.db = db
 	if page.ctx == nil {
 		page.ctx = context.Background()
 	}
-	rp.db.WithContext(page.ctx)
+	rp.db = db.WithContext(page.ctx)
 	rp.page = page
 	return
 }
@@ -88,6 +87,34 @@ func (page *Page) Limit() (int, int) {
 	return int(limit), int(offset)
 }
 
+// Scope set limit/offset as gorm scope, Total should be set before if you need to fix Num, e.g. db.Scopes(page.Scope()).Find(&list)
+func (page *Page) Scope() func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if page.Disable {
+			return db
+		}
+		limit, offset := page.Limit()
+		return db.Limit(limit).Offset(offset)
+	}
+}
+
+// Reply is the standard response envelope of page data
+type Reply[T any] struct {
+	Page *Page `json:"page"`
+	List []T   `json:"list"`
+}
+
+// NewReply create page response envelope, nil list will be serialized as []
+func NewReply[T any](page *Page, list []T) *Reply[T] {
+	if list == nil {
+		list = make([]T, 0)
+	}
+	return &Reply[T]{
+		Page: page,
+		List: list,
+	}
+}
+
 type Query struct {
 	db   *gorm.DB
 	page *Page
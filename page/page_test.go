@@ -0,0 +1,98 @@
+package page
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+)
+
+type params struct {
+	num     uint64
+	size    uint64
+	total   int64
+	disable bool
+}
+
+func (p params) GetNum() uint64   { return p.num }
+func (p params) GetSize() uint64  { return p.size }
+func (p params) GetTotal() int64  { return p.total }
+func (p params) GetDisable() bool { return p.disable }
+
+func TestFromQuery(t *testing.T) {
+	values := url.Values{}
+	values.Set("page.num", "3")
+	values.Set("size", "20")
+	values.Set("count", "true")
+	values.Set("disable", "x")
+	values.Set("primary", "id")
+	p := FromQuery(values)
+	if p.Num != 3 || p.Size != 20 || !p.Count || p.Disable || p.Primary != "" {
+		t.Fatalf("FromQuery() = %+v", p)
+	}
+
+	p = FromQuery(url.Values{})
+	if p.Num != MinNum || p.Size != Size {
+		t.Fatalf("FromQuery() default = %+v", p)
+	}
+}
+
+func TestFromParams(t *testing.T) {
+	p := FromParams(params{num: 2, size: 5, disable: true})
+	if p.Num != 2 || p.Size != 5 || !p.Disable {
+		t.Fatalf("FromParams() = %+v", p)
+	}
+}
+
+func TestPage_Limit(t *testing.T) {
+	tests := []struct {
+		name   string
+		page   Page
+		limit  int
+		offset int
+		num    uint64
+	}{
+		{
+			name:   "first",
+			page:   Page{Num: 1, Size: 10, Total: 100},
+			limit:  10,
+			offset: 0,
+			num:    1,
+		},
+		{
+			name:   "middle",
+			page:   Page{Num: 3, Size: 10, Total: 100},
+			limit:  10,
+			offset: 20,
+			num:    3,
+		},
+		{
+			name:   "invalid size",
+			page:   Page{Num: 0, Size: MaxSize + 1, Total: 100},
+			limit:  int(Size),
+			offset: 0,
+			num:    1,
+		},
+		{
+			name:   "out of range",
+			page:   Page{Num: 20, Size: 10, Total: 100},
+			limit:  10,
+			offset: 100,
+			num:    11,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, offset := tt.page.Limit()
+			if limit != tt.limit || offset != tt.offset || tt.page.Num != tt.num {
+				t.Fatalf("Limit() = %d, %d, num = %d, want %d, %d, num = %d", limit, offset, tt.page.Num, tt.limit, tt.offset, tt.num)
+			}
+		})
+	}
+}
+
+func TestNewReply(t *testing.T) {
+	bs, _ := json.Marshal(NewReply[string](New(), nil))
+	if string(bs) != `{"page":{"num":1,"size":10,"total":0,"disable":false,"count":false,"primary":""},"list":[]}` {
+		t.Fatalf("NewReply() = %s", bs)
+	}
+}
@@ -6,6 +6,7 @@ replace (
 	github.com/go-cinch/common/log => ../../../log
 	github.com/go-cinch/common/migrate => ../../../migrate
 	github.com/go-cinch/common/plugins/gorm/log => ../../../plugins/gorm/log
+	github.com/go-cinch/common/tenant => ../../../tenant
 	github.com/go-cinch/common/utils => ../../../utils
 )
 
@@ -13,6 +14,7 @@ require (
 	github.com/go-cinch/common/log v1.0.4
 	github.com/go-cinch/common/migrate v1.0.4
 	github.com/go-cinch/common/plugins/gorm/log v1.0.4
+	github.com/go-cinch/common/tenant v1.0.4
 	github.com/go-cinch/common/utils v1.0.4
 	github.com/go-kratos/kratos/v2 v2.7.0
 	github.com/go-sql-driver/mysql v1.7.1
@@ -30,6 +32,7 @@ require (
 	github.com/r3labs/diff/v3 v3.0.1 // indirect
 	github.com/redis/go-redis/v9 v9.2.1 // indirect
 	github.com/rubenv/sql-migrate v1.5.1 // indirect
+	github.com/stretchr/testify v1.8.4 // indirect
 	github.com/vmihailenco/msgpack/v5 v5.3.5 // indirect
 	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
-)
\ No newline at end of file
+)
@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-cinch/common/log"
 	"github.com/go-cinch/common/migrate"
+	commonTenant "github.com/go-cinch/common/tenant"
 	"github.com/go-cinch/common/utils"
 	kratosLog "github.com/go-kratos/kratos/v2/log"
 	"github.com/go-sql-driver/mysql"
@@ -15,23 +16,16 @@ import (
 )
 
 func ID() kratosLog.Valuer {
-	return func(ctx context.Context) interface{} {
-		return FromContext(ctx)
-	}
+	return commonTenant.ID()
 }
 
-type tenantCtx struct{}
-
+// NewContext is the same as commonTenant.SetTenant, ctx is shared with common tenant package
 func NewContext(ctx context.Context, id string) context.Context {
-	ctx = context.WithValue(ctx, tenantCtx{}, id)
-	return ctx
+	return commonTenant.SetTenant(ctx, id)
 }
 
 func FromContext(ctx context.Context) (id string) {
-	if v, ok := ctx.Value(tenantCtx{}).(string); ok {
-		id = v
-	}
-	return
+	return commonTenant.FromContext(ctx)
 }
 
 type Tenant struct {
@@ -0,0 +1,276 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"filippo.io/age"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const (
+	SchemeAes = "aes"
+	SchemeAge = "age"
+)
+
+// marker ENC(scheme:base64), scheme is aes if omitted
+var marker = regexp.MustCompile(`ENC\(([A-Za-z0-9+/=:_-]*)\)`)
+
+// NewResolver decrypt ENC(...) markers of config values, plain values keep unchanged
+func NewResolver(options ...func(*Options)) func(map[string]interface{}) error {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	resolver := func(sub map[string]interface{}) error {
+		d := &decrypter{ops: *ops}
+		return d.resolveMap("", sub)
+	}
+	return resolver
+}
+
+// Chain run resolvers one by one, such as env.NewRevolver and crypto.NewResolver,
+// kratos config only accept one resolver
+func Chain(resolvers ...func(map[string]interface{}) error) func(map[string]interface{}) error {
+	return func(sub map[string]interface{}) error {
+		for _, r := range resolvers {
+			if r == nil {
+				continue
+			}
+			if err := r(sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// EncryptAes encrypt plaintext by aes-gcm, key is base64 of 16/24/32 bytes, return ENC(aes:...)
+func EncryptAes(key, plaintext string) (rp string, err error) {
+	gcm, err := newGcm(key)
+	if err != nil {
+		return
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	data := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	rp = "ENC(" + SchemeAes + ":" + base64.StdEncoding.EncodeToString(data) + ")"
+	return
+}
+
+// EncryptAge encrypt plaintext to age recipients(age1...), return ENC(age:...)
+func EncryptAge(plaintext string, recipients ...string) (rp string, err error) {
+	list := make([]age.Recipient, 0, len(recipients))
+	for _, item := range recipients {
+		var r *age.X25519Recipient
+		r, err = age.ParseX25519Recipient(item)
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+		list = append(list, r)
+	}
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, list...)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	_, _ = io.WriteString(w, plaintext)
+	if err = w.Close(); err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	rp = "ENC(" + SchemeAge + ":" + base64.StdEncoding.EncodeToString(buf.Bytes()) + ")"
+	return
+}
+
+// decrypter load keys lazily, config without markers does not need keys
+type decrypter struct {
+	ops        Options
+	gcm        cipher.AEAD
+	identities []age.Identity
+}
+
+func (d *decrypter) resolveMap(prefix string, sub map[string]interface{}) (err error) {
+	for k, v := range sub {
+		key := join(prefix, k)
+		var v1 interface{}
+		v1, err = d.resolve(key, v)
+		if err != nil {
+			return
+		}
+		sub[k] = v1
+	}
+	return
+}
+
+func (d *decrypter) resolve(key string, v interface{}) (rp interface{}, err error) {
+	rp = v
+	switch vt := v.(type) {
+	case string:
+		if !strings.Contains(vt, "ENC(") {
+			return
+		}
+		var s string
+		s, err = d.replace(vt)
+		if err != nil {
+			err = errors.WithMessagef(err, "decrypt %s failed", key)
+			return
+		}
+		rp = s
+		if d.ops.loaded != nil {
+			d.ops.loaded(key)
+		}
+	case map[string]interface{}:
+		err = d.resolveMap(key, vt)
+	case []interface{}:
+		for i, item := range vt {
+			vt[i], err = d.resolve(join(key, strconv.Itoa(i)), item)
+			if err != nil {
+				return
+			}
+		}
+	}
+	return
+}
+
+func (d *decrypter) replace(s string) (rp string, err error) {
+	rp = marker.ReplaceAllStringFunc(s, func(m string) string {
+		if err != nil {
+			return m
+		}
+		var plain string
+		plain, err = d.decrypt(marker.FindStringSubmatch(m)[1])
+		return plain
+	})
+	return
+}
+
+func (d *decrypter) decrypt(s string) (rp string, err error) {
+	scheme := SchemeAes
+	if i := strings.Index(s, ":"); i >= 0 {
+		scheme = s[:i]
+		s = s[i+1:]
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		err = errors.WithStack(ErrCiphertext)
+		return
+	}
+	switch scheme {
+	case SchemeAes:
+		rp, err = d.decryptAes(data)
+	case SchemeAge:
+		rp, err = d.decryptAge(data)
+	default:
+		err = errors.Wrap(ErrUnknownScheme, scheme)
+	}
+	return
+}
+
+func (d *decrypter) decryptAes(data []byte) (rp string, err error) {
+	if d.gcm == nil {
+		key := d.ops.aesKey
+		if key == "" {
+			key, _ = syscall.Getenv(d.ops.aesKeyEnv)
+		}
+		if key == "" {
+			err = ErrAesKeyNil
+			return
+		}
+		d.gcm, err = newGcm(key)
+		if err != nil {
+			return
+		}
+	}
+	size := d.gcm.NonceSize()
+	if len(data) < size {
+		err = errors.WithStack(ErrCiphertext)
+		return
+	}
+	plain, err := d.gcm.Open(nil, data[:size], data[size:], nil)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	rp = string(plain)
+	return
+}
+
+func (d *decrypter) decryptAge(data []byte) (rp string, err error) {
+	if len(d.identities) == 0 {
+		keys := d.ops.ageIdentity
+		if keys == "" {
+			keys, _ = syscall.Getenv(d.ops.ageKeyEnv)
+		}
+		if keys == "" {
+			if file, ok := syscall.Getenv(d.ops.ageKeyFileEnv); ok && file != "" {
+				var bs []byte
+				bs, err = os.ReadFile(file)
+				if err != nil {
+					err = errors.WithStack(err)
+					return
+				}
+				keys = string(bs)
+			}
+		}
+		if keys == "" {
+			err = ErrAgeKeyNil
+			return
+		}
+		d.identities, err = age.ParseIdentities(strings.NewReader(keys))
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), d.identities...)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	rp = string(plain)
+	return
+}
+
+func newGcm(key string) (gcm cipher.AEAD, err error) {
+	bs, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		err = ErrAesKeyInvalid
+		return
+	}
+	block, err := aes.NewCipher(bs)
+	if err != nil {
+		err = ErrAesKeyInvalid
+		return
+	}
+	gcm, err = cipher.NewGCM(block)
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+func join(prefix, k string) string {
+	if prefix == "" {
+		return k
+	}
+	return prefix + "." + k
+}
@@ -0,0 +1,206 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"filippo.io/age"
+	"github.com/go-cinch/common/plugins/kratos/config/env"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func newAesKey(t *testing.T) string {
+	bs := make([]byte, 32)
+	if _, err := rand.Read(bs); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(bs)
+}
+
+func TestResolver(t *testing.T) {
+	key := newAesKey(t)
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := EncryptAes(key, "secret1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := EncryptAge("secret2", identity.Recipient().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// scheme is aes if omitted
+	c := strings.Replace(a, "ENC(aes:", "ENC(", 1)
+	sub := map[string]interface{}{
+		"plain": "ENC is not a marker",
+		"port":  8080,
+		"db": map[string]interface{}{
+			"password": a,
+			"nested": map[string]interface{}{
+				"token": b,
+			},
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"key": c},
+			"user:" + a + "@" + b,
+		},
+	}
+	loaded := make([]string, 0)
+	err = NewResolver(
+		WithAesKey(key),
+		WithAgeIdentity(identity.String()),
+		WithLoaded(func(k string) {
+			loaded = append(loaded, k)
+		}),
+	)(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := sub["db"].(map[string]interface{})
+	servers := sub["servers"].([]interface{})
+	if db["password"] != "secret1" ||
+		db["nested"].(map[string]interface{})["token"] != "secret2" ||
+		servers[0].(map[string]interface{})["key"] != "secret1" ||
+		servers[1] != "user:secret1@secret2" ||
+		sub["plain"] != "ENC is not a marker" ||
+		sub["port"] != 8080 {
+		t.Fatalf("unexpected config %v", sub)
+	}
+	sort.Strings(loaded)
+	if strings.Join(loaded, ",") != "db.nested.token,db.password,servers.0.key,servers.1" {
+		t.Fatalf("unexpected loaded keys %v", loaded)
+	}
+}
+
+func TestResolver_Error(t *testing.T) {
+	key := newAesKey(t)
+	a, _ := EncryptAes(key, "secret1")
+	identity, _ := age.GenerateX25519Identity()
+	b, _ := EncryptAge("secret2", identity.Recipient().String())
+
+	// flip one byte of ciphertext, gcm authentication must fail
+	data, _ := base64.StdEncoding.DecodeString(strings.TrimSuffix(strings.TrimPrefix(a, "ENC(aes:"), ")"))
+	data[len(data)-1] ^= 0xff
+	tampered := "ENC(aes:" + base64.StdEncoding.EncodeToString(data) + ")"
+
+	other := newAesKey(t)
+	tests := []struct {
+		name    string
+		value   string
+		options []func(*Options)
+		want    error
+	}{
+		{
+			name:    "unknown scheme",
+			value:   "ENC(rsa:" + base64.StdEncoding.EncodeToString([]byte("x")) + ")",
+			options: []func(*Options){WithAesKey(key)},
+			want:    ErrUnknownScheme,
+		},
+		{
+			name:    "invalid base64",
+			value:   "ENC(aes:abc)",
+			options: []func(*Options){WithAesKey(key)},
+			want:    ErrCiphertext,
+		},
+		{
+			name:    "aes key missing",
+			value:   a,
+			options: []func(*Options){WithAesKeyEnv("CRYPTO_TEST_AES_KEY")},
+			want:    ErrAesKeyNil,
+		},
+		{
+			name:    "aes key invalid",
+			value:   a,
+			options: []func(*Options){WithAesKey("invalid")},
+			want:    ErrAesKeyInvalid,
+		},
+		{
+			name:    "age key missing",
+			value:   b,
+			options: []func(*Options){WithAgeKeyEnv("CRYPTO_TEST_AGE_KEY"), WithAgeKeyFileEnv("CRYPTO_TEST_AGE_KEY_FILE")},
+			want:    ErrAgeKeyNil,
+		},
+		{
+			name:    "tampered",
+			value:   tampered,
+			options: []func(*Options){WithAesKey(key)},
+		},
+		{
+			name:    "wrong key",
+			value:   a,
+			options: []func(*Options){WithAesKey(other)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := map[string]interface{}{
+				"db": map[string]interface{}{"password": tt.value},
+			}
+			err := NewResolver(tt.options...)(sub)
+			if err == nil {
+				t.Fatalf("expect error but got %v", sub)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("expect %v but got %v", tt.want, err)
+			}
+			if !strings.Contains(err.Error(), "db.password") {
+				t.Fatalf("expect key path in error but got %v", err)
+			}
+		})
+	}
+}
+
+func TestResolver_Env(t *testing.T) {
+	key := newAesKey(t)
+	a, _ := EncryptAes(key, "secret1")
+	t.Setenv("CRYPTO_TEST_AES_KEY", key)
+	t.Setenv("AUTH_DB_PASSWORD", a)
+
+	// env first, then decrypt the value from env
+	sub := map[string]interface{}{
+		"db": map[string]interface{}{"password": "plain"},
+	}
+	err := Chain(
+		env.NewRevolver(env.WithPrefix("AUTH")),
+		nil,
+		NewResolver(WithAesKeyEnv("CRYPTO_TEST_AES_KEY")),
+	)(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := sub["db"].(map[string]interface{})["password"]; v != "secret1" {
+		t.Fatalf("unexpected password %v", v)
+	}
+
+	// decrypt first, the env value is still encrypted
+	sub = map[string]interface{}{
+		"db": map[string]interface{}{"password": "plain"},
+	}
+	err = Chain(
+		NewResolver(WithAesKeyEnv("CRYPTO_TEST_AES_KEY")),
+		env.NewRevolver(env.WithPrefix("AUTH")),
+	)(sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := sub["db"].(map[string]interface{})["password"]; v != a {
+		t.Fatalf("unexpected password %v", v)
+	}
+
+	// stop at the first error
+	called := false
+	err = Chain(
+		NewResolver(WithAesKeyEnv("CRYPTO_TEST_AES_KEY_NOT_FOUND")),
+		func(map[string]interface{}) error {
+			called = true
+			return nil
+		},
+	)(map[string]interface{}{"password": a})
+	if !errors.Is(err, ErrAesKeyNil) || called {
+		t.Fatalf("expect aes key nil and stop but got %v, called %v", err, called)
+	}
+}
@@ -0,0 +1,11 @@
+package crypto
+
+import "github.com/pkg/errors"
+
+var (
+	ErrAesKeyNil     = errors.New("aes key is empty")
+	ErrAesKeyInvalid = errors.New("aes key must be base64 of 16/24/32 bytes")
+	ErrAgeKeyNil     = errors.New("age identity is empty")
+	ErrCiphertext    = errors.New("invalid ciphertext")
+	ErrUnknownScheme = errors.New("unknown encryption scheme")
+)
@@ -0,0 +1,22 @@
+module github.com/go-cinch/common/plugins/kratos/config/crypto
+
+go 1.20
+
+replace (
+	github.com/go-cinch/common/copierx => ../../../../copierx
+	github.com/go-cinch/common/plugins/kratos/config/env => ../env
+)
+
+require (
+	filippo.io/age v1.1.1
+	github.com/go-cinch/common/plugins/kratos/config/env v1.0.4
+	github.com/pkg/errors v0.9.1
+)
+
+require (
+	github.com/go-cinch/common/copierx v1.0.3 // indirect
+	github.com/golang-module/carbon/v2 v2.2.8 // indirect
+	github.com/jinzhu/copier v0.4.0 // indirect
+	golang.org/x/crypto v0.4.0 // indirect
+	golang.org/x/sys v0.3.0 // indirect
+)
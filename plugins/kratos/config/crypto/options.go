@@ -0,0 +1,66 @@
+package crypto
+
+type Options struct {
+	aesKey        string
+	aesKeyEnv     string
+	ageIdentity   string
+	ageKeyEnv     string
+	ageKeyFileEnv string
+	loaded        func(string)
+}
+
+// WithAesKey base64 of 16/24/32 bytes aes key, env is used if empty
+func WithAesKey(s string) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).aesKey = s
+	}
+}
+
+func WithAesKeyEnv(s string) func(*Options) {
+	return func(options *Options) {
+		if s != "" {
+			getOptionsOrSetDefault(options).aesKeyEnv = s
+		}
+	}
+}
+
+// WithAgeIdentity age secret key(AGE-SECRET-KEY-...), multiple keys are separated by newline, env is used if empty
+func WithAgeIdentity(s string) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).ageIdentity = s
+	}
+}
+
+func WithAgeKeyEnv(s string) func(*Options) {
+	return func(options *Options) {
+		if s != "" {
+			getOptionsOrSetDefault(options).ageKeyEnv = s
+		}
+	}
+}
+
+func WithAgeKeyFileEnv(s string) func(*Options) {
+	return func(options *Options) {
+		if s != "" {
+			getOptionsOrSetDefault(options).ageKeyFileEnv = s
+		}
+	}
+}
+
+// WithLoaded called with the key path(a.b.0.c) of each decrypted value
+func WithLoaded(f func(k string)) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).loaded = f
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			aesKeyEnv:     "CONFIG_AES_KEY",
+			ageKeyEnv:     "SOPS_AGE_KEY",
+			ageKeyFileEnv: "SOPS_AGE_KEY_FILE",
+		}
+	}
+	return options
+}
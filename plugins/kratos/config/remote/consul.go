@@ -0,0 +1,91 @@
+package remote
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Consul read config from consul kv, watch by blocking query
+type Consul struct {
+	ops ConsulOptions
+}
+
+func NewConsul(options ...func(*ConsulOptions)) (c *Consul, err error) {
+	ops := getConsulOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	ops.key = strings.Trim(ops.key, "/")
+	if ops.key == "" {
+		err = ErrKeyNil
+		return
+	}
+	c = &Consul{
+		ops: *ops,
+	}
+	return
+}
+
+func (c *Consul) Key() string {
+	return c.ops.key
+}
+
+func (c *Consul) Get(ctx context.Context) (data []byte, version string, err error) {
+	return c.get(ctx, "")
+}
+
+// Wait version is X-Consul-Index, consul returns the same index if nothing changed in wait time
+func (c *Consul) Wait(ctx context.Context, version string) (data []byte, newVersion string, err error) {
+	// consul adds up to wait/16 jitter
+	ctx, cancel := context.WithTimeout(ctx, c.ops.wait+c.ops.wait/16+waitMargin)
+	defer cancel()
+	return c.get(ctx, version)
+}
+
+func (c *Consul) get(ctx context.Context, index string) (data []byte, version string, err error) {
+	params := url.Values{}
+	params.Set("raw", "")
+	if c.ops.datacenter != "" {
+		params.Set("dc", c.ops.datacenter)
+	}
+	if index != "" {
+		params.Set("index", index)
+		params.Set("wait", c.ops.wait.String())
+	}
+	u := strings.TrimSuffix(c.ops.addr, "/") + "/v1/kv/" + c.ops.key + "?" + params.Encode()
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	if c.ops.token != "" {
+		r.Header.Set("X-Consul-Token", c.ops.token)
+	}
+	res, err := c.ops.client.Do(r)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		err = errors.Wrap(ErrNotFound, c.ops.key)
+		return
+	default:
+		err = errors.Wrapf(ErrInvalidStatusCode, "%d %s", res.StatusCode, body)
+		return
+	}
+	data = body
+	version = res.Header.Get("X-Consul-Index")
+	return
+}
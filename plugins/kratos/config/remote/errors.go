@@ -0,0 +1,12 @@
+package remote
+
+import "github.com/pkg/errors"
+
+var (
+	ErrProviderNil       = errors.New("provider is nil")
+	ErrKeyNil            = errors.New("config key is empty")
+	ErrDataIdNil         = errors.New("nacos data id is empty")
+	ErrNotFound          = errors.New("config not found")
+	ErrInvalidStatusCode = errors.New("invalid status code")
+	ErrCacheDirInvalid   = errors.New("cache dir must be owned by current user with mode 0700")
+)
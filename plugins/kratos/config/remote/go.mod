@@ -0,0 +1,17 @@
+module github.com/go-cinch/common/plugins/kratos/config/remote
+
+go 1.20
+
+replace github.com/go-cinch/common/log => ../../../../log
+
+require (
+	github.com/go-cinch/common/log v1.0.4
+	github.com/go-kratos/kratos/v2 v2.7.0
+	github.com/pkg/errors v0.9.1
+)
+
+require (
+	github.com/imdario/mergo v0.3.16 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,198 @@
+package remote
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Nacos read config from nacos open api v1, watch by long polling listener
+type Nacos struct {
+	ops    NacosOptions
+	lock   sync.Mutex
+	token  string
+	expire time.Time
+}
+
+type nacosToken struct {
+	AccessToken string `json:"accessToken"`
+	TokenTtl    int64  `json:"tokenTtl"`
+}
+
+func NewNacos(options ...func(*NacosOptions)) (n *Nacos, err error) {
+	ops := getNacosOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.dataId == "" {
+		err = ErrDataIdNil
+		return
+	}
+	n = &Nacos{
+		ops: *ops,
+	}
+	return
+}
+
+// Key is namespace/group/dataId, namespace is omitted if empty
+func (n *Nacos) Key() string {
+	list := []string{n.ops.group, n.ops.dataId}
+	if n.ops.namespace != "" {
+		list = append([]string{n.ops.namespace}, list...)
+	}
+	return strings.Join(list, "/")
+}
+
+// Get version is md5 of content, the same as nacos
+func (n *Nacos) Get(ctx context.Context) (data []byte, version string, err error) {
+	params := n.params()
+	err = n.auth(ctx, params)
+	if err != nil {
+		return
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, n.url("/nacos/v1/cs/configs")+"?"+params.Encode(), nil)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	data, err = n.do(r)
+	if err != nil {
+		return
+	}
+	sum := md5.Sum(data)
+	version = hex.EncodeToString(sum[:])
+	return
+}
+
+// Wait nacos listener responds changed data ids or empty after long polling timeout
+func (n *Nacos) Wait(ctx context.Context, version string) (data []byte, newVersion string, err error) {
+	ctx, cancel := context.WithTimeout(ctx, n.ops.timeout+waitMargin)
+	defer cancel()
+	params := url.Values{}
+	err = n.auth(ctx, params)
+	if err != nil {
+		return
+	}
+	// dataId^2group^2md5^2tenant^1
+	item := []string{n.ops.dataId, n.ops.group, version}
+	if n.ops.namespace != "" {
+		item = append(item, n.ops.namespace)
+	}
+	form := url.Values{}
+	form.Set("Listening-Configs", strings.Join(item, "\x02")+"\x01")
+	u := n.url("/nacos/v1/cs/configs/listener")
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Long-Pulling-Timeout", strconv.FormatInt(n.ops.timeout.Milliseconds(), 10))
+	body, err := n.do(r)
+	if err != nil {
+		return
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		newVersion = version
+		return
+	}
+	return n.Get(ctx)
+}
+
+func (n *Nacos) params() url.Values {
+	params := url.Values{}
+	params.Set("dataId", n.ops.dataId)
+	params.Set("group", n.ops.group)
+	if n.ops.namespace != "" {
+		params.Set("tenant", n.ops.namespace)
+	}
+	return params
+}
+
+// auth login and set accessToken to params, token is cached until expired
+func (n *Nacos) auth(ctx context.Context, params url.Values) (err error) {
+	if n.ops.username == "" {
+		return
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if n.token == "" || time.Now().After(n.expire) {
+		form := url.Values{}
+		form.Set("username", n.ops.username)
+		form.Set("password", n.ops.password)
+		var r *http.Request
+		r, err = http.NewRequestWithContext(ctx, http.MethodPost, n.url("/nacos/v1/auth/login"), strings.NewReader(form.Encode()))
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		var body []byte
+		body, _, err = n.send(r)
+		if err != nil {
+			err = errors.WithMessage(err, "nacos login failed")
+			return
+		}
+		var t nacosToken
+		err = json.Unmarshal(body, &t)
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+		n.token = t.AccessToken
+		// refresh before expired
+		n.expire = time.Now().Add(time.Duration(t.TokenTtl) * time.Second * 9 / 10)
+	}
+	params.Set("accessToken", n.token)
+	return
+}
+
+func (n *Nacos) do(r *http.Request) (body []byte, err error) {
+	body, code, err := n.send(r)
+	if code == http.StatusForbidden {
+		// token may be expired, login again next time
+		n.lock.Lock()
+		n.token = ""
+		n.lock.Unlock()
+	}
+	return
+}
+
+func (n *Nacos) send(r *http.Request) (body []byte, code int, err error) {
+	res, err := n.ops.client.Do(r)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	defer res.Body.Close()
+	code = res.StatusCode
+	body, err = io.ReadAll(res.Body)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	switch code {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		err = errors.Wrap(ErrNotFound, n.Key())
+	default:
+		err = errors.Wrapf(ErrInvalidStatusCode, "%d %s", code, body)
+	}
+	return
+}
+
+func (n *Nacos) url(path string) string {
+	return strings.TrimSuffix(n.ops.addr, "/") + path
+}
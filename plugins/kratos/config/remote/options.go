@@ -0,0 +1,192 @@
+package remote
+
+import (
+	"net/http"
+	"time"
+)
+
+type Options struct {
+	format   string
+	cacheDir string
+	timeout  time.Duration
+}
+
+// WithFormat config format, e.g. yaml/json, default is extension of key
+func WithFormat(s string) func(*Options) {
+	return func(options *Options) {
+		if s != "" {
+			getOptionsOrSetDefault(options).format = s
+		}
+	}
+}
+
+// WithCacheDir local fallback cache dir, the last loaded config is used if remote is unavailable, disabled by default,
+// the dir must be owned by current user with mode 0700, it is created if not exists
+func WithCacheDir(s string) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).cacheDir = s
+	}
+}
+
+// WithTimeout timeout of Load
+func WithTimeout(timeout time.Duration) func(*Options) {
+	return func(options *Options) {
+		if timeout > 0 {
+			getOptionsOrSetDefault(options).timeout = timeout
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			timeout: 10 * time.Second,
+		}
+	}
+	return options
+}
+
+type ConsulOptions struct {
+	addr       string
+	key        string
+	token      string
+	datacenter string
+	wait       time.Duration // max blocking time of watch
+	client     *http.Client
+}
+
+func WithConsulAddr(addr string) func(*ConsulOptions) {
+	return func(options *ConsulOptions) {
+		if addr != "" {
+			getConsulOptionsOrSetDefault(options).addr = addr
+		}
+	}
+}
+
+// WithConsulKey kv key, e.g. app/config.yaml
+func WithConsulKey(key string) func(*ConsulOptions) {
+	return func(options *ConsulOptions) {
+		getConsulOptionsOrSetDefault(options).key = key
+	}
+}
+
+// WithConsulToken acl token
+func WithConsulToken(token string) func(*ConsulOptions) {
+	return func(options *ConsulOptions) {
+		getConsulOptionsOrSetDefault(options).token = token
+	}
+}
+
+func WithConsulDatacenter(dc string) func(*ConsulOptions) {
+	return func(options *ConsulOptions) {
+		getConsulOptionsOrSetDefault(options).datacenter = dc
+	}
+}
+
+// WithConsulWait max blocking time of watch, consul limits it to 10m
+func WithConsulWait(wait time.Duration) func(*ConsulOptions) {
+	return func(options *ConsulOptions) {
+		if wait > 0 {
+			getConsulOptionsOrSetDefault(options).wait = wait
+		}
+	}
+}
+
+// WithConsulClient custom http client, the timeout should be greater than wait
+func WithConsulClient(client *http.Client) func(*ConsulOptions) {
+	return func(options *ConsulOptions) {
+		if client != nil {
+			getConsulOptionsOrSetDefault(options).client = client
+		}
+	}
+}
+
+func getConsulOptionsOrSetDefault(options *ConsulOptions) *ConsulOptions {
+	if options == nil {
+		return &ConsulOptions{
+			addr:   "http://127.0.0.1:8500",
+			wait:   5 * time.Minute,
+			client: &http.Client{},
+		}
+	}
+	return options
+}
+
+type NacosOptions struct {
+	addr      string
+	dataId    string
+	group     string
+	namespace string
+	username  string
+	password  string
+	timeout   time.Duration // long polling timeout of watch
+	client    *http.Client
+}
+
+func WithNacosAddr(addr string) func(*NacosOptions) {
+	return func(options *NacosOptions) {
+		if addr != "" {
+			getNacosOptionsOrSetDefault(options).addr = addr
+		}
+	}
+}
+
+// WithNacosDataId data id, e.g. app.yaml
+func WithNacosDataId(dataId string) func(*NacosOptions) {
+	return func(options *NacosOptions) {
+		getNacosOptionsOrSetDefault(options).dataId = dataId
+	}
+}
+
+func WithNacosGroup(group string) func(*NacosOptions) {
+	return func(options *NacosOptions) {
+		if group != "" {
+			getNacosOptionsOrSetDefault(options).group = group
+		}
+	}
+}
+
+// WithNacosNamespace namespace id(tenant), default is public
+func WithNacosNamespace(namespace string) func(*NacosOptions) {
+	return func(options *NacosOptions) {
+		getNacosOptionsOrSetDefault(options).namespace = namespace
+	}
+}
+
+// WithNacosAuth username and password if auth is enabled
+func WithNacosAuth(username, password string) func(*NacosOptions) {
+	return func(options *NacosOptions) {
+		getNacosOptionsOrSetDefault(options).username = username
+		getNacosOptionsOrSetDefault(options).password = password
+	}
+}
+
+// WithNacosTimeout long polling timeout of watch
+func WithNacosTimeout(timeout time.Duration) func(*NacosOptions) {
+	return func(options *NacosOptions) {
+		if timeout > 0 {
+			getNacosOptionsOrSetDefault(options).timeout = timeout
+		}
+	}
+}
+
+// WithNacosClient custom http client, the timeout should be greater than long polling timeout
+func WithNacosClient(client *http.Client) func(*NacosOptions) {
+	return func(options *NacosOptions) {
+		if client != nil {
+			getNacosOptionsOrSetDefault(options).client = client
+		}
+	}
+}
+
+func getNacosOptionsOrSetDefault(options *NacosOptions) *NacosOptions {
+	if options == nil {
+		return &NacosOptions{
+			addr:    "http://127.0.0.1:8848",
+			group:   "DEFAULT_GROUP",
+			timeout: 30 * time.Second,
+			client:  &http.Client{},
+		}
+	}
+	return options
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package remote
+
+import (
+	"os"
+	"syscall"
+)
+
+// owned check the file is owned by current user and only accessible by owner
+func owned(info os.FileInfo) bool {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return false
+	}
+	return int(st.Uid) == os.Getuid() && info.Mode().Perm() == 0o700
+}
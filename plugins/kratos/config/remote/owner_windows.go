@@ -0,0 +1,8 @@
+package remote
+
+import "os"
+
+// owned windows has no unix owner and mode, protect the cache dir by acl
+func owned(info os.FileInfo) bool {
+	return true
+}
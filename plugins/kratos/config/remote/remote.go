@@ -0,0 +1,215 @@
+package remote
+
+import (
+	"context"
+	"github.com/go-cinch/common/log"
+	"github.com/go-kratos/kratos/v2/config"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// waitMargin is added to the blocking time of Wait, a half-open connection fails after it instead of stalling hot reload
+var waitMargin = 10 * time.Second
+
+// Provider read config from remote config center
+type Provider interface {
+	// Key is the unique name of config, the extension is used as format
+	Key() string
+	// Get read current config and version
+	Get(ctx context.Context) (data []byte, version string, err error)
+	// Wait block until config changed from version or timeout, return the same version if nothing changed
+	Wait(ctx context.Context, version string) (data []byte, newVersion string, err error)
+}
+
+// Source is a kratos config source of remote provider, hot reload by watch and fallback to local cache on outage
+type Source struct {
+	ops      Options
+	provider Provider
+	lock     sync.Mutex
+	version  string
+}
+
+type watcher struct {
+	s       *Source
+	ctx     context.Context
+	cancel  context.CancelFunc
+	version string
+}
+
+func NewSource(provider Provider, options ...func(*Options)) (s *Source, err error) {
+	if provider == nil {
+		err = ErrProviderNil
+		return
+	}
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.cacheDir != "" {
+		err = checkCacheDir(ops.cacheDir)
+		if os.IsNotExist(errors.Cause(err)) {
+			err = nil
+		}
+		if err != nil {
+			return
+		}
+	}
+	s = &Source{
+		ops:      *ops,
+		provider: provider,
+	}
+	return
+}
+
+// Load read config from remote, the local cache is used if remote is unavailable
+func (s *Source) Load() (kvs []*config.KeyValue, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.ops.timeout)
+	defer cancel()
+	data, version, err := s.provider.Get(ctx)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return
+		}
+		cached, ok := s.readCache()
+		if !ok {
+			return
+		}
+		log.WithError(err).WithFields(log.Fields{
+			"key": s.provider.Key(),
+		}).Warn("load remote config failed, use local cache")
+		data = cached
+		// watcher will get the latest after remote recovered
+		version = ""
+		err = nil
+	} else {
+		s.writeCache(data)
+	}
+	s.lock.Lock()
+	s.version = version
+	s.lock.Unlock()
+	kvs = []*config.KeyValue{s.kv(data)}
+	return
+}
+
+// Watch config changes from the version of last Load
+func (s *Source) Watch() (w config.Watcher, err error) {
+	s.lock.Lock()
+	version := s.version
+	s.lock.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	w = &watcher{
+		s:       s,
+		ctx:     ctx,
+		cancel:  cancel,
+		version: version,
+	}
+	return
+}
+
+// Next block until config changed, kratos retries it after 1s if error
+func (w *watcher) Next() (kvs []*config.KeyValue, err error) {
+	for {
+		var data []byte
+		var version string
+		data, version, err = w.s.provider.Wait(w.ctx, w.version)
+		if w.ctx.Err() != nil {
+			err = w.ctx.Err()
+			return
+		}
+		if err != nil {
+			return
+		}
+		if version == w.version {
+			continue
+		}
+		w.version = version
+		w.s.writeCache(data)
+		kvs = []*config.KeyValue{w.s.kv(data)}
+		return
+	}
+}
+
+func (w *watcher) Stop() error {
+	w.cancel()
+	return nil
+}
+
+func (s *Source) kv(data []byte) *config.KeyValue {
+	key := s.provider.Key()
+	format := s.ops.format
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(key), ".")
+	}
+	return &config.KeyValue{
+		Key:    key,
+		Value:  data,
+		Format: format,
+	}
+}
+
+func (s *Source) cacheFile() string {
+	if s.ops.cacheDir == "" {
+		return ""
+	}
+	name := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(s.provider.Key())
+	return filepath.Join(s.ops.cacheDir, name)
+}
+
+func (s *Source) readCache() (data []byte, ok bool) {
+	file := s.cacheFile()
+	if file == "" {
+		return
+	}
+	err := checkCacheDir(s.ops.cacheDir)
+	if err == nil {
+		data, err = os.ReadFile(file)
+	}
+	ok = err == nil
+	if err != nil && !os.IsNotExist(errors.Cause(err)) {
+		log.WithError(err).WithFields(log.Fields{
+			"file": file,
+		}).Warn("read config cache failed")
+	}
+	return
+}
+
+// writeCache write to tmp file and rename, avoid partial file
+func (s *Source) writeCache(data []byte) {
+	file := s.cacheFile()
+	if file == "" {
+		return
+	}
+	err := os.MkdirAll(s.ops.cacheDir, 0o700)
+	if err == nil {
+		err = checkCacheDir(s.ops.cacheDir)
+	}
+	if err == nil {
+		tmp := file + ".tmp"
+		err = os.WriteFile(tmp, data, 0o600)
+		if err == nil {
+			err = os.Rename(tmp, file)
+		}
+	}
+	if err != nil {
+		log.WithError(err).WithFields(log.Fields{
+			"file": file,
+		}).Warn("write config cache failed")
+	}
+}
+
+// checkCacheDir other users can not read secrets or forge config by the cache
+func checkCacheDir(dir string) (err error) {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	if !info.IsDir() || !owned(info) {
+		err = errors.Wrap(ErrCacheDirInvalid, dir)
+	}
+	return
+}
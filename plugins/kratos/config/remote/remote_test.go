@@ -0,0 +1,301 @@
+package remote
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"github.com/go-kratos/kratos/v2/config"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newCacheDir temp dir only accessible by current user
+func newCacheDir(t *testing.T) string {
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// fakeKv is a config center with one key, changed notify blocking requests
+type fakeKv struct {
+	lock    sync.Mutex
+	data    string
+	index   int
+	changed chan struct{}
+}
+
+func newFakeKv(data string) *fakeKv {
+	return &fakeKv{
+		data:    data,
+		index:   1,
+		changed: make(chan struct{}),
+	}
+}
+
+func (f *fakeKv) get() (string, int, chan struct{}) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.data, f.index, f.changed
+}
+
+func (f *fakeKv) set(data string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.data = data
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// wait block until changed or timeout
+func (f *fakeKv) wait(r *http.Request, changed chan struct{}) {
+	select {
+	case <-changed:
+	case <-r.Context().Done():
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+type conf struct {
+	Name string `json:"name"`
+	Port int    `json:"port"`
+}
+
+func TestConsul(t *testing.T) {
+	_, err := NewConsul()
+	if err != ErrKeyNil {
+		t.Fatalf("expect key nil but got %v", err)
+	}
+	kv := newFakeKv("name: a\nport: 8080\n")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/kv/app/config.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("X-Consul-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		data, index, changed := kv.get()
+		if r.URL.Query().Get("index") == strconv.Itoa(index) {
+			kv.wait(r, changed)
+			data, index, _ = kv.get()
+		}
+		w.Header().Set("X-Consul-Index", strconv.Itoa(index))
+		_, _ = w.Write([]byte(data))
+	}))
+	c, _ := NewConsul(WithConsulAddr(srv.URL), WithConsulKey("/app/config.yaml"), WithConsulToken("token"), WithConsulWait(time.Second))
+	dir := newCacheDir(t)
+	s, _ := NewSource(c, WithCacheDir(dir))
+	cfg := config.New(config.WithSource(s))
+	if err = cfg.Load(); err != nil {
+		t.Fatal(err)
+	}
+	var v conf
+	_ = cfg.Scan(&v)
+	if v.Name != "a" || v.Port != 8080 {
+		t.Fatalf("unexpected config %+v", v)
+	}
+
+	// hot reload
+	ch := make(chan string, 1)
+	_ = cfg.Watch("name", func(key string, value config.Value) {
+		name, _ := value.String()
+		ch <- name
+	})
+	kv.set("name: b\nport: 8080\n")
+	select {
+	case name := <-ch:
+		if name != "b" {
+			t.Fatalf("unexpected name %s", name)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("config is not reloaded")
+	}
+	_ = cfg.Close()
+
+	// fallback to local cache
+	srv.Close()
+	s, _ = NewSource(c, WithCacheDir(dir))
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kvs[0].Format != "yaml" || string(kvs[0].Value) != "name: b\nport: 8080\n" {
+		t.Fatalf("unexpected cache %s %s", kvs[0].Format, kvs[0].Value)
+	}
+	// cache is disabled by default
+	s, _ = NewSource(c)
+	if _, err = s.Load(); err == nil {
+		t.Fatal("expect error without cache")
+	}
+}
+
+func TestCacheDir(t *testing.T) {
+	c, _ := NewConsul(WithConsulKey("app/config.yaml"))
+	dir := newCacheDir(t)
+	if _, err := NewSource(c, WithCacheDir(dir)); err != nil {
+		t.Fatalf("expect cache dir ok but got %v", err)
+	}
+	if _, err := NewSource(c, WithCacheDir(filepath.Join(dir, "not-exists"))); err != nil {
+		t.Fatalf("expect not exists dir ok but got %v", err)
+	}
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSource(c, WithCacheDir(dir)); !errors.Is(err, ErrCacheDirInvalid) {
+		t.Fatalf("expect cache dir invalid but got %v", err)
+	}
+	file := filepath.Join(dir, "file")
+	_ = os.WriteFile(file, nil, 0o600)
+	if _, err := NewSource(c, WithCacheDir(file)); !errors.Is(err, ErrCacheDirInvalid) {
+		t.Fatalf("expect cache dir invalid but got %v", err)
+	}
+
+	// cache is not written to an insecure dir
+	s := &Source{ops: Options{cacheDir: dir}, provider: c}
+	s.writeCache([]byte("name: a"))
+	if _, err := os.Stat(s.cacheFile()); !os.IsNotExist(err) {
+		t.Fatalf("expect no cache file but got %v", err)
+	}
+}
+
+func TestWaitTimeout(t *testing.T) {
+	margin := waitMargin
+	waitMargin = 100 * time.Millisecond
+	defer func() {
+		waitMargin = margin
+	}()
+	// half-open connection never responds
+	stop := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-stop
+	}))
+	defer srv.Close()
+	defer close(stop)
+	c, _ := NewConsul(WithConsulAddr(srv.URL), WithConsulKey("app/config.yaml"), WithConsulWait(100*time.Millisecond))
+	n, _ := NewNacos(WithNacosAddr(srv.URL), WithNacosDataId("app.json"), WithNacosTimeout(100*time.Millisecond))
+	for _, p := range []Provider{c, n} {
+		done := make(chan error, 1)
+		go func() {
+			_, _, err := p.Wait(context.Background(), "1")
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("%s expect deadline exceeded but got %v", p.Key(), err)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("%s wait is not timeout", p.Key())
+		}
+	}
+}
+
+func TestNacos(t *testing.T) {
+	_, err := NewNacos()
+	if err != ErrDataIdNil {
+		t.Fatalf("expect data id nil but got %v", err)
+	}
+	kv := newFakeKv(`{"name":"a","port":8080}`)
+	var logins int
+	var listening string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/nacos/v1/auth/login":
+			_ = r.ParseForm()
+			if r.PostForm.Get("username") != "nacos" || r.PostForm.Get("password") != "pwd" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			logins++
+			_, _ = w.Write([]byte(`{"accessToken":"token","tokenTtl":18000}`))
+			return
+		}
+		if r.URL.Query().Get("accessToken") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		data, _, changed := kv.get()
+		switch r.URL.Path {
+		case "/nacos/v1/cs/configs":
+			q := r.URL.Query()
+			if q.Get("dataId") != "app.json" || q.Get("group") != "DEFAULT_GROUP" || q.Get("tenant") != "dev" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(data))
+		case "/nacos/v1/cs/configs/listener":
+			_ = r.ParseForm()
+			listening = r.PostForm.Get("Listening-Configs")
+			item := strings.Split(strings.TrimSuffix(listening, "\x01"), "\x02")
+			if item[2] == md5Hex(data) {
+				kv.wait(r, changed)
+				data, _, _ = kv.get()
+			}
+			if item[2] != md5Hex(data) {
+				_, _ = w.Write([]byte("app.json%02DEFAULT_GROUP%02dev%01\n"))
+			}
+		}
+	}))
+	defer srv.Close()
+	n, _ := NewNacos(WithNacosAddr(srv.URL), WithNacosDataId("app.json"), WithNacosNamespace("dev"), WithNacosAuth("nacos", "pwd"))
+	if n.Key() != "dev/DEFAULT_GROUP/app.json" {
+		t.Fatalf("unexpected key %s", n.Key())
+	}
+	s, _ := NewSource(n, WithCacheDir(newCacheDir(t)))
+	kvs, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kvs[0].Format != "json" || string(kvs[0].Value) != `{"name":"a","port":8080}` {
+		t.Fatalf("unexpected config %s %s", kvs[0].Format, kvs[0].Value)
+	}
+	w, _ := s.Watch()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		kv.set(`{"name":"b","port":8080}`)
+	}()
+	kvs, err = w.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(kvs[0].Value) != `{"name":"b","port":8080}` {
+		t.Fatalf("unexpected config %s", kvs[0].Value)
+	}
+	if listening != "app.json\x02DEFAULT_GROUP\x02"+md5Hex(`{"name":"a","port":8080}`)+"\x02dev\x01" || logins != 1 {
+		t.Fatalf("unexpected listening %q logins %d", listening, logins)
+	}
+
+	// stop watch
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		_ = w.Stop()
+	}()
+	_, err = w.Next()
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expect canceled but got %v", err)
+	}
+
+	// not found is not fallback
+	n, _ = NewNacos(WithNacosAddr(srv.URL), WithNacosDataId("other.json"), WithNacosNamespace("dev"), WithNacosAuth("nacos", "pwd"))
+	s, _ = NewSource(n)
+	if _, err = s.Load(); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expect not found but got %v", err)
+	}
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,12 @@
+package query
+
+import "github.com/pkg/errors"
+
+var (
+	ErrFieldNotAllowed = errors.New("filter field is not allowed")
+	ErrOpNotAllowed    = errors.New("filter op is not allowed")
+	ErrSortNotAllowed  = errors.New("sort field is not allowed")
+	ErrInvalidValue    = errors.New("invalid filter value")
+	ErrInvalidLogic    = errors.New("invalid filter logic")
+	ErrTooComplex      = errors.New("filter is too complex")
+)
@@ -0,0 +1,24 @@
+module github.com/go-cinch/common/query
+
+go 1.20
+
+replace (
+	github.com/go-cinch/common/log => ../log
+	github.com/go-cinch/common/page => ../page
+)
+
+require (
+	github.com/go-cinch/common/page v1.0.4
+	github.com/pkg/errors v0.9.1
+	gorm.io/driver/sqlite v1.5.2
+	gorm.io/gorm v1.25.2
+)
+
+require (
+	github.com/go-cinch/common/log v1.0.4 // indirect
+	github.com/go-kratos/kratos/v2 v2.7.0 // indirect
+	github.com/jinzhu/inflection v1.0.0 // indirect
+	github.com/jinzhu/now v1.1.5 // indirect
+	github.com/mattn/go-sqlite3 v1.14.22 // indirect
+	golang.org/x/sync v0.9.0 // indirect
+)
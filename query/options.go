@@ -0,0 +1,92 @@
+package query
+
+type Options struct {
+	fields        map[string]field
+	sorts         map[string]string
+	defaultSorts  []Sort
+	maxConditions int
+	maxDepth      int
+	maxValues     int
+}
+
+type field struct {
+	column string
+	ops    map[Op]struct{}
+}
+
+// WithField allow filter by field, column is the db column, all ops are allowed if ops is empty
+func WithField(name, column string, ops ...Op) func(*Options) {
+	return func(options *Options) {
+		if name == "" || column == "" {
+			return
+		}
+		f := field{
+			column: column,
+		}
+		if len(ops) > 0 {
+			f.ops = make(map[Op]struct{}, len(ops))
+			for _, item := range ops {
+				f.ops[item] = struct{}{}
+			}
+		}
+		getOptionsOrSetDefault(options).fields[name] = f
+	}
+}
+
+// WithSort allow sort by field, column is the db column
+func WithSort(name, column string) func(*Options) {
+	return func(options *Options) {
+		if name != "" && column != "" {
+			getOptionsOrSetDefault(options).sorts[name] = column
+		}
+	}
+}
+
+// WithDefaultSort is used when request has no sort, the fields should be allowed by WithSort
+func WithDefaultSort(sorts ...Sort) func(*Options) {
+	return func(options *Options) {
+		if len(sorts) > 0 {
+			getOptionsOrSetDefault(options).defaultSorts = sorts
+		}
+	}
+}
+
+// WithMaxConditions max count of conditions in all groups
+func WithMaxConditions(count int) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).maxConditions = count
+		}
+	}
+}
+
+// WithMaxDepth max nested depth of groups, the root group is 1
+func WithMaxDepth(depth int) func(*Options) {
+	return func(options *Options) {
+		if depth > 0 {
+			getOptionsOrSetDefault(options).maxDepth = depth
+		}
+	}
+}
+
+// WithMaxValues max count of in values
+func WithMaxValues(count int) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).maxValues = count
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			fields:        make(map[string]field),
+			sorts:         make(map[string]string),
+			maxConditions: 20,
+			maxDepth:      3,
+			maxValues:     100,
+		}
+	}
+	return options
+}
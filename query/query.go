@@ -0,0 +1,330 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-cinch/common/page"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type Op string
+
+const (
+	Eq      Op = "eq"
+	Ne      Op = "ne"
+	Gt      Op = "gt"
+	Gte     Op = "gte"
+	Lt      Op = "lt"
+	Lte     Op = "lte"
+	Like    Op = "like"
+	In      Op = "in"
+	Between Op = "between"
+)
+
+const (
+	And = "and"
+	Or  = "or"
+)
+
+// Condition is one field condition, value of in is array, value of between is [min, max]
+type Condition struct {
+	Field string      `json:"field"`
+	Op    Op          `json:"op"`
+	Value interface{} `json:"value"`
+}
+
+// Group combine conditions and sub groups by logic
+type Group struct {
+	Logic      string      `json:"logic"` // and(default)/or
+	Conditions []Condition `json:"conditions"`
+	Groups     []Group     `json:"groups"`
+}
+
+type Sort struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// Spec is the declarative filter from request
+type Spec struct {
+	Filter Group      `json:"filter"`
+	Sorts  []Sort     `json:"sorts"`
+	Page   *page.Page `json:"page"`
+}
+
+// Query convert spec to gorm scope, only whitelisted fields can be used
+type Query struct {
+	ops Options
+}
+
+func New(options ...func(*Options)) *Query {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	return &Query{
+		ops: *ops,
+	}
+}
+
+// FromQuery parse spec from url query,
+// e.g. ?filter={"conditions":[{"field":"name","op":"like","value":"cinch"}]}&sort=-createdAt,id&page.num=1
+func FromQuery(values url.Values) (spec Spec, err error) {
+	if s := values.Get("filter"); s != "" {
+		err = json.Unmarshal([]byte(s), &spec.Filter)
+		if err != nil {
+			err = errors.Wrap(ErrInvalidValue, err.Error())
+			return
+		}
+	}
+	for _, item := range strings.Split(values.Get("sort"), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		s := Sort{
+			Field: strings.TrimPrefix(item, "-"),
+			Desc:  strings.HasPrefix(item, "-"),
+		}
+		spec.Sorts = append(spec.Sorts, s)
+	}
+	spec.Page = page.FromQuery(values)
+	return
+}
+
+// Scope validate spec and convert it to where and order scope, e.g. db.Scopes(scope).Find(&list)
+func (q *Query) Scope(spec Spec) (scope func(*gorm.DB) *gorm.DB, err error) {
+	where, err := q.Where(spec.Filter)
+	if err != nil {
+		return
+	}
+	order, err := q.Order(spec.Sorts...)
+	if err != nil {
+		return
+	}
+	scope = func(db *gorm.DB) *gorm.DB {
+		if where != nil {
+			db = db.Where(where)
+		}
+		if len(order.Columns) > 0 {
+			db = db.Clauses(order)
+		}
+		return db
+	}
+	return
+}
+
+// Find query list by spec with page, spec.Page is created if it is nil
+func (q *Query) Find(ctx context.Context, db *gorm.DB, spec *Spec, model interface{}) (err error) {
+	scope, err := q.Scope(*spec)
+	if err != nil {
+		return
+	}
+	if spec.Page == nil {
+		spec.Page = page.New()
+	}
+	spec.Page.
+		WithContext(ctx).
+		Query(db.Scopes(scope)).
+		Find(model)
+	return
+}
+
+// Where convert group to where expression, nil means no condition
+func (q *Query) Where(g Group) (expr clause.Expression, err error) {
+	count := 0
+	expr, err = q.group(g, 1, &count)
+	return
+}
+
+// Order convert sorts to order by, default sorts are used if sorts is empty
+func (q *Query) Order(sorts ...Sort) (rp clause.OrderBy, err error) {
+	if len(sorts) == 0 {
+		sorts = q.ops.defaultSorts
+	}
+	for _, item := range sorts {
+		column, ok := q.ops.sorts[item.Field]
+		if !ok {
+			err = errors.Wrap(ErrSortNotAllowed, item.Field)
+			return
+		}
+		rp.Columns = append(rp.Columns, clause.OrderByColumn{
+			Column: clause.Column{Name: column},
+			Desc:   item.Desc,
+		})
+	}
+	return
+}
+
+func (q *Query) group(g Group, depth int, count *int) (expr clause.Expression, err error) {
+	if depth > q.ops.maxDepth {
+		err = errors.Wrapf(ErrTooComplex, "depth exceeds %d", q.ops.maxDepth)
+		return
+	}
+	logic := strings.ToLower(g.Logic)
+	if logic != "" && logic != And && logic != Or {
+		err = errors.Wrap(ErrInvalidLogic, g.Logic)
+		return
+	}
+	exprs := make([]clause.Expression, 0, len(g.Conditions)+len(g.Groups))
+	for _, item := range g.Conditions {
+		*count++
+		if *count > q.ops.maxConditions {
+			err = errors.Wrapf(ErrTooComplex, "conditions exceed %d", q.ops.maxConditions)
+			return
+		}
+		var e clause.Expression
+		e, err = q.condition(item)
+		if err != nil {
+			return
+		}
+		exprs = append(exprs, e)
+	}
+	for _, item := range g.Groups {
+		var e clause.Expression
+		e, err = q.group(item, depth+1, count)
+		if err != nil {
+			return
+		}
+		if e != nil {
+			exprs = append(exprs, e)
+		}
+	}
+	switch {
+	case len(exprs) == 0:
+	case logic == Or:
+		expr = clause.Or(exprs...)
+	default:
+		expr = clause.And(exprs...)
+	}
+	return
+}
+
+func (q *Query) condition(c Condition) (expr clause.Expression, err error) {
+	f, ok := q.ops.fields[c.Field]
+	if !ok {
+		err = errors.Wrap(ErrFieldNotAllowed, c.Field)
+		return
+	}
+	if f.ops != nil {
+		if _, ok = f.ops[c.Op]; !ok {
+			err = errors.Wrapf(ErrOpNotAllowed, "%s %s", c.Field, c.Op)
+			return
+		}
+	}
+	column := clause.Column{Name: f.column}
+	switch c.Op {
+	case Eq, Ne:
+		if c.Value != nil && !scalar(c.Value) {
+			err = errors.Wrapf(ErrInvalidValue, "%s %s requires scalar", c.Field, c.Op)
+			return
+		}
+		if c.Op == Eq {
+			expr = clause.Eq{Column: column, Value: c.Value}
+		} else {
+			expr = clause.Neq{Column: column, Value: c.Value}
+		}
+	case Gt, Gte, Lt, Lte:
+		if !scalar(c.Value) {
+			err = errors.Wrapf(ErrInvalidValue, "%s %s requires scalar", c.Field, c.Op)
+			return
+		}
+		switch c.Op {
+		case Gt:
+			expr = clause.Gt{Column: column, Value: c.Value}
+		case Gte:
+			expr = clause.Gte{Column: column, Value: c.Value}
+		case Lt:
+			expr = clause.Lt{Column: column, Value: c.Value}
+		default:
+			expr = clause.Lte{Column: column, Value: c.Value}
+		}
+	case Like:
+		s, ok1 := c.Value.(string)
+		if !ok1 || s == "" {
+			err = errors.Wrapf(ErrInvalidValue, "%s like requires string", c.Field)
+			return
+		}
+		// user input is matched literally, avoid wildcard scan
+		expr = clause.Expr{
+			SQL:  "? LIKE ? ESCAPE '!'",
+			Vars: []interface{}{column, "%" + likeEscaper.Replace(s) + "%"},
+		}
+	case In:
+		var values []interface{}
+		values, err = q.values(c)
+		if err != nil {
+			return
+		}
+		if len(values) == 0 || len(values) > q.ops.maxValues {
+			err = errors.Wrapf(ErrInvalidValue, "%s in requires 1-%d values", c.Field, q.ops.maxValues)
+			return
+		}
+		expr = clause.IN{Column: column, Values: values}
+	case Between:
+		var values []interface{}
+		values, err = q.values(c)
+		if err != nil {
+			return
+		}
+		if len(values) != 2 {
+			err = errors.Wrapf(ErrInvalidValue, "%s between requires 2 values", c.Field)
+			return
+		}
+		expr = clause.Expr{
+			SQL:  "? BETWEEN ? AND ?",
+			Vars: []interface{}{column, values[0], values[1]},
+		}
+	default:
+		err = errors.Wrapf(ErrOpNotAllowed, "%s %s", c.Field, c.Op)
+	}
+	return
+}
+
+func (q *Query) values(c Condition) (rp []interface{}, err error) {
+	rv := reflect.ValueOf(c.Value)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		err = errors.Wrapf(ErrInvalidValue, "%s %s requires array", c.Field, c.Op)
+		return
+	}
+	if rv.Len() > q.ops.maxValues {
+		err = errors.Wrapf(ErrInvalidValue, "%s %s values exceed %d", c.Field, c.Op, q.ops.maxValues)
+		return
+	}
+	rp = make([]interface{}, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		v := rv.Index(i).Interface()
+		if !scalar(v) {
+			err = errors.Wrapf(ErrInvalidValue, "%s %s requires scalar values", c.Field, c.Op)
+			return
+		}
+		rp = append(rp, v)
+	}
+	return
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// scalar check value can be bound as sql var, expressions/slices/maps are rejected
+func scalar(v interface{}) bool {
+	switch v.(type) {
+	case time.Time, *time.Time:
+		return true
+	case nil, clause.Expression:
+		return false
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"github.com/go-cinch/common/page"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"net/url"
+	"testing"
+)
+
+type user struct {
+	Id     uint64 `gorm:"primaryKey"`
+	Name   string
+	Age    int
+	Status string
+}
+
+func newDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = db.AutoMigrate(&user{})
+	db.Create([]user{
+		{Name: "alice", Age: 18, Status: "active"},
+		{Name: "bob", Age: 25, Status: "active"},
+		{Name: "carol", Age: 30, Status: "locked"},
+		{Name: "100%", Age: 40, Status: "active"},
+		{Name: "1000", Age: 50, Status: "deleted"},
+	})
+	return db
+}
+
+func newQuery() *Query {
+	return New(
+		WithField("name", "name", Eq, Like),
+		WithField("age", "age"),
+		WithField("status", "status", Eq, Ne, In),
+		WithSort("age", "age"),
+		WithSort("id", "id"),
+		WithDefaultSort(Sort{Field: "id"}),
+	)
+}
+
+func TestFind(t *testing.T) {
+	db := newDB(t)
+	q := newQuery()
+	// (status in (active, locked) and age between 20 and 45) or name like 'ali'
+	spec := &Spec{
+		Filter: Group{
+			Logic: Or,
+			Conditions: []Condition{
+				{Field: "name", Op: Like, Value: "ali"},
+			},
+			Groups: []Group{
+				{
+					Conditions: []Condition{
+						{Field: "status", Op: In, Value: []interface{}{"active", "locked"}},
+						{Field: "age", Op: Between, Value: []int{20, 45}},
+					},
+				},
+			},
+		},
+		Sorts: []Sort{{Field: "age", Desc: true}},
+	}
+	var list []user
+	err := q.Find(context.Background(), db.Model(&user{}), spec, &list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 4 || list[0].Name != "100%" || list[3].Name != "alice" || spec.Page.Total != 4 {
+		t.Fatalf("unexpected list %+v, total %d", list, spec.Page.Total)
+	}
+
+	// wildcard in value is matched literally
+	list = nil
+	spec = &Spec{
+		Filter: Group{Conditions: []Condition{{Field: "name", Op: Like, Value: "0%"}}},
+	}
+	_ = q.Find(context.Background(), db.Model(&user{}), spec, &list)
+	if len(list) != 1 || list[0].Name != "100%" {
+		t.Fatalf("unexpected like result %+v", list)
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	db := newDB(t)
+	values := url.Values{}
+	values.Set("filter", `{"conditions":[{"field":"status","op":"ne","value":"deleted"},{"field":"age","op":"gte","value":25}]}`)
+	values.Set("sort", "-age,id")
+	values.Set("page.size", "2")
+	spec, err := FromQuery(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spec.Sorts) != 2 || !spec.Sorts[0].Desc || spec.Sorts[1].Field != "id" || spec.Page.Size != 2 {
+		t.Fatalf("unexpected spec %+v", spec)
+	}
+	var list []user
+	err = newQuery().Find(context.Background(), db.Model(&user{}), &spec, &list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Age != 40 || spec.Page.Total != 3 {
+		t.Fatalf("unexpected list %+v, total %d", list, spec.Page.Total)
+	}
+
+	values.Set("filter", "{")
+	if _, err = FromQuery(values); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("expect invalid value but got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	q := newQuery()
+	cases := []struct {
+		spec Spec
+		err  error
+	}{
+		{Spec{Filter: Group{Conditions: []Condition{{Field: "password", Op: Eq, Value: "x"}}}}, ErrFieldNotAllowed},
+		{Spec{Filter: Group{Conditions: []Condition{{Field: "name", Op: Gt, Value: "x"}}}}, ErrOpNotAllowed},
+		{Spec{Filter: Group{Conditions: []Condition{{Field: "age", Op: "exists", Value: 1}}}}, ErrOpNotAllowed},
+		{Spec{Filter: Group{Conditions: []Condition{{Field: "age", Op: Gt, Value: map[string]int{"a": 1}}}}}, ErrInvalidValue},
+		{Spec{Filter: Group{Conditions: []Condition{{Field: "age", Op: Between, Value: []int{1}}}}}, ErrInvalidValue},
+		{Spec{Filter: Group{Conditions: []Condition{{Field: "status", Op: In, Value: "active"}}}}, ErrInvalidValue},
+		{Spec{Filter: Group{Logic: "xor"}}, ErrInvalidLogic},
+		{Spec{Filter: Group{Groups: []Group{{Groups: []Group{{Groups: []Group{{}}}}}}}}, ErrTooComplex},
+		{Spec{Sorts: []Sort{{Field: "name"}}}, ErrSortNotAllowed},
+	}
+	for i, item := range cases {
+		if _, err := q.Scope(item.spec); !errors.Is(err, item.err) {
+			t.Fatalf("case %d expect %v but got %v", i, item.err, err)
+		}
+	}
+
+	db := newDB(t)
+	scope, err := q.Scope(Spec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		var list []user
+		return tx.Model(&user{}).Scopes(scope, page.New().Scope()).Find(&list)
+	})
+	if sql != "SELECT * FROM `users` ORDER BY `id` LIMIT 10" {
+		t.Fatalf("unexpected sql %s", sql)
+	}
+}
@@ -0,0 +1,102 @@
+package retry
+
+import (
+	"context"
+	"github.com/go-cinch/common/log"
+	"time"
+)
+
+type Options struct {
+	maxAttempts int
+	backoff     func(attempt int) time.Duration
+	jitter      float64
+	retryIf     func(err error) bool
+	onRetry     func(ctx context.Context, attempt int, err error, delay time.Duration)
+}
+
+// WithMaxAttempts max call count of fn, including the first call
+func WithMaxAttempts(count int) func(*Options) {
+	return func(options *Options) {
+		if count > 0 {
+			getOptionsOrSetDefault(options).maxAttempts = count
+		}
+	}
+}
+
+// WithBackoff custom delay before next attempt, attempt starts from 1
+func WithBackoff(f func(attempt int) time.Duration) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).backoff = f
+		}
+	}
+}
+
+// WithConstantBackoff wait the same delay between attempts
+func WithConstantBackoff(delay time.Duration) func(*Options) {
+	return func(options *Options) {
+		if delay >= 0 {
+			getOptionsOrSetDefault(options).backoff = Constant(delay)
+		}
+	}
+}
+
+// WithExponentialBackoff delay is initial*2^(attempt-1), but not more than max
+func WithExponentialBackoff(initial, max time.Duration) func(*Options) {
+	return func(options *Options) {
+		if initial > 0 {
+			getOptionsOrSetDefault(options).backoff = Exponential(initial, max)
+		}
+	}
+}
+
+// WithJitter change delay by random [-delay*ratio, delay*ratio), avoid callers retry at the same time
+func WithJitter(ratio float64) func(*Options) {
+	return func(options *Options) {
+		if ratio >= 0 && ratio <= 1 {
+			getOptionsOrSetDefault(options).jitter = ratio
+		}
+	}
+}
+
+// WithRetryIf classify error, return false to stop retrying, all errors are retried by default
+func WithRetryIf(f func(err error) bool) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).retryIf = f
+		}
+	}
+}
+
+// WithOnRetry called before waiting next attempt, default log a warning
+func WithOnRetry(f func(ctx context.Context, attempt int, err error, delay time.Duration)) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).onRetry = f
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			maxAttempts: 3,
+			backoff:     Exponential(100*time.Millisecond, 10*time.Second),
+			jitter:      0.1,
+			retryIf: func(err error) bool {
+				return true
+			},
+			onRetry: func(ctx context.Context, attempt int, err error, delay time.Duration) {
+				log.
+					WithContext(ctx).
+					WithError(err).
+					WithFields(log.Fields{
+						"attempt": attempt,
+						"delay":   delay.String(),
+					}).
+					Warn("retry after failure")
+			},
+		}
+	}
+	return options
+}
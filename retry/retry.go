@@ -0,0 +1,127 @@
+package retry
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+type unrecoverable struct {
+	err error
+}
+
+func (u unrecoverable) Error() string {
+	return u.err.Error()
+}
+
+func (u unrecoverable) Unwrap() error {
+	return u.err
+}
+
+// Unrecoverable mark err as not retryable, Do returns the origin err immediately
+func Unrecoverable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return unrecoverable{err: err}
+}
+
+// Do call fn until it succeeds, attempts exhausted, error is not retryable or ctx is done,
+// the last error of fn is returned
+func Do(ctx context.Context, fn func(ctx context.Context) error, options ...func(*Options)) (err error) {
+	_, err = DoValue(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}, options...)
+	return
+}
+
+// DoValue is the same as Do, but fn returns a value, such as cache loader
+func DoValue[T any](ctx context.Context, fn func(ctx context.Context) (T, error), options ...func(*Options)) (v T, err error) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for attempt := 1; ; attempt++ {
+		if e := ctx.Err(); e != nil {
+			if err == nil {
+				err = errors.WithStack(e)
+			} else {
+				err = errors.WithMessagef(e, "last error: %v", err)
+			}
+			return
+		}
+		v, err = fn(ctx)
+		if err == nil {
+			return
+		}
+		var u unrecoverable
+		if errors.As(err, &u) {
+			err = u.err
+			return
+		}
+		if attempt >= ops.maxAttempts || !ops.retryIf(err) {
+			return
+		}
+		delay := ops.delay(attempt)
+		ops.onRetry(ctx, attempt, err, delay)
+		if delay <= 0 {
+			continue
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// Constant backoff
+func Constant(delay time.Duration) func(attempt int) time.Duration {
+	return func(int) time.Duration {
+		return delay
+	}
+}
+
+// Exponential backoff, max <= 0 means no limit
+func Exponential(initial, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		d := initial
+		for i := 1; i < attempt; i++ {
+			if d > math.MaxInt64/2 {
+				// avoid overflow
+				d = math.MaxInt64
+				break
+			}
+			d *= 2
+			if max > 0 && d >= max {
+				return max
+			}
+		}
+		if max > 0 && d > max {
+			d = max
+		}
+		return d
+	}
+}
+
+func (ops Options) delay(attempt int) time.Duration {
+	d := ops.backoff(attempt)
+	if d <= 0 || ops.jitter <= 0 {
+		return d
+	}
+	// float avoids overflow of large backoff, delay is in [d*(1-jitter), d*(1+jitter))
+	f := float64(d) * (1 - ops.jitter + 2*ops.jitter*rand.Float64())
+	if f >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	if f < 0 {
+		return 0
+	}
+	return time.Duration(f)
+}
@@ -0,0 +1,123 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	var count int
+	var delays []time.Duration
+	err := Do(context.Background(), func(ctx context.Context) error {
+		count++
+		if count < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	},
+		WithMaxAttempts(5),
+		WithExponentialBackoff(time.Millisecond, 0),
+		WithJitter(0),
+		WithOnRetry(func(ctx context.Context, attempt int, err error, delay time.Duration) {
+			delays = append(delays, delay)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 || len(delays) != 2 || delays[0] != time.Millisecond || delays[1] != 2*time.Millisecond {
+		t.Fatalf("unexpected count %d delays %v", count, delays)
+	}
+
+	// attempts exhausted
+	count = 0
+	errTemporary := errors.New("temporary")
+	err = Do(context.Background(), func(ctx context.Context) error {
+		count++
+		return errTemporary
+	}, WithMaxAttempts(2), WithConstantBackoff(0))
+	if err != errTemporary || count != 2 {
+		t.Fatalf("unexpected count %d err %v", count, err)
+	}
+
+	// not retryable
+	count = 0
+	errFatal := errors.New("fatal")
+	err = Do(context.Background(), func(ctx context.Context) error {
+		count++
+		return errFatal
+	}, WithConstantBackoff(0), WithRetryIf(func(err error) bool {
+		return !errors.Is(err, errFatal)
+	}))
+	if err != errFatal || count != 1 {
+		t.Fatalf("unexpected count %d err %v", count, err)
+	}
+	count = 0
+	err = Do(context.Background(), func(ctx context.Context) error {
+		count++
+		return Unrecoverable(errFatal)
+	}, WithConstantBackoff(0))
+	if err != errFatal || count != 1 {
+		t.Fatalf("unexpected count %d err %v", count, err)
+	}
+}
+
+func TestDoContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var count int
+	err := Do(ctx, func(ctx context.Context) error {
+		count++
+		return errors.New("temporary")
+	}, WithMaxAttempts(10), WithConstantBackoff(time.Second))
+	if !errors.Is(err, context.DeadlineExceeded) || count != 1 {
+		t.Fatalf("unexpected count %d err %v", count, err)
+	}
+}
+
+func TestDoValue(t *testing.T) {
+	var count int
+	v, err := DoValue(context.Background(), func(ctx context.Context) (string, error) {
+		count++
+		if count == 1 {
+			return "", errors.New("temporary")
+		}
+		return "ok", nil
+	}, WithConstantBackoff(0))
+	if err != nil || v != "ok" {
+		t.Fatalf("unexpected value %s err %v", v, err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	f := Exponential(100*time.Millisecond, time.Second)
+	expect := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, item := range expect {
+		if d := f(i + 1); d != item {
+			t.Fatalf("attempt %d expect %v but got %v", i+1, item, d)
+		}
+	}
+	if d := Exponential(time.Second, 0)(100); d <= 0 {
+		t.Fatalf("overflow %v", d)
+	}
+	ops := getOptionsOrSetDefault(nil)
+	ops.backoff = Constant(time.Second)
+	ops.jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := ops.delay(1); d < 500*time.Millisecond || d >= 1500*time.Millisecond {
+			t.Fatalf("unexpected jitter delay %v", d)
+		}
+	}
+	// large backoff does not overflow
+	ops.backoff = Exponential(time.Second, 0)
+	for _, jitter := range []float64{0.1, 0.6, 1} {
+		ops.jitter = jitter
+		for i := 0; i < 100; i++ {
+			if d := ops.delay(100); d < 0 {
+				t.Fatalf("jitter %v overflow %v", jitter, d)
+			}
+		}
+	}
+}
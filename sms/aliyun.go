@@ -0,0 +1,125 @@
+package sms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Aliyun send sms by aliyun dysmsapi, signature version 1.0
+type Aliyun struct {
+	ops AliyunOptions
+}
+
+type aliyunReply struct {
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+	RequestId string `json:"RequestId"`
+	BizId     string `json:"BizId"`
+}
+
+func NewAliyun(options ...func(*AliyunOptions)) (a *Aliyun, err error) {
+	ops := getAliyunOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.key == "" {
+		err = ErrKeyNil
+		return
+	}
+	if ops.secret == "" {
+		err = ErrSecretNil
+		return
+	}
+	a = &Aliyun{
+		ops: *ops,
+	}
+	return
+}
+
+func (a *Aliyun) Send(ctx context.Context, msg Message) (err error) {
+	err = msg.validate()
+	if err != nil {
+		return
+	}
+	if msg.Sign == "" {
+		msg.Sign = a.ops.sign
+	}
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	params := url.Values{}
+	params.Set("AccessKeyId", a.ops.key)
+	params.Set("Action", "SendSms")
+	params.Set("Format", "JSON")
+	params.Set("RegionId", a.ops.region)
+	params.Set("SignatureMethod", "HMAC-SHA1")
+	params.Set("SignatureNonce", hex.EncodeToString(nonce))
+	params.Set("SignatureVersion", "1.0")
+	params.Set("Timestamp", time.Now().UTC().Format("2006-01-02T15:04:05Z"))
+	params.Set("Version", "2017-05-25")
+	params.Set("PhoneNumbers", msg.Phone)
+	params.Set("SignName", msg.Sign)
+	params.Set("TemplateCode", msg.Template)
+	if len(msg.Params) > 0 {
+		bs, _ := json.Marshal(msg.paramMap())
+		params.Set("TemplateParam", string(bs))
+	}
+	query := aliyunCanonicalize(params)
+	params.Set("Signature", a.sign(http.MethodGet, query))
+
+	r, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(a.ops.url, "/")+"/?"+aliyunCanonicalize(params), nil)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	bs, err := do(a.ops.client, r)
+	if err != nil {
+		return
+	}
+	var reply aliyunReply
+	err = json.Unmarshal(bs, &reply)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	if reply.Code != "OK" {
+		err = errors.Wrapf(ErrSendFailed, "aliyun %s %s, request id: %s", reply.Code, reply.Message, reply.RequestId)
+	}
+	return
+}
+
+func (a *Aliyun) sign(method, query string) string {
+	s := strings.Join([]string{method, aliyunEncode("/"), aliyunEncode(query)}, "&")
+	h := hmac.New(sha1.New, []byte(a.ops.secret+"&"))
+	h.Write([]byte(s))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// aliyunCanonicalize sort params by key and encode as rfc3986
+func aliyunCanonicalize(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	list := make([]string, 0, len(keys))
+	for _, k := range keys {
+		list = append(list, aliyunEncode(k)+"="+aliyunEncode(params.Get(k)))
+	}
+	return strings.Join(list, "&")
+}
+
+func aliyunEncode(s string) string {
+	s = url.QueryEscape(s)
+	return strings.NewReplacer("+", "%20", "*", "%2A", "%7E", "~").Replace(s)
+}
@@ -0,0 +1,124 @@
+package sms
+
+import (
+	"context"
+	"crypto/rand"
+	"github.com/pkg/errors"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// redis lua script
+const (
+	// return 1 if matched, 0 if mismatched, -1 if not found, -2 if attempts exceeded
+	luaVerify string = `
+local code = redis.call('HGET', KEYS[1], 'code')
+if not code then
+    return -1
+end
+local attempts = redis.call('HINCRBY', KEYS[1], 'attempts', 1)
+if code == ARGV[1] then
+    redis.call('DEL', KEYS[1])
+    return 1
+end
+if attempts >= tonumber(ARGV[2]) then
+    redis.call('DEL', KEYS[1])
+    return -2
+end
+return 0
+`
+)
+
+// Code issue and verify sms verification code, the code is one-time and invalid after max attempts
+type Code struct {
+	ops    CodeOptions
+	sender Sender
+}
+
+func NewCode(sender Sender, options ...func(*CodeOptions)) (c *Code, err error) {
+	ops := getCodeOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.redis == nil {
+		err = ErrRedisNil
+		return
+	}
+	if ops.template == "" {
+		err = ErrTemplateNil
+		return
+	}
+	c = &Code{
+		ops:    *ops,
+		sender: sender,
+	}
+	return
+}
+
+// Issue generate a new code and send to phone, the previous code will be replaced
+func (c *Code) Issue(ctx context.Context, phone string) (err error) {
+	if phone == "" {
+		err = ErrPhoneNil
+		return
+	}
+	code := randomDigits(c.ops.length)
+	key := c.key(phone)
+	pipe := c.ops.redis.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.HSet(ctx, key, "code", code, "attempts", 0)
+	pipe.Expire(ctx, key, time.Duration(c.ops.expire)*time.Second)
+	_, err = pipe.Exec(ctx)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	err = c.sender.Send(ctx, Message{
+		Phone:    phone,
+		Sign:     c.ops.sign,
+		Template: c.ops.template,
+		Params:   c.ops.params(code),
+	})
+	if err != nil {
+		// the code is useless if not sent
+		c.ops.redis.Del(ctx, key)
+	}
+	return
+}
+
+// Verify check code of phone, ErrCodeNotFound/ErrCodeMismatch/ErrCodeAttempts will be returned if failed
+func (c *Code) Verify(ctx context.Context, phone, code string) (err error) {
+	if phone == "" {
+		err = ErrPhoneNil
+		return
+	}
+	res, err := c.ops.redis.Eval(ctx, luaVerify, []string{c.key(phone)}, code, c.ops.maxAttempts).Int64()
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	switch res {
+	case 1:
+	case 0:
+		err = ErrCodeMismatch
+	case -2:
+		err = ErrCodeAttempts
+	default:
+		err = ErrCodeNotFound
+	}
+	return
+}
+
+func (c *Code) key(phone string) string {
+	return strings.Join([]string{c.ops.prefix, phone}, ".")
+}
+
+func randomDigits(n int) string {
+	var b strings.Builder
+	ten := big.NewInt(10)
+	for i := 0; i < n; i++ {
+		d, _ := rand.Int(rand.Reader, ten)
+		b.WriteByte(byte('0' + d.Int64()))
+	}
+	return b.String()
+}
@@ -0,0 +1,18 @@
+package sms
+
+import "github.com/pkg/errors"
+
+var (
+	ErrKeyNil            = errors.New("access key is empty")
+	ErrSecretNil         = errors.New("access secret is empty")
+	ErrAppIdNil          = errors.New("sdk app id is empty")
+	ErrFromNil           = errors.New("from number is empty")
+	ErrRedisNil          = errors.New("redis is nil")
+	ErrPhoneNil          = errors.New("phone is empty")
+	ErrTemplateNil       = errors.New("template is empty")
+	ErrInvalidStatusCode = errors.New("invalid status code")
+	ErrSendFailed        = errors.New("send sms failed")
+	ErrCodeNotFound      = errors.New("verification code not found or expired")
+	ErrCodeMismatch      = errors.New("verification code mismatch")
+	ErrCodeAttempts      = errors.New("verification code attempts exceeded")
+)
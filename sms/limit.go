@@ -0,0 +1,95 @@
+package sms
+
+import (
+	"context"
+	"github.com/go-cinch/common/middleware/ratelimit"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// check all rules first and consume one quota of each rule only if all allowed,
+// so a rejected send does not use the quota of earlier rules.
+// return 0 if allowed, otherwise return retry after milliseconds
+const luaLimit string = `
+local now = tonumber(ARGV[1])
+local wait = 0
+for i, key in ipairs(KEYS) do
+    local window = tonumber(ARGV[i * 2 + 1])
+    local limit = tonumber(ARGV[i * 2 + 2])
+    redis.call('ZREMRANGEBYSCORE', key, 0, now - window)
+    if redis.call('ZCARD', key) >= limit then
+        local first = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+        wait = math.max(wait, 1, tonumber(first[2]) + window - now)
+    end
+end
+if wait > 0 then
+    return wait
+end
+for i, key in ipairs(KEYS) do
+    redis.call('ZADD', key, now, ARGV[2])
+    redis.call('PEXPIRE', key, tonumber(ARGV[i * 2 + 1]))
+end
+return 0
+`
+
+type limited struct {
+	Sender
+	ops   LimitOptions
+	rules []Rule
+}
+
+// Limit wrap sender with per-phone rate limit backed by redis,
+// ratelimit.ErrRateLimited will be returned if any rule exceeds
+func Limit(s Sender, options ...func(*LimitOptions)) (l Sender, err error) {
+	ops := getLimitOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.redis == nil {
+		err = ErrRedisNil
+		return
+	}
+	rules := ops.rules
+	if len(rules) == 0 {
+		rules = []Rule{
+			{Limit: 1, Window: 60},
+			{Limit: 5, Window: 3600},
+			{Limit: 10, Window: 86400},
+		}
+	}
+	l = &limited{
+		Sender: s,
+		ops:    *ops,
+		rules:  rules,
+	}
+	return
+}
+
+func (l *limited) Send(ctx context.Context, msg Message) (err error) {
+	if msg.Phone == "" {
+		err = ErrPhoneNil
+		return
+	}
+	keys := make([]string, 0, len(l.rules))
+	args := []interface{}{time.Now().UnixMilli(), uuid.NewString()}
+	for _, item := range l.rules {
+		// hash tag keeps keys of one phone in the same slot of redis cluster
+		keys = append(keys, strings.Join([]string{l.ops.prefix, strconv.Itoa(item.Window), "{" + msg.Phone + "}"}, "."))
+		args = append(args, int64(item.Window)*1000, item.Limit)
+	}
+	// fail closed, sms costs money and should not be sent without limit
+	res, err := l.ops.redis.Eval(ctx, luaLimit, keys, args...).Int64()
+	if err != nil {
+		return
+	}
+	if res > 0 {
+		err = ratelimit.ErrRateLimited{
+			Key:        msg.Phone,
+			RetryAfter: time.Duration(res) * time.Millisecond,
+		}
+		return
+	}
+	return l.Sender.Send(ctx, msg)
+}
@@ -0,0 +1,368 @@
+package sms
+
+import (
+	"github.com/redis/go-redis/v9"
+	"net/http"
+	"time"
+)
+
+type AliyunOptions struct {
+	url    string
+	key    string // access key id
+	secret string // access key secret
+	region string
+	sign   string // default sign name
+	client *http.Client
+}
+
+func WithAliyunUrl(url string) func(*AliyunOptions) {
+	return func(options *AliyunOptions) {
+		if url != "" {
+			getAliyunOptionsOrSetDefault(options).url = url
+		}
+	}
+}
+
+func WithAliyunKey(key string) func(*AliyunOptions) {
+	return func(options *AliyunOptions) {
+		if key != "" {
+			getAliyunOptionsOrSetDefault(options).key = key
+		}
+	}
+}
+
+func WithAliyunSecret(secret string) func(*AliyunOptions) {
+	return func(options *AliyunOptions) {
+		if secret != "" {
+			getAliyunOptionsOrSetDefault(options).secret = secret
+		}
+	}
+}
+
+func WithAliyunRegion(region string) func(*AliyunOptions) {
+	return func(options *AliyunOptions) {
+		if region != "" {
+			getAliyunOptionsOrSetDefault(options).region = region
+		}
+	}
+}
+
+func WithAliyunSign(sign string) func(*AliyunOptions) {
+	return func(options *AliyunOptions) {
+		if sign != "" {
+			getAliyunOptionsOrSetDefault(options).sign = sign
+		}
+	}
+}
+
+func WithAliyunClient(client *http.Client) func(*AliyunOptions) {
+	return func(options *AliyunOptions) {
+		if client != nil {
+			getAliyunOptionsOrSetDefault(options).client = client
+		}
+	}
+}
+
+func getAliyunOptionsOrSetDefault(options *AliyunOptions) *AliyunOptions {
+	if options == nil {
+		return &AliyunOptions{
+			url:    "https://dysmsapi.aliyuncs.com",
+			region: "cn-hangzhou",
+			client: &http.Client{
+				Timeout: 10 * time.Second,
+			},
+		}
+	}
+	return options
+}
+
+type TencentOptions struct {
+	url    string
+	key    string // secret id
+	secret string // secret key
+	region string
+	appId  string // sms sdk app id
+	sign   string // default sign name
+	client *http.Client
+}
+
+func WithTencentUrl(url string) func(*TencentOptions) {
+	return func(options *TencentOptions) {
+		if url != "" {
+			getTencentOptionsOrSetDefault(options).url = url
+		}
+	}
+}
+
+func WithTencentKey(key string) func(*TencentOptions) {
+	return func(options *TencentOptions) {
+		if key != "" {
+			getTencentOptionsOrSetDefault(options).key = key
+		}
+	}
+}
+
+func WithTencentSecret(secret string) func(*TencentOptions) {
+	return func(options *TencentOptions) {
+		if secret != "" {
+			getTencentOptionsOrSetDefault(options).secret = secret
+		}
+	}
+}
+
+func WithTencentRegion(region string) func(*TencentOptions) {
+	return func(options *TencentOptions) {
+		if region != "" {
+			getTencentOptionsOrSetDefault(options).region = region
+		}
+	}
+}
+
+func WithTencentAppId(appId string) func(*TencentOptions) {
+	return func(options *TencentOptions) {
+		if appId != "" {
+			getTencentOptionsOrSetDefault(options).appId = appId
+		}
+	}
+}
+
+func WithTencentSign(sign string) func(*TencentOptions) {
+	return func(options *TencentOptions) {
+		if sign != "" {
+			getTencentOptionsOrSetDefault(options).sign = sign
+		}
+	}
+}
+
+func WithTencentClient(client *http.Client) func(*TencentOptions) {
+	return func(options *TencentOptions) {
+		if client != nil {
+			getTencentOptionsOrSetDefault(options).client = client
+		}
+	}
+}
+
+func getTencentOptionsOrSetDefault(options *TencentOptions) *TencentOptions {
+	if options == nil {
+		return &TencentOptions{
+			url:    "https://sms.tencentcloudapi.com",
+			region: "ap-guangzhou",
+			client: &http.Client{
+				Timeout: 10 * time.Second,
+			},
+		}
+	}
+	return options
+}
+
+type TwilioOptions struct {
+	url       string
+	sid       string // account sid
+	token     string // auth token
+	from      string // from number or messaging service sid(starts with MG)
+	templates map[string]string
+	client    *http.Client
+}
+
+func WithTwilioUrl(url string) func(*TwilioOptions) {
+	return func(options *TwilioOptions) {
+		if url != "" {
+			getTwilioOptionsOrSetDefault(options).url = url
+		}
+	}
+}
+
+func WithTwilioSid(sid string) func(*TwilioOptions) {
+	return func(options *TwilioOptions) {
+		if sid != "" {
+			getTwilioOptionsOrSetDefault(options).sid = sid
+		}
+	}
+}
+
+func WithTwilioToken(token string) func(*TwilioOptions) {
+	return func(options *TwilioOptions) {
+		if token != "" {
+			getTwilioOptionsOrSetDefault(options).token = token
+		}
+	}
+}
+
+func WithTwilioFrom(from string) func(*TwilioOptions) {
+	return func(options *TwilioOptions) {
+		if from != "" {
+			getTwilioOptionsOrSetDefault(options).from = from
+		}
+	}
+}
+
+// WithTwilioTemplates twilio has no template, register text/template body by template id
+func WithTwilioTemplates(templates map[string]string) func(*TwilioOptions) {
+	return func(options *TwilioOptions) {
+		for k, v := range templates {
+			getTwilioOptionsOrSetDefault(options).templates[k] = v
+		}
+	}
+}
+
+func WithTwilioClient(client *http.Client) func(*TwilioOptions) {
+	return func(options *TwilioOptions) {
+		if client != nil {
+			getTwilioOptionsOrSetDefault(options).client = client
+		}
+	}
+}
+
+func getTwilioOptionsOrSetDefault(options *TwilioOptions) *TwilioOptions {
+	if options == nil {
+		return &TwilioOptions{
+			url:       "https://api.twilio.com",
+			templates: make(map[string]string),
+			client: &http.Client{
+				Timeout: 10 * time.Second,
+			},
+		}
+	}
+	return options
+}
+
+// Rule allow limit sms in window seconds for each phone
+type Rule struct {
+	Limit  int
+	Window int
+}
+
+type LimitOptions struct {
+	redis  redis.UniversalClient
+	prefix string
+	rules  []Rule
+}
+
+func WithLimitRedis(rd redis.UniversalClient) func(*LimitOptions) {
+	return func(options *LimitOptions) {
+		if rd != nil {
+			getLimitOptionsOrSetDefault(options).redis = rd
+		}
+	}
+}
+
+func WithLimitPrefix(prefix string) func(*LimitOptions) {
+	return func(options *LimitOptions) {
+		if prefix != "" {
+			getLimitOptionsOrSetDefault(options).prefix = prefix
+		}
+	}
+}
+
+// WithLimitRule append rule, default rules are 1/minute, 5/hour and 10/day if no rule provided
+func WithLimitRule(limit, second int) func(*LimitOptions) {
+	return func(options *LimitOptions) {
+		if limit > 0 && second > 0 {
+			getLimitOptionsOrSetDefault(options).rules = append(getLimitOptionsOrSetDefault(options).rules, Rule{
+				Limit:  limit,
+				Window: second,
+			})
+		}
+	}
+}
+
+func getLimitOptionsOrSetDefault(options *LimitOptions) *LimitOptions {
+	if options == nil {
+		return &LimitOptions{
+			prefix: "sms.limit",
+		}
+	}
+	return options
+}
+
+type CodeOptions struct {
+	redis       redis.UniversalClient
+	prefix      string
+	expire      int
+	length      int
+	maxAttempts int
+	template    string
+	sign        string
+	params      func(code string) []Param
+}
+
+func WithCodeRedis(rd redis.UniversalClient) func(*CodeOptions) {
+	return func(options *CodeOptions) {
+		if rd != nil {
+			getCodeOptionsOrSetDefault(options).redis = rd
+		}
+	}
+}
+
+// WithCodePrefix redis key prefix, use different prefix for different scenes, e.g. sms.code.login
+func WithCodePrefix(prefix string) func(*CodeOptions) {
+	return func(options *CodeOptions) {
+		if prefix != "" {
+			getCodeOptionsOrSetDefault(options).prefix = prefix
+		}
+	}
+}
+
+func WithCodeExpire(second int) func(*CodeOptions) {
+	return func(options *CodeOptions) {
+		if second > 0 {
+			getCodeOptionsOrSetDefault(options).expire = second
+		}
+	}
+}
+
+func WithCodeLength(length int) func(*CodeOptions) {
+	return func(options *CodeOptions) {
+		if length > 0 {
+			getCodeOptionsOrSetDefault(options).length = length
+		}
+	}
+}
+
+func WithCodeMaxAttempts(count int) func(*CodeOptions) {
+	return func(options *CodeOptions) {
+		if count > 0 {
+			getCodeOptionsOrSetDefault(options).maxAttempts = count
+		}
+	}
+}
+
+func WithCodeTemplate(template string) func(*CodeOptions) {
+	return func(options *CodeOptions) {
+		if template != "" {
+			getCodeOptionsOrSetDefault(options).template = template
+		}
+	}
+}
+
+func WithCodeSign(sign string) func(*CodeOptions) {
+	return func(options *CodeOptions) {
+		if sign != "" {
+			getCodeOptionsOrSetDefault(options).sign = sign
+		}
+	}
+}
+
+// WithCodeParams custom template params, default Params("code", code)
+func WithCodeParams(f func(code string) []Param) func(*CodeOptions) {
+	return func(options *CodeOptions) {
+		if f != nil {
+			getCodeOptionsOrSetDefault(options).params = f
+		}
+	}
+}
+
+func getCodeOptionsOrSetDefault(options *CodeOptions) *CodeOptions {
+	if options == nil {
+		return &CodeOptions{
+			prefix:      "sms.code",
+			expire:      300,
+			length:      6,
+			maxAttempts: 5,
+			params: func(code string) []Param {
+				return Params("code", code)
+			},
+		}
+	}
+	return options
+}
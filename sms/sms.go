@@ -0,0 +1,86 @@
+package sms
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+)
+
+// Sender deliver sms, implemented by aliyun/tencent/twilio
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Message is the sms content, Template is the template code of provider(or text template of twilio),
+// Params keep the order since some providers(e.g. tencent) only accept positional params
+type Message struct {
+	Phone    string  `json:"phone"`
+	Sign     string  `json:"sign,omitempty"`
+	Template string  `json:"template"`
+	Params   []Param `json:"params,omitempty"`
+}
+
+type Param struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Params build ordered params by key value pairs, e.g. Params("code", "123456", "minute", "5")
+func Params(kv ...string) (rp []Param) {
+	for i := 0; i+1 < len(kv); i += 2 {
+		rp = append(rp, Param{
+			Key:   kv[i],
+			Value: kv[i+1],
+		})
+	}
+	return
+}
+
+func (m Message) validate() (err error) {
+	if m.Phone == "" {
+		err = ErrPhoneNil
+		return
+	}
+	if m.Template == "" {
+		err = ErrTemplateNil
+	}
+	return
+}
+
+func (m Message) paramMap() (rp map[string]string) {
+	rp = make(map[string]string, len(m.Params))
+	for _, item := range m.Params {
+		rp[item.Key] = item.Value
+	}
+	return
+}
+
+func (m Message) paramValues() (rp []string) {
+	rp = make([]string, 0, len(m.Params))
+	for _, item := range m.Params {
+		rp = append(rp, item.Value)
+	}
+	return
+}
+
+func do(client *http.Client, r *http.Request) (rp []byte, err error) {
+	res, err := client.Do(r)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	defer res.Body.Close()
+	rp, err = io.ReadAll(res.Body)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		if len(rp) > 1024 {
+			rp = rp[:1024]
+		}
+		err = errors.Wrapf(ErrInvalidStatusCode, "%d %s", res.StatusCode, rp)
+	}
+	return
+}
@@ -0,0 +1,265 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-cinch/common/middleware/ratelimit"
+	"github.com/redis/go-redis/v9"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAliyun(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if query.Get("PhoneNumbers") == "13800000001" {
+			_, _ = w.Write([]byte(`{"Code":"isv.BUSINESS_LIMIT_CONTROL","Message":"limited"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"Code":"OK","Message":"OK"}`))
+	}))
+	defer srv.Close()
+	a, err := NewAliyun(WithAliyunUrl(srv.URL), WithAliyunKey("key"), WithAliyunSecret("secret"), WithAliyunSign("cinch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = a.Send(context.Background(), Message{
+		Phone:    "13800000000",
+		Template: "SMS_1",
+		Params:   Params("code", "123456"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("SignName") != "cinch" || query.Get("TemplateParam") != `{"code":"123456"}` {
+		t.Fatalf("unexpected query %v", query)
+	}
+	// verify signature
+	signature := query.Get("Signature")
+	query.Del("Signature")
+	if a.sign(http.MethodGet, aliyunCanonicalize(query)) != signature {
+		t.Fatal("signature mismatch")
+	}
+
+	err = a.Send(context.Background(), Message{
+		Phone:    "13800000001",
+		Template: "SMS_1",
+	})
+	if !errors.Is(err, ErrSendFailed) {
+		t.Fatalf("expect send failed but got %v", err)
+	}
+}
+
+func TestTencent(t *testing.T) {
+	var body tencentRequest
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"Response":{"SendStatusSet":[{"Code":"Ok"}],"RequestId":"1"}}`))
+	}))
+	defer srv.Close()
+	_, err := NewTencent(WithTencentKey("key"), WithTencentSecret("secret"))
+	if err != ErrAppIdNil {
+		t.Fatalf("expect app id nil but got %v", err)
+	}
+	tc, _ := NewTencent(WithTencentUrl(srv.URL), WithTencentKey("key"), WithTencentSecret("secret"), WithTencentAppId("1400000000"))
+	err = tc.Send(context.Background(), Message{
+		Phone:    "+8613800000000",
+		Template: "1",
+		Params:   Params("code", "123456", "minute", "5"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(body.TemplateParamSet, ",") != "123456,5" || body.SmsSdkAppId != "1400000000" {
+		t.Fatalf("unexpected body %+v", body)
+	}
+	if !strings.HasPrefix(auth, "TC3-HMAC-SHA256 Credential=key/") || !strings.Contains(auth, "SignedHeaders=content-type;host") {
+		t.Fatalf("unexpected authorization %s", auth)
+	}
+}
+
+func TestTwilio(t *testing.T) {
+	var form url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sid, token, _ := r.BasicAuth()
+		if r.URL.Path != "/2010-04-01/Accounts/AC1/Messages.json" || sid != "AC1" || token != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code":20003,"message":"Authenticate"}`))
+			return
+		}
+		_ = r.ParseForm()
+		form = r.PostForm
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid":"SM1"}`))
+	}))
+	defer srv.Close()
+	tw, err := NewTwilio(
+		WithTwilioUrl(srv.URL),
+		WithTwilioSid("AC1"),
+		WithTwilioToken("token"),
+		WithTwilioFrom("+15550000000"),
+		WithTwilioTemplates(map[string]string{"code": "Your code is {{.code}}"}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tw.Send(context.Background(), Message{
+		Phone:    "+15551111111",
+		Template: "code",
+		Params:   Params("code", "123456"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if form.Get("Body") != "Your code is 123456" || form.Get("From") != "+15550000000" {
+		t.Fatalf("unexpected form %v", form)
+	}
+
+	tw, _ = NewTwilio(WithTwilioUrl(srv.URL), WithTwilioSid("AC1"), WithTwilioToken("invalid"), WithTwilioFrom("MG1"))
+	err = tw.Send(context.Background(), Message{
+		Phone:    "+15551111111",
+		Template: "hello",
+	})
+	if !errors.Is(err, ErrSendFailed) || !strings.Contains(err.Error(), "20003") {
+		t.Fatalf("expect send failed but got %v", err)
+	}
+}
+
+type memorySender struct {
+	list []Message
+	err  error
+}
+
+func (m *memorySender) Send(ctx context.Context, msg Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.list = append(m.list, msg)
+	return nil
+}
+
+func TestLimit(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	sender := &memorySender{}
+	if _, err := Limit(sender); !errors.Is(err, ErrRedisNil) {
+		t.Fatalf("expect redis nil but got %v", err)
+	}
+	l, err := Limit(sender, WithLimitRedis(client), WithLimitRule(2, 60))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	msg := Message{
+		Phone:    "13800000000",
+		Template: "SMS_1",
+	}
+	for i := 0; i < 2; i++ {
+		if err := l.Send(ctx, msg); err != nil {
+			t.Fatalf("Send() %d error = %v", i, err)
+		}
+	}
+	var e ratelimit.ErrRateLimited
+	if err := l.Send(ctx, msg); !errors.As(err, &e) {
+		t.Fatalf("expect rate limited but got %v", err)
+	}
+	// other phone is not affected
+	msg.Phone = "13800000001"
+	if err := l.Send(ctx, msg); err != nil {
+		t.Fatal(err)
+	}
+	if len(sender.list) != 3 {
+		t.Fatalf("unexpected sent count %d", len(sender.list))
+	}
+
+	// rejected by the later rule does not use quota of the earlier rule
+	l, _ = Limit(sender, WithLimitRedis(client), WithLimitPrefix("sms.rules"), WithLimitRule(2, 60), WithLimitRule(1, 3600))
+	sender.list = nil
+	for i := 0; i < 3; i++ {
+		_ = l.Send(ctx, msg)
+	}
+	if len(sender.list) != 1 {
+		t.Fatalf("unexpected sent count %d", len(sender.list))
+	}
+	if n, _ := client.ZCard(ctx, "sms.rules.60.{13800000001}").Result(); n != 1 {
+		t.Fatalf("unexpected used quota %d", n)
+	}
+
+	// redis is unavailable, fail closed
+	s.Close()
+	msg.Phone = "13800000002"
+	if err = l.Send(ctx, msg); err == nil {
+		t.Fatal("expect error when redis is unavailable")
+	}
+	if len(sender.list) != 1 {
+		t.Fatalf("unexpected sent count %d", len(sender.list))
+	}
+}
+
+func TestCode(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	sender := &memorySender{}
+	c, err := NewCode(sender, WithCodeRedis(client), WithCodeTemplate("SMS_1"), WithCodeMaxAttempts(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	phone := "13800000000"
+
+	err = c.Issue(ctx, phone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := sender.list[0].Params[0].Value
+	if len(code) != 6 {
+		t.Fatalf("unexpected code %s", code)
+	}
+	if err = c.Verify(ctx, phone, "wrong"); err != ErrCodeMismatch {
+		t.Fatalf("expect mismatch but got %v", err)
+	}
+	if err = c.Verify(ctx, phone, code); err != nil {
+		t.Fatal(err)
+	}
+	// one-time
+	if err = c.Verify(ctx, phone, code); err != ErrCodeNotFound {
+		t.Fatalf("expect not found but got %v", err)
+	}
+
+	// attempts exceeded
+	_ = c.Issue(ctx, phone)
+	code = sender.list[1].Params[0].Value
+	_ = c.Verify(ctx, phone, "wrong")
+	_ = c.Verify(ctx, phone, "wrong")
+	if err = c.Verify(ctx, phone, "wrong"); err != ErrCodeAttempts {
+		t.Fatalf("expect attempts exceeded but got %v", err)
+	}
+	if err = c.Verify(ctx, phone, code); err != ErrCodeNotFound {
+		t.Fatalf("expect not found but got %v", err)
+	}
+
+	// expired
+	_ = c.Issue(ctx, phone)
+	s.FastForward(301 * time.Second)
+	if err = c.Verify(ctx, phone, sender.list[2].Params[0].Value); err != ErrCodeNotFound {
+		t.Fatalf("expect not found but got %v", err)
+	}
+
+	// code is removed if send failed
+	sender.err = errors.New("gateway error")
+	if err = c.Issue(ctx, phone); err == nil {
+		t.Fatal("expect send error")
+	}
+	if s.Exists("sms.code." + phone) {
+		t.Fatal("code should be removed")
+	}
+}
@@ -0,0 +1,165 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tencentService     = "sms"
+	tencentAlgorithm   = "TC3-HMAC-SHA256"
+	tencentContentType = "application/json; charset=utf-8"
+)
+
+// Tencent send sms by tencent cloud api 3.0, signature version TC3-HMAC-SHA256
+type Tencent struct {
+	ops  TencentOptions
+	host string
+}
+
+type tencentRequest struct {
+	PhoneNumberSet   []string `json:"PhoneNumberSet"`
+	SmsSdkAppId      string   `json:"SmsSdkAppId"`
+	SignName         string   `json:"SignName,omitempty"`
+	TemplateId       string   `json:"TemplateId"`
+	TemplateParamSet []string `json:"TemplateParamSet,omitempty"`
+}
+
+type tencentReply struct {
+	Response struct {
+		SendStatusSet []struct {
+			PhoneNumber string `json:"PhoneNumber"`
+			Code        string `json:"Code"`
+			Message     string `json:"Message"`
+		} `json:"SendStatusSet"`
+		Error *struct {
+			Code    string `json:"Code"`
+			Message string `json:"Message"`
+		} `json:"Error"`
+		RequestId string `json:"RequestId"`
+	} `json:"Response"`
+}
+
+func NewTencent(options ...func(*TencentOptions)) (t *Tencent, err error) {
+	ops := getTencentOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.key == "" {
+		err = ErrKeyNil
+		return
+	}
+	if ops.secret == "" {
+		err = ErrSecretNil
+		return
+	}
+	if ops.appId == "" {
+		err = ErrAppIdNil
+		return
+	}
+	u, err := url.Parse(ops.url)
+	if err != nil {
+		err = errors.WithMessage(err, "invalid tencent url")
+		return
+	}
+	t = &Tencent{
+		ops:  *ops,
+		host: u.Host,
+	}
+	return
+}
+
+func (t *Tencent) Send(ctx context.Context, msg Message) (err error) {
+	err = msg.validate()
+	if err != nil {
+		return
+	}
+	if msg.Sign == "" {
+		msg.Sign = t.ops.sign
+	}
+	payload, _ := json.Marshal(tencentRequest{
+		PhoneNumberSet:   []string{msg.Phone},
+		SmsSdkAppId:      t.ops.appId,
+		SignName:         msg.Sign,
+		TemplateId:       msg.Template,
+		TemplateParamSet: msg.paramValues(),
+	})
+	now := time.Now()
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, t.ops.url, bytes.NewReader(payload))
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	r.Header.Set("Content-Type", tencentContentType)
+	r.Header.Set("Authorization", t.authorization(payload, now))
+	r.Header.Set("X-TC-Action", "SendSms")
+	r.Header.Set("X-TC-Version", "2021-01-11")
+	r.Header.Set("X-TC-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	r.Header.Set("X-TC-Region", t.ops.region)
+	bs, err := do(t.ops.client, r)
+	if err != nil {
+		return
+	}
+	var reply tencentReply
+	err = json.Unmarshal(bs, &reply)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	if reply.Response.Error != nil {
+		err = errors.Wrapf(ErrSendFailed, "tencent %s %s, request id: %s", reply.Response.Error.Code, reply.Response.Error.Message, reply.Response.RequestId)
+		return
+	}
+	for _, item := range reply.Response.SendStatusSet {
+		if item.Code != "Ok" {
+			err = errors.Wrapf(ErrSendFailed, "tencent %s %s, request id: %s", item.Code, item.Message, reply.Response.RequestId)
+			return
+		}
+	}
+	return
+}
+
+func (t *Tencent) authorization(payload []byte, now time.Time) string {
+	date := now.UTC().Format("2006-01-02")
+	canonical := strings.Join([]string{
+		http.MethodPost,
+		"/",
+		"",
+		"content-type:" + tencentContentType + "\nhost:" + t.host + "\n",
+		"content-type;host",
+		sha256Hex(payload),
+	}, "\n")
+	scope := strings.Join([]string{date, tencentService, "tc3_request"}, "/")
+	s := strings.Join([]string{
+		tencentAlgorithm,
+		strconv.FormatInt(now.Unix(), 10),
+		scope,
+		sha256Hex([]byte(canonical)),
+	}, "\n")
+	key := hmacSha256([]byte("TC3"+t.ops.secret), date)
+	key = hmacSha256(key, tencentService)
+	key = hmacSha256(key, "tc3_request")
+	signature := hex.EncodeToString(hmacSha256(key, s))
+	return tencentAlgorithm + " Credential=" + t.ops.key + "/" + scope + ", SignedHeaders=content-type;host, Signature=" + signature
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSha256(key []byte, s string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
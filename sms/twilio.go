@@ -0,0 +1,101 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+)
+
+// Twilio send sms by twilio messages api
+type Twilio struct {
+	ops       TwilioOptions
+	templates map[string]*template.Template
+}
+
+type twilioReply struct {
+	Sid     string `json:"sid"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func NewTwilio(options ...func(*TwilioOptions)) (t *Twilio, err error) {
+	ops := getTwilioOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.sid == "" {
+		err = ErrKeyNil
+		return
+	}
+	if ops.token == "" {
+		err = ErrSecretNil
+		return
+	}
+	if ops.from == "" {
+		err = ErrFromNil
+		return
+	}
+	t = &Twilio{
+		ops:       *ops,
+		templates: make(map[string]*template.Template, len(ops.templates)),
+	}
+	for k, v := range ops.templates {
+		t.templates[k], err = template.New(k).Parse(v)
+		if err != nil {
+			err = errors.Wrapf(err, "invalid template %s", k)
+			return
+		}
+	}
+	return
+}
+
+// Send render body by registered template, msg.Template will be used as text template if not registered
+func (t *Twilio) Send(ctx context.Context, msg Message) (err error) {
+	err = msg.validate()
+	if err != nil {
+		return
+	}
+	tpl, ok := t.templates[msg.Template]
+	if !ok {
+		tpl, err = template.New("").Parse(msg.Template)
+		if err != nil {
+			err = errors.WithStack(err)
+			return
+		}
+	}
+	var body bytes.Buffer
+	err = tpl.Execute(&body, msg.paramMap())
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	form := url.Values{}
+	form.Set("To", msg.Phone)
+	form.Set("Body", body.String())
+	if strings.HasPrefix(t.ops.from, "MG") {
+		form.Set("MessagingServiceSid", t.ops.from)
+	} else {
+		form.Set("From", t.ops.from)
+	}
+	u := strings.TrimSuffix(t.ops.url, "/") + "/2010-04-01/Accounts/" + t.ops.sid + "/Messages.json"
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, u, strings.NewReader(form.Encode()))
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	r.SetBasicAuth(t.ops.sid, t.ops.token)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	bs, err := do(t.ops.client, r)
+	if err != nil {
+		var reply twilioReply
+		if json.Unmarshal(bs, &reply) == nil && reply.Code > 0 {
+			err = errors.Wrapf(ErrSendFailed, "twilio %d %s", reply.Code, reply.Message)
+		}
+	}
+	return
+}
@@ -0,0 +1,22 @@
+package storage
+
+import "github.com/pkg/errors"
+
+var (
+	ErrEndpointNil        = errors.New("endpoint is empty")
+	ErrKeyNil             = errors.New("key is empty")
+	ErrSecretNil          = errors.New("secret is empty")
+	ErrBucketNil          = errors.New("bucket is empty")
+	ErrRootNil            = errors.New("root is empty")
+	ErrObjectNameNil      = errors.New("object name is empty")
+	ErrObjectNameInvalid  = errors.New("object name invalid")
+	ErrObjectNotFound     = errors.New("object not found")
+	ErrContentTypeInvalid = errors.New("object content type invalid")
+	ErrObjectSizeInvalid  = errors.New("object size invalid")
+	ErrPresignUnsupported = errors.New("presign unsupported, please set base url and secret")
+	ErrPresignValidated   = errors.New("presign put unsupported with validators, client upload bypasses them")
+	ErrSignatureInvalid   = errors.New("signature invalid")
+	ErrSignatureExpired   = errors.New("signature expired")
+	ErrUploadIdInvalid    = errors.New("upload id invalid")
+	ErrPartInvalid        = errors.New("part invalid")
+)
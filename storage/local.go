@@ -0,0 +1,501 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	multipartDir  = ".multipart"
+	multipartMeta = "meta.json"
+	defaultType   = "application/octet-stream"
+)
+
+// Local is the storage of local filesystem, suitable for development or single node deployment
+type Local struct {
+	ops    LocalOptions
+	prefix string
+}
+
+type multipartInfo struct {
+	Object      string `json:"object"`
+	ContentType string `json:"contentType"`
+}
+
+func NewLocal(options ...func(*LocalOptions)) (l *Local, err error) {
+	ops := getLocalOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.root == "" {
+		err = ErrRootNil
+		return
+	}
+	ops.root, err = filepath.Abs(ops.root)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	err = os.MkdirAll(ops.root, 0o755)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	l = &Local{
+		ops: *ops,
+	}
+	if ops.baseUrl != "" {
+		var u *url.URL
+		u, err = url.Parse(ops.baseUrl)
+		if err != nil {
+			err = errors.WithMessage(err, "invalid base url")
+			return
+		}
+		l.ops.baseUrl = strings.TrimSuffix(ops.baseUrl, "/")
+		l.prefix = strings.TrimSuffix(u.Path, "/")
+	}
+	return
+}
+
+func (l *Local) Put(ctx context.Context, object string, reader io.Reader, size int64, contentType string) (rp *Object, err error) {
+	name, err := l.name(object)
+	if err != nil {
+		return
+	}
+	err = l.write(name, reader, size)
+	if err != nil {
+		return
+	}
+	rp, err = l.Stat(ctx, name)
+	if err != nil {
+		return
+	}
+	if contentType != "" {
+		rp.ContentType = contentType
+	}
+	return
+}
+
+func (l *Local) Get(ctx context.Context, object string) (rp io.ReadCloser, err error) {
+	name, err := l.name(object)
+	if err != nil {
+		return
+	}
+	f, err := os.Open(l.file(name))
+	if err != nil {
+		err = localError(err)
+		return
+	}
+	rp = f
+	return
+}
+
+func (l *Local) Stat(ctx context.Context, object string) (rp *Object, err error) {
+	name, err := l.name(object)
+	if err != nil {
+		return
+	}
+	info, err := os.Stat(l.file(name))
+	if err != nil {
+		err = localError(err)
+		return
+	}
+	if info.IsDir() {
+		err = errors.WithStack(ErrObjectNotFound)
+		return
+	}
+	rp = &Object{
+		Name:         name,
+		Size:         info.Size(),
+		ContentType:  contentTypeByName(name),
+		ETag:         fmt.Sprintf("%x-%x", info.ModTime().UnixNano(), info.Size()),
+		LastModified: info.ModTime(),
+	}
+	return
+}
+
+func (l *Local) Delete(ctx context.Context, object string) (err error) {
+	name, err := l.name(object)
+	if err != nil {
+		return
+	}
+	err = os.Remove(l.file(name))
+	// same as s3, delete a non-existent object is not an error
+	if os.IsNotExist(err) {
+		err = nil
+	}
+	err = errors.WithStack(err)
+	return
+}
+
+func (l *Local) PresignGet(ctx context.Context, object string, expire time.Duration) (rp string, err error) {
+	return l.presign(http.MethodGet, object, expire)
+}
+
+func (l *Local) PresignPut(ctx context.Context, object string, expire time.Duration) (rp string, err error) {
+	return l.presign(http.MethodPut, object, expire)
+}
+
+func (l *Local) presign(method, object string, expire time.Duration) (rp string, err error) {
+	if l.ops.baseUrl == "" || l.ops.secret == "" {
+		err = ErrPresignUnsupported
+		return
+	}
+	name, err := l.name(object)
+	if err != nil {
+		return
+	}
+	expires := strconv.FormatInt(time.Now().Add(expire).Unix(), 10)
+	q := url.Values{}
+	q.Set("expires", expires)
+	q.Set("signature", l.sign(method, name, expires))
+	rp = strings.Join([]string{l.ops.baseUrl, "/", (&url.URL{Path: name}).EscapedPath(), "?", q.Encode()}, "")
+	return
+}
+
+func (l *Local) NewMultipart(ctx context.Context, object, contentType string) (rp string, err error) {
+	name, err := l.name(object)
+	if err != nil {
+		return
+	}
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	rp = hex.EncodeToString(b)
+	dir := l.uploadDir(rp)
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	bs, _ := json.Marshal(multipartInfo{
+		Object:      name,
+		ContentType: contentType,
+	})
+	err = os.WriteFile(filepath.Join(dir, multipartMeta), bs, 0o644)
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+func (l *Local) UploadPart(ctx context.Context, object, uploadId string, number int, reader io.Reader, size int64) (rp *Part, err error) {
+	if number < 1 {
+		err = ErrPartInvalid
+		return
+	}
+	_, err = l.multipart(object, uploadId)
+	if err != nil {
+		return
+	}
+	f, err := os.Create(filepath.Join(l.uploadDir(uploadId), strconv.Itoa(number)))
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	defer f.Close()
+	h := md5.New()
+	n, err := io.Copy(io.MultiWriter(f, h), reader)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	if size >= 0 && n != size {
+		err = errors.Wrapf(ErrObjectSizeInvalid, "expect %d but got %d", size, n)
+		return
+	}
+	rp = &Part{
+		Number: number,
+		ETag:   hex.EncodeToString(h.Sum(nil)),
+		Size:   n,
+	}
+	return
+}
+
+func (l *Local) CompleteMultipart(ctx context.Context, object, uploadId string, parts []Part) (rp *Object, err error) {
+	info, err := l.multipart(object, uploadId)
+	if err != nil {
+		return
+	}
+	if len(parts) == 0 {
+		err = ErrPartInvalid
+		return
+	}
+	list := make([]Part, len(parts))
+	copy(list, parts)
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Number < list[j].Number
+	})
+	readers := make([]io.Reader, 0, len(list))
+	defer func() {
+		for _, item := range readers {
+			_ = item.(io.Closer).Close()
+		}
+	}()
+	for i, item := range list {
+		if i > 0 && list[i-1].Number == item.Number {
+			err = errors.Wrapf(ErrPartInvalid, "duplicate part %d", item.Number)
+			return
+		}
+		var f *os.File
+		f, err = os.Open(filepath.Join(l.uploadDir(uploadId), strconv.Itoa(item.Number)))
+		if err != nil {
+			err = errors.Wrapf(ErrPartInvalid, "part %d not found", item.Number)
+			return
+		}
+		readers = append(readers, f)
+	}
+	err = l.write(info.Object, io.MultiReader(readers...), -1)
+	if err != nil {
+		return
+	}
+	_ = os.RemoveAll(l.uploadDir(uploadId))
+	rp, err = l.Stat(ctx, info.Object)
+	if err != nil {
+		return
+	}
+	if info.ContentType != "" {
+		rp.ContentType = info.ContentType
+	}
+	return
+}
+
+func (l *Local) partsSize(ctx context.Context, object, uploadId string, parts []Part) (rp int64, err error) {
+	_, err = l.multipart(object, uploadId)
+	if err != nil {
+		return
+	}
+	numbers := make(map[int]struct{}, len(parts))
+	for _, item := range parts {
+		if _, ok := numbers[item.Number]; ok || item.Number < 1 {
+			err = errors.Wrapf(ErrPartInvalid, "invalid part %d", item.Number)
+			return
+		}
+		numbers[item.Number] = struct{}{}
+		var info os.FileInfo
+		info, err = os.Stat(filepath.Join(l.uploadDir(uploadId), strconv.Itoa(item.Number)))
+		if err != nil {
+			err = errors.Wrapf(ErrPartInvalid, "part %d not found", item.Number)
+			return
+		}
+		rp += info.Size()
+	}
+	return
+}
+
+func (l *Local) AbortMultipart(ctx context.Context, object, uploadId string) (err error) {
+	_, err = l.multipart(object, uploadId)
+	if err != nil {
+		return
+	}
+	err = os.RemoveAll(l.uploadDir(uploadId))
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+// Handler serve presigned url, GET/HEAD download and PUT upload are supported, PUT upload is checked by validators,
+// mount it on the path of base url, e.g. http.Handle("/files/", l.Handler(MaxSize(10*1024*1024)))
+func (l *Local) Handler(validators ...Validator) http.Handler {
+	var s Storage = l
+	if len(validators) > 0 {
+		s = Validate(l, validators...)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := r.Method
+		if method == http.MethodHead {
+			method = http.MethodGet
+		}
+		if method != http.MethodGet && method != http.MethodPut {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		object := strings.TrimPrefix(r.URL.Path, l.prefix)
+		err := l.verify(method, object, r.URL.Query().Get("expires"), r.URL.Query().Get("signature"))
+		if err != nil {
+			http.Error(w, err.Error(), httpStatus(err))
+			return
+		}
+		if method == http.MethodPut {
+			_, err = s.Put(r.Context(), object, r.Body, r.ContentLength, r.Header.Get("Content-Type"))
+			if err != nil {
+				http.Error(w, err.Error(), httpStatus(err))
+			}
+			return
+		}
+		name, _ := l.name(object)
+		f, err := os.Open(l.file(name))
+		if err != nil {
+			err = localError(err)
+			http.Error(w, err.Error(), httpStatus(err))
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			http.Error(w, ErrObjectNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypeByName(name))
+		// ServeContent handles range and conditional requests
+		http.ServeContent(w, r, name, info.ModTime(), f)
+	})
+}
+
+func (l *Local) verify(method, object, expires, signature string) (err error) {
+	if l.ops.secret == "" {
+		err = ErrPresignUnsupported
+		return
+	}
+	name, err := l.name(object)
+	if err != nil {
+		return
+	}
+	if !hmac.Equal([]byte(signature), []byte(l.sign(method, name, expires))) {
+		err = ErrSignatureInvalid
+		return
+	}
+	ts, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		err = ErrSignatureInvalid
+		return
+	}
+	if time.Now().Unix() > ts {
+		err = ErrSignatureExpired
+	}
+	return
+}
+
+func (l *Local) sign(method, object, expires string) string {
+	h := hmac.New(sha256.New, []byte(l.ops.secret))
+	h.Write([]byte(strings.Join([]string{method, object, expires}, "\n")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// write save to a temp file first, readers never see a partial object
+func (l *Local) write(name string, reader io.Reader, size int64) (err error) {
+	file := l.file(name)
+	err = os.MkdirAll(filepath.Dir(file), 0o755)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".tmp-*")
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, reader)
+	if err != nil {
+		_ = tmp.Close()
+		err = errors.WithStack(err)
+		return
+	}
+	err = tmp.Close()
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	if size >= 0 && n != size {
+		err = errors.Wrapf(ErrObjectSizeInvalid, "expect %d but got %d", size, n)
+		return
+	}
+	err = os.Rename(tmp.Name(), file)
+	if err != nil {
+		err = errors.WithStack(err)
+	}
+	return
+}
+
+func (l *Local) multipart(object, uploadId string) (rp *multipartInfo, err error) {
+	name, err := l.name(object)
+	if err != nil {
+		return
+	}
+	// upload id is hex, avoid path traversal
+	if _, e := hex.DecodeString(uploadId); uploadId == "" || e != nil {
+		err = ErrUploadIdInvalid
+		return
+	}
+	bs, err := os.ReadFile(filepath.Join(l.uploadDir(uploadId), multipartMeta))
+	if err != nil {
+		err = ErrUploadIdInvalid
+		return
+	}
+	var info multipartInfo
+	err = json.Unmarshal(bs, &info)
+	if err != nil || info.Object != name {
+		err = ErrUploadIdInvalid
+		return
+	}
+	rp = &info
+	return
+}
+
+// name clean object name, objects can not escape from root
+func (l *Local) name(object string) (rp string, err error) {
+	rp = strings.TrimPrefix(path.Clean("/"+object), "/")
+	if rp == "" {
+		err = ErrObjectNameNil
+		return
+	}
+	first := strings.Split(rp, "/")[0]
+	if first == multipartDir || strings.HasPrefix(path.Base(rp), ".tmp-") {
+		err = ErrObjectNameInvalid
+	}
+	return
+}
+
+func (l *Local) file(name string) string {
+	return filepath.Join(l.ops.root, filepath.FromSlash(name))
+}
+
+func (l *Local) uploadDir(uploadId string) string {
+	return filepath.Join(l.ops.root, multipartDir, uploadId)
+}
+
+func contentTypeByName(name string) (rp string) {
+	rp = mime.TypeByExtension(path.Ext(name))
+	if rp == "" {
+		rp = defaultType
+	}
+	return
+}
+
+func localError(err error) error {
+	if os.IsNotExist(err) {
+		return errors.WithStack(ErrObjectNotFound)
+	}
+	return errors.WithStack(err)
+}
+
+func httpStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrSignatureInvalid), errors.Is(err, ErrSignatureExpired):
+		return http.StatusForbidden
+	case errors.Is(err, ErrObjectNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrObjectNameNil), errors.Is(err, ErrObjectNameInvalid), errors.Is(err, ErrObjectSizeInvalid), errors.Is(err, ErrContentTypeInvalid), errors.Is(err, ErrPartInvalid):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLocal(t *testing.T, options ...func(*LocalOptions)) *Local {
+	l, err := NewLocal(append([]func(*LocalOptions){WithLocalRoot(t.TempDir())}, options...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	l := newLocal(t)
+
+	obj, err := l.Put(ctx, "a/b.txt", strings.NewReader("hello"), 5, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.Size != 5 || !strings.HasPrefix(obj.ContentType, "text/plain") {
+		t.Fatalf("unexpected object %+v", obj)
+	}
+
+	r, err := l.Get(ctx, "/a/../a/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, _ := io.ReadAll(r)
+	_ = r.Close()
+	if string(bs) != "hello" {
+		t.Fatalf("unexpected content %s", bs)
+	}
+
+	// size mismatch
+	_, err = l.Put(ctx, "c.txt", strings.NewReader("hello"), 3, "")
+	if !errors.Is(err, ErrObjectSizeInvalid) {
+		t.Fatalf("expect size invalid but got %v", err)
+	}
+	_, err = l.Stat(ctx, "c.txt")
+	if !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("expect not found but got %v", err)
+	}
+
+	// can not escape from root
+	obj, err = l.Put(ctx, "../../d.txt", strings.NewReader("d"), 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.Name != "d.txt" {
+		t.Fatalf("unexpected name %s", obj.Name)
+	}
+	_, err = l.Put(ctx, ".multipart/x", strings.NewReader("x"), 1, "")
+	if !errors.Is(err, ErrObjectNameInvalid) {
+		t.Fatalf("expect name invalid but got %v", err)
+	}
+
+	err = l.Delete(ctx, "a/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = l.Delete(ctx, "a/b.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = l.Get(ctx, "a/b.txt")
+	if !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("expect not found but got %v", err)
+	}
+}
+
+func TestLocalMultipart(t *testing.T) {
+	ctx := context.Background()
+	l := newLocal(t)
+
+	uploadId, err := l.NewMultipart(ctx, "big.bin", "application/zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, err := l.UploadPart(ctx, "big.bin", uploadId, 2, strings.NewReader("world"), 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p1, err := l.UploadPart(ctx, "big.bin", uploadId, 1, strings.NewReader("hello "), 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = l.UploadPart(ctx, "other.bin", uploadId, 3, strings.NewReader("x"), 1)
+	if !errors.Is(err, ErrUploadIdInvalid) {
+		t.Fatalf("expect upload id invalid but got %v", err)
+	}
+	_, err = l.CompleteMultipart(ctx, "big.bin", uploadId, []Part{*p1, {Number: 3}})
+	if !errors.Is(err, ErrPartInvalid) {
+		t.Fatalf("expect part invalid but got %v", err)
+	}
+
+	obj, err := l.CompleteMultipart(ctx, "big.bin", uploadId, []Part{*p2, *p1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.Size != 11 || obj.ContentType != "application/zip" {
+		t.Fatalf("unexpected object %+v", obj)
+	}
+	r, _ := l.Get(ctx, "big.bin")
+	bs, _ := io.ReadAll(r)
+	_ = r.Close()
+	if string(bs) != "hello world" {
+		t.Fatalf("unexpected content %s", bs)
+	}
+
+	// upload dir is removed after completed
+	_, err = l.UploadPart(ctx, "big.bin", uploadId, 1, strings.NewReader("x"), 1)
+	if !errors.Is(err, ErrUploadIdInvalid) {
+		t.Fatalf("expect upload id invalid but got %v", err)
+	}
+
+	uploadId, _ = l.NewMultipart(ctx, "abort.bin", "")
+	err = l.AbortMultipart(ctx, "abort.bin", uploadId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = l.AbortMultipart(ctx, "abort.bin", "../..")
+	if !errors.Is(err, ErrUploadIdInvalid) {
+		t.Fatalf("expect upload id invalid but got %v", err)
+	}
+}
+
+func TestLocalPresign(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := newLocal(t).PresignGet(ctx, "a.txt", time.Minute)
+	if !errors.Is(err, ErrPresignUnsupported) {
+		t.Fatalf("expect presign unsupported but got %v", err)
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	l := newLocal(t, WithLocalBaseUrl(srv.URL+"/files/"), WithLocalSecret("secret"))
+	mux.Handle("/files/", l.Handler())
+
+	u, err := l.PresignPut(ctx, "dir/a b.txt", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, u, strings.NewReader("hello"))
+	rp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rp.Body.Close()
+	if rp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d", rp.StatusCode)
+	}
+
+	// put signature can not be used to download
+	rp, _ = http.Get(u)
+	_ = rp.Body.Close()
+	if rp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unexpected status %d", rp.StatusCode)
+	}
+
+	u, _ = l.PresignGet(ctx, "dir/a b.txt", time.Minute)
+	rp, err = http.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, _ := io.ReadAll(rp.Body)
+	_ = rp.Body.Close()
+	if rp.StatusCode != http.StatusOK || string(bs) != "hello" {
+		t.Fatalf("unexpected response %d %s", rp.StatusCode, bs)
+	}
+
+	u, _ = l.PresignGet(ctx, "dir/a b.txt", -time.Minute)
+	rp, _ = http.Get(u)
+	_ = rp.Body.Close()
+	if rp.StatusCode != http.StatusForbidden {
+		t.Fatalf("unexpected status %d", rp.StatusCode)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	s := Validate(newLocal(t), MaxSize(5), ContentTypes("image/*", "text/plain"))
+
+	_, err := s.Put(ctx, "a.png", bytes.NewReader(make([]byte, 3)), 3, "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Put(ctx, "b.png", bytes.NewReader(make([]byte, 6)), 6, "image/png")
+	if !errors.Is(err, ErrObjectSizeInvalid) {
+		t.Fatalf("expect size invalid but got %v", err)
+	}
+	_, err = s.Put(ctx, "c.txt", strings.NewReader("c"), 1, "text/plain; charset=utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.Put(ctx, "d.zip", strings.NewReader("d"), 1, "application/zip")
+	if !errors.Is(err, ErrContentTypeInvalid) {
+		t.Fatalf("expect content type invalid but got %v", err)
+	}
+
+	// unknown size is checked while uploading, the existing object is kept
+	_, err = s.Put(ctx, "a.png", bytes.NewReader(make([]byte, 6)), -1, "image/png")
+	if !errors.Is(err, ErrObjectSizeInvalid) {
+		t.Fatalf("expect size invalid but got %v", err)
+	}
+	if obj, e := s.Stat(ctx, "a.png"); e != nil || obj.Size != 3 {
+		t.Fatalf("expect existing object but got %v %v", obj, e)
+	}
+	_, err = s.Put(ctx, "e.png", bytes.NewReader(make([]byte, 6)), -1, "image/png")
+	if !errors.Is(err, ErrObjectSizeInvalid) {
+		t.Fatalf("expect size invalid but got %v", err)
+	}
+	_, err = s.Stat(ctx, "e.png")
+	if !errors.Is(err, ErrObjectNotFound) {
+		t.Fatalf("expect not found but got %v", err)
+	}
+	// content type by name if empty
+	_, err = s.Put(ctx, "e.html", strings.NewReader("e"), -1, "")
+	if !errors.Is(err, ErrContentTypeInvalid) {
+		t.Fatalf("expect content type invalid but got %v", err)
+	}
+
+	// multipart total size is checked before completed, the existing object is kept
+	uploadId, err := s.NewMultipart(ctx, "a.png", "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p1, _ := s.UploadPart(ctx, "a.png", uploadId, 1, strings.NewReader("abc"), 3)
+	p2, _ := s.UploadPart(ctx, "a.png", uploadId, 2, strings.NewReader("def"), 3)
+	// part size from client is untrusted
+	p2.Size = 0
+	_, err = s.CompleteMultipart(ctx, "a.png", uploadId, []Part{*p1, *p2})
+	if !errors.Is(err, ErrObjectSizeInvalid) {
+		t.Fatalf("expect size invalid but got %v", err)
+	}
+	if obj, e := s.Stat(ctx, "a.png"); e != nil || obj.Size != 3 {
+		t.Fatalf("expect existing object but got %v %v", obj, e)
+	}
+	obj, err := s.CompleteMultipart(ctx, "a.png", uploadId, []Part{*p1})
+	if err != nil || obj.Size != 3 {
+		t.Fatalf("unexpected object %v %v", obj, err)
+	}
+
+	// presigned put bypasses validators
+	l := newLocal(t, WithLocalBaseUrl("http://127.0.0.1/files"), WithLocalSecret("secret"))
+	_, err = Validate(l, MaxSize(5)).PresignPut(ctx, "a.png", time.Minute)
+	if !errors.Is(err, ErrPresignValidated) {
+		t.Fatalf("expect presign validated but got %v", err)
+	}
+}
+
+func TestLocalHandlerValidate(t *testing.T) {
+	ctx := context.Background()
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	l := newLocal(t, WithLocalBaseUrl(srv.URL+"/files/"), WithLocalSecret("secret"))
+	mux.Handle("/files/", l.Handler(MaxSize(5), ContentTypes("text/plain")))
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		status      int
+	}{
+		{name: "ok", body: "hello", contentType: "text/plain", status: http.StatusOK},
+		{name: "too large", body: "hello world", contentType: "text/plain", status: http.StatusBadRequest},
+		{name: "invalid type", body: "<a>", contentType: "text/html", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, _ := l.PresignPut(ctx, "a.txt", time.Minute)
+			req, _ := http.NewRequest(http.MethodPut, u, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = rp.Body.Close()
+			if rp.StatusCode != tt.status {
+				t.Fatalf("unexpected status %d", rp.StatusCode)
+			}
+		})
+	}
+	if obj, err := l.Stat(ctx, "a.txt"); err != nil || obj.Size != 5 {
+		t.Fatalf("expect first object but got %v %v", obj, err)
+	}
+}
@@ -0,0 +1,111 @@
+package storage
+
+type S3Options struct {
+	endpoint string // s3/minio endpoint, e.g. s3.amazonaws.com, 127.0.0.1:9000
+	key      string // access key id
+	secret   string // access secret
+	region   string // bucket region
+	bucket   string // bucket name
+	ssl      bool   // use https or not
+	partSize uint64 // part size of auto multipart upload when object size is unknown
+}
+
+func WithS3Endpoint(endpoint string) func(*S3Options) {
+	return func(options *S3Options) {
+		if endpoint != "" {
+			getS3OptionsOrSetDefault(options).endpoint = endpoint
+		}
+	}
+}
+
+func WithS3Key(key string) func(*S3Options) {
+	return func(options *S3Options) {
+		if key != "" {
+			getS3OptionsOrSetDefault(options).key = key
+		}
+	}
+}
+
+func WithS3Secret(secret string) func(*S3Options) {
+	return func(options *S3Options) {
+		if secret != "" {
+			getS3OptionsOrSetDefault(options).secret = secret
+		}
+	}
+}
+
+func WithS3Region(region string) func(*S3Options) {
+	return func(options *S3Options) {
+		if region != "" {
+			getS3OptionsOrSetDefault(options).region = region
+		}
+	}
+}
+
+func WithS3Bucket(bucket string) func(*S3Options) {
+	return func(options *S3Options) {
+		if bucket != "" {
+			getS3OptionsOrSetDefault(options).bucket = bucket
+		}
+	}
+}
+
+func WithS3SSL(ssl bool) func(*S3Options) {
+	return func(options *S3Options) {
+		getS3OptionsOrSetDefault(options).ssl = ssl
+	}
+}
+
+func WithS3PartSize(size uint64) func(*S3Options) {
+	return func(options *S3Options) {
+		if size > 0 {
+			getS3OptionsOrSetDefault(options).partSize = size
+		}
+	}
+}
+
+func getS3OptionsOrSetDefault(options *S3Options) *S3Options {
+	if options == nil {
+		return &S3Options{
+			partSize: 16 * 1024 * 1024, // 16MB
+		}
+	}
+	return options
+}
+
+type LocalOptions struct {
+	root    string // root dir of objects
+	baseUrl string // base url of Handler, used to generate presigned url
+	secret  string // hmac secret of presigned url
+}
+
+func WithLocalRoot(root string) func(*LocalOptions) {
+	return func(options *LocalOptions) {
+		if root != "" {
+			getLocalOptionsOrSetDefault(options).root = root
+		}
+	}
+}
+
+func WithLocalBaseUrl(baseUrl string) func(*LocalOptions) {
+	return func(options *LocalOptions) {
+		if baseUrl != "" {
+			getLocalOptionsOrSetDefault(options).baseUrl = baseUrl
+		}
+	}
+}
+
+func WithLocalSecret(secret string) func(*LocalOptions) {
+	return func(options *LocalOptions) {
+		if secret != "" {
+			getLocalOptionsOrSetDefault(options).secret = secret
+		}
+	}
+}
+
+func getLocalOptionsOrSetDefault(options *LocalOptions) *LocalOptions {
+	if options == nil {
+		return &LocalOptions{}
+	}
+	return options
+}
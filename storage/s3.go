@@ -0,0 +1,287 @@
+package storage
+
+import (
+	"context"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// S3 is the storage of aws s3 or any s3 compatible service, e.g. minio
+type S3 struct {
+	ops    S3Options
+	client *minio.Client
+	core   *minio.Core
+}
+
+func NewS3(options ...func(*S3Options)) (s *S3, err error) {
+	ops := getS3OptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	if ops.endpoint == "" {
+		err = ErrEndpointNil
+		return
+	}
+	if ops.key == "" {
+		err = ErrKeyNil
+		return
+	}
+	if ops.secret == "" {
+		err = ErrSecretNil
+		return
+	}
+	if ops.bucket == "" {
+		err = ErrBucketNil
+		return
+	}
+	core, err := minio.NewCore(ops.endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(ops.key, ops.secret, ""),
+		Secure: ops.ssl,
+		Region: ops.region,
+	})
+	if err != nil {
+		err = errors.WithMessage(err, "initialize s3 client failed")
+		return
+	}
+	s = &S3{
+		ops:    *ops,
+		client: core.Client,
+		core:   core,
+	}
+	return
+}
+
+func (s *S3) Put(ctx context.Context, object string, reader io.Reader, size int64, contentType string) (rp *Object, err error) {
+	if object == "" {
+		err = ErrObjectNameNil
+		return
+	}
+	info, err := s.client.PutObject(ctx, s.ops.bucket, object, reader, size, minio.PutObjectOptions{
+		ContentType: contentType,
+		PartSize:    s.ops.partSize,
+	})
+	if err != nil {
+		err = s3Error(err)
+		return
+	}
+	rp = &Object{
+		Name:         object,
+		Size:         info.Size,
+		ContentType:  contentType,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+	}
+	return
+}
+
+func (s *S3) Get(ctx context.Context, object string) (rp io.ReadCloser, err error) {
+	// GetObject is lazy, stat first to return not found immediately
+	_, err = s.Stat(ctx, object)
+	if err != nil {
+		return
+	}
+	rp, err = s.client.GetObject(ctx, s.ops.bucket, object, minio.GetObjectOptions{})
+	if err != nil {
+		err = s3Error(err)
+	}
+	return
+}
+
+func (s *S3) Stat(ctx context.Context, object string) (rp *Object, err error) {
+	if object == "" {
+		err = ErrObjectNameNil
+		return
+	}
+	info, err := s.client.StatObject(ctx, s.ops.bucket, object, minio.StatObjectOptions{})
+	if err != nil {
+		err = s3Error(err)
+		return
+	}
+	rp = &Object{
+		Name:         object,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+	}
+	return
+}
+
+func (s *S3) Delete(ctx context.Context, object string) (err error) {
+	if object == "" {
+		err = ErrObjectNameNil
+		return
+	}
+	err = s.client.RemoveObject(ctx, s.ops.bucket, object, minio.RemoveObjectOptions{})
+	if err != nil {
+		err = s3Error(err)
+	}
+	return
+}
+
+func (s *S3) PresignGet(ctx context.Context, object string, expire time.Duration) (rp string, err error) {
+	return s.presign(ctx, http.MethodGet, object, expire)
+}
+
+func (s *S3) PresignPut(ctx context.Context, object string, expire time.Duration) (rp string, err error) {
+	return s.presign(ctx, http.MethodPut, object, expire)
+}
+
+func (s *S3) presign(ctx context.Context, method, object string, expire time.Duration) (rp string, err error) {
+	if object == "" {
+		err = ErrObjectNameNil
+		return
+	}
+	u, err := s.client.Presign(ctx, method, s.ops.bucket, object, expire, nil)
+	if err != nil {
+		err = s3Error(err)
+		return
+	}
+	rp = u.String()
+	return
+}
+
+func (s *S3) NewMultipart(ctx context.Context, object, contentType string) (rp string, err error) {
+	if object == "" {
+		err = ErrObjectNameNil
+		return
+	}
+	rp, err = s.core.NewMultipartUpload(ctx, s.ops.bucket, object, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	if err != nil {
+		err = s3Error(err)
+	}
+	return
+}
+
+func (s *S3) UploadPart(ctx context.Context, object, uploadId string, number int, reader io.Reader, size int64) (rp *Part, err error) {
+	if object == "" {
+		err = ErrObjectNameNil
+		return
+	}
+	if uploadId == "" {
+		err = ErrUploadIdInvalid
+		return
+	}
+	if number < 1 {
+		err = ErrPartInvalid
+		return
+	}
+	part, err := s.core.PutObjectPart(ctx, s.ops.bucket, object, uploadId, number, reader, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		err = s3Error(err)
+		return
+	}
+	rp = &Part{
+		Number: part.PartNumber,
+		ETag:   part.ETag,
+		Size:   part.Size,
+	}
+	return
+}
+
+func (s *S3) CompleteMultipart(ctx context.Context, object, uploadId string, parts []Part) (rp *Object, err error) {
+	if object == "" {
+		err = ErrObjectNameNil
+		return
+	}
+	if uploadId == "" {
+		err = ErrUploadIdInvalid
+		return
+	}
+	if len(parts) == 0 {
+		err = ErrPartInvalid
+		return
+	}
+	list := make([]minio.CompletePart, 0, len(parts))
+	for _, item := range parts {
+		list = append(list, minio.CompletePart{
+			PartNumber: item.Number,
+			ETag:       item.ETag,
+		})
+	}
+	// s3 requires ascending part number
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].PartNumber < list[j].PartNumber
+	})
+	_, err = s.core.CompleteMultipartUpload(ctx, s.ops.bucket, object, uploadId, list, minio.PutObjectOptions{})
+	if err != nil {
+		err = s3Error(err)
+		return
+	}
+	return s.Stat(ctx, object)
+}
+
+func (s *S3) partsSize(ctx context.Context, object, uploadId string, parts []Part) (rp int64, err error) {
+	if object == "" {
+		err = ErrObjectNameNil
+		return
+	}
+	if uploadId == "" {
+		err = ErrUploadIdInvalid
+		return
+	}
+	// real size of uploaded parts
+	sizes := make(map[int]int64)
+	marker := 0
+	for {
+		var res minio.ListObjectPartsResult
+		res, err = s.core.ListObjectParts(ctx, s.ops.bucket, object, uploadId, marker, 1000)
+		if err != nil {
+			err = s3Error(err)
+			return
+		}
+		for _, item := range res.ObjectParts {
+			sizes[item.PartNumber] = item.Size
+		}
+		if !res.IsTruncated {
+			break
+		}
+		marker = res.NextPartNumberMarker
+	}
+	for _, item := range parts {
+		size, ok := sizes[item.Number]
+		if !ok {
+			err = errors.Wrapf(ErrPartInvalid, "part %d not found", item.Number)
+			return
+		}
+		delete(sizes, item.Number)
+		rp += size
+	}
+	return
+}
+
+func (s *S3) AbortMultipart(ctx context.Context, object, uploadId string) (err error) {
+	if object == "" {
+		err = ErrObjectNameNil
+		return
+	}
+	if uploadId == "" {
+		err = ErrUploadIdInvalid
+		return
+	}
+	err = s.core.AbortMultipartUpload(ctx, s.ops.bucket, object, uploadId)
+	if err != nil {
+		err = s3Error(err)
+	}
+	return
+}
+
+func s3Error(err error) error {
+	rp := minio.ToErrorResponse(err)
+	switch rp.Code {
+	case "NoSuchKey":
+		return errors.WithStack(ErrObjectNotFound)
+	case "NoSuchUpload":
+		return errors.WithStack(ErrUploadIdInvalid)
+	case "InvalidPart", "InvalidPartOrder":
+		return errors.WithStack(ErrPartInvalid)
+	}
+	return errors.WithStack(err)
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Storage abstract object storage, S3/MinIO/local filesystem are supported
+type Storage interface {
+	// Put upload object stream, size -1 means unknown size(will use multipart upload automatically for S3)
+	Put(ctx context.Context, object string, reader io.Reader, size int64, contentType string) (*Object, error)
+	// Get download object stream, remember to close it
+	Get(ctx context.Context, object string) (io.ReadCloser, error)
+	Stat(ctx context.Context, object string) (*Object, error)
+	Delete(ctx context.Context, object string) error
+	// PresignGet generate a temporary download url
+	PresignGet(ctx context.Context, object string, expire time.Duration) (string, error)
+	// PresignPut generate a temporary upload url, client can upload by http PUT directly
+	PresignPut(ctx context.Context, object string, expire time.Duration) (string, error)
+
+	// NewMultipart start a multipart upload for large files, return upload id
+	NewMultipart(ctx context.Context, object, contentType string) (string, error)
+	// UploadPart upload one part, number starts from 1
+	UploadPart(ctx context.Context, object, uploadId string, number int, reader io.Reader, size int64) (*Part, error)
+	// CompleteMultipart merge all parts in number order
+	CompleteMultipart(ctx context.Context, object, uploadId string, parts []Part) (*Object, error)
+	AbortMultipart(ctx context.Context, object, uploadId string) error
+}
+
+type Object struct {
+	Name         string    `json:"name"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"contentType"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+type Part struct {
+	Number int    `json:"number"`
+	ETag   string `json:"etag"`
+	Size   int64  `json:"size"`
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"io"
+	"strings"
+	"time"
+)
+
+// Validator check object before upload, size is 0 if unknown, contentType is empty if it has been checked
+type Validator func(ctx context.Context, object string, size int64, contentType string) error
+
+// MaxSize limit object size
+func MaxSize(max int64) Validator {
+	return func(ctx context.Context, object string, size int64, contentType string) (err error) {
+		if size > max {
+			err = errors.Wrapf(ErrObjectSizeInvalid, "size %d exceeds %d", size, max)
+		}
+		return
+	}
+}
+
+// ContentTypes limit object content type, wildcard is supported, e.g. image/*
+func ContentTypes(types ...string) Validator {
+	return func(ctx context.Context, object string, size int64, contentType string) (err error) {
+		if contentType == "" {
+			return
+		}
+		ct := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+		for _, item := range types {
+			item = strings.ToLower(item)
+			if item == ct || (strings.HasSuffix(item, "/*") && strings.HasPrefix(ct, strings.TrimSuffix(item, "*"))) {
+				return
+			}
+		}
+		err = errors.Wrapf(ErrContentTypeInvalid, "content type %s not in %s", contentType, strings.Join(types, ","))
+		return
+	}
+}
+
+type validated struct {
+	Storage
+	validators []Validator
+}
+
+// partSizer get the real total size of uploaded parts before complete, the size of Part from client is untrusted
+type partSizer interface {
+	partsSize(ctx context.Context, object, uploadId string, parts []Part) (int64, error)
+}
+
+// Validate wrap storage with validation hooks, Put/NewMultipart/CompleteMultipart will be checked before the object is written,
+// PresignPut is refused because the client uploads directly, use Local.Handler(validators...) for local storage
+func Validate(s Storage, validators ...Validator) Storage {
+	return &validated{
+		Storage:    s,
+		validators: validators,
+	}
+}
+
+func (v *validated) Put(ctx context.Context, object string, reader io.Reader, size int64, contentType string) (rp *Object, err error) {
+	contentType = v.contentType(object, contentType)
+	err = v.validate(ctx, object, size, contentType)
+	if err != nil {
+		return
+	}
+	if size < 0 {
+		// unknown size, check while reading, the upload is aborted before the object is written
+		reader = &validateReader{
+			ctx:    ctx,
+			v:      v,
+			object: object,
+			reader: reader,
+		}
+	}
+	return v.Storage.Put(ctx, object, reader, size, contentType)
+}
+
+func (v *validated) PresignPut(ctx context.Context, object string, expire time.Duration) (rp string, err error) {
+	if len(v.validators) > 0 {
+		err = ErrPresignValidated
+		return
+	}
+	return v.Storage.PresignPut(ctx, object, expire)
+}
+
+func (v *validated) NewMultipart(ctx context.Context, object, contentType string) (uploadId string, err error) {
+	contentType = v.contentType(object, contentType)
+	err = v.validate(ctx, object, -1, contentType)
+	if err != nil {
+		return
+	}
+	return v.Storage.NewMultipart(ctx, object, contentType)
+}
+
+func (v *validated) CompleteMultipart(ctx context.Context, object, uploadId string, parts []Part) (rp *Object, err error) {
+	if s, ok := v.Storage.(partSizer); ok {
+		// content type is checked by NewMultipart, only check the total size before merged
+		var size int64
+		size, err = s.partsSize(ctx, object, uploadId, parts)
+		if err != nil {
+			return
+		}
+		err = v.validate(ctx, object, size, "")
+		if err != nil {
+			return
+		}
+		return v.Storage.CompleteMultipart(ctx, object, uploadId, parts)
+	}
+	// custom storage, the total size is known after merged, never delete an existing object
+	_, err = v.Storage.Stat(ctx, object)
+	exists := err == nil
+	rp, err = v.Storage.CompleteMultipart(ctx, object, uploadId, parts)
+	if err != nil {
+		return
+	}
+	err = v.validate(ctx, object, rp.Size, rp.ContentType)
+	if err != nil {
+		if !exists {
+			_ = v.Storage.Delete(ctx, object)
+		}
+		rp = nil
+	}
+	return
+}
+
+// contentType use type by object name if empty, validators always get the real type
+func (v *validated) contentType(object, contentType string) string {
+	if contentType == "" {
+		return contentTypeByName(object)
+	}
+	return contentType
+}
+
+func (v *validated) validate(ctx context.Context, object string, size int64, contentType string) (err error) {
+	if object == "" {
+		err = ErrObjectNameNil
+		return
+	}
+	for _, f := range v.validators {
+		if size < 0 {
+			// skip size check
+			err = f(ctx, object, 0, contentType)
+		} else {
+			err = f(ctx, object, size, contentType)
+		}
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// validateReader check size of read bytes, the read error fails the upload
+type validateReader struct {
+	ctx    context.Context
+	v      *validated
+	object string
+	reader io.Reader
+	n      int64
+}
+
+func (r *validateReader) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	r.n += int64(n)
+	if e := r.v.validate(r.ctx, r.object, r.n, ""); e != nil {
+		err = e
+	}
+	return
+}
@@ -0,0 +1,5 @@
+module github.com/go-cinch/common/tenant
+
+go 1.20
+
+require github.com/go-kratos/kratos/v2 v2.7.0
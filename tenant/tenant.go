@@ -0,0 +1,47 @@
+package tenant
+
+import (
+	"context"
+	"github.com/go-kratos/kratos/v2/log"
+)
+
+// HeaderKey is the key of worker task header
+const HeaderKey = "tenant.id"
+
+type tenantCtx struct{}
+
+// SetTenant set tenant id to ctx
+func SetTenant(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, tenantCtx{}, id)
+}
+
+// FromContext get tenant id from ctx, empty if not set
+func FromContext(ctx context.Context) (id string) {
+	if v, ok := ctx.Value(tenantCtx{}).(string); ok {
+		id = v
+	}
+	return
+}
+
+// ID is log valuer, common log: log.WithValuer("tenant.id", tenant.ID())
+func ID() log.Valuer {
+	return func(ctx context.Context) interface{} {
+		return FromContext(ctx)
+	}
+}
+
+// Carrier is worker carrier, worker.WithCarrier(tenant.Carrier{})
+type Carrier struct{}
+
+func (Carrier) Inject(ctx context.Context, header map[string]string) {
+	if id := FromContext(ctx); id != "" {
+		header[HeaderKey] = id
+	}
+}
+
+func (Carrier) Extract(ctx context.Context, header map[string]string) context.Context {
+	if id, ok := header[HeaderKey]; ok && id != "" {
+		ctx = SetTenant(ctx, id)
+	}
+	return ctx
+}
@@ -0,0 +1,27 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCarrier(t *testing.T) {
+	header := make(map[string]string)
+	Carrier{}.Inject(context.Background(), header)
+	if len(header) != 0 {
+		t.Fatalf("Inject() without id, header = %v", header)
+	}
+
+	Carrier{}.Inject(SetTenant(context.Background(), "t1"), header)
+	if header[HeaderKey] != "t1" {
+		t.Fatalf("Inject() header = %v", header)
+	}
+
+	ctx := Carrier{}.Extract(context.Background(), header)
+	if got := FromContext(ctx); got != "t1" {
+		t.Fatalf("Extract() id = %v, want t1", got)
+	}
+	if got := ID()(ctx); got != "t1" {
+		t.Fatalf("ID() = %v, want t1", got)
+	}
+}
@@ -0,0 +1,351 @@
+package diff
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	Add     = "add"
+	Remove  = "remove"
+	Replace = "replace"
+)
+
+var (
+	ErrTypeMismatch = errors.New("old and new must be the same struct type")
+	ErrPathInvalid  = errors.New("invalid patch path")
+)
+
+// Change is one field level difference like json patch(RFC 6902), Path is json pointer of json names, e.g. /profile/phone
+type Change struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Old   interface{} `json:"old,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+	field string      // top-level struct field name, used by Updates
+}
+
+type Options struct {
+	tag    string
+	ignore map[string]struct{}
+}
+
+// WithTag tag name of ignore flag, default diff, e.g. `diff:"-"`
+func WithTag(tag string) func(*Options) {
+	return func(options *Options) {
+		if tag != "" {
+			getOptionsOrSetDefault(options).tag = tag
+		}
+	}
+}
+
+// WithIgnore ignore fields by struct field path, e.g. UpdatedAt, Profile.Password
+func WithIgnore(fields ...string) func(*Options) {
+	return func(options *Options) {
+		for _, item := range fields {
+			getOptionsOrSetDefault(options).ignore[item] = struct{}{}
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			tag:    "diff",
+			ignore: make(map[string]struct{}),
+		}
+	}
+	return options
+}
+
+// Compare get changes from o(old struct) to n(new struct), nested struct is compared by field,
+// slice/map is replaced as a whole, embedded struct fields are flattened like json
+func Compare(o, n interface{}, options ...func(*Options)) (rp []Change, err error) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	ov, nv := indirect(reflect.ValueOf(o)), indirect(reflect.ValueOf(n))
+	if !ov.IsValid() || !nv.IsValid() || ov.Type() != nv.Type() || ov.Kind() != reflect.Struct {
+		err = ErrTypeMismatch
+		return
+	}
+	rp = make([]Change, 0)
+	compare(*ops, ov, nv, "", "", "", &rp)
+	return
+}
+
+// Updates get changed top-level fields and new values, it can be used by gorm, e.g. db.Model(&old).Updates(m)
+func Updates(o, n interface{}, options ...func(*Options)) (rp map[string]interface{}, err error) {
+	changes, err := Compare(o, n, options...)
+	if err != nil {
+		return
+	}
+	nv := indirect(reflect.ValueOf(n))
+	rp = make(map[string]interface{}, len(changes))
+	for _, item := range changes {
+		if _, ok := rp[item.field]; ok {
+			continue
+		}
+		rp[item.field] = nv.FieldByName(item.field).Interface()
+	}
+	return
+}
+
+// Apply changes to dst(pointer of struct), Old is ignored, Value is converted by json,
+// so changes can be from request body of PATCH endpoint, options are same as Compare,
+// all paths are checked before apply, ignored field path will get ErrPathInvalid
+func Apply(dst interface{}, changes []Change, options ...func(*Options)) (err error) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		err = errors.New("dst must be pointer of struct")
+		return
+	}
+	for _, item := range changes {
+		switch item.Op {
+		case Add, Replace, Remove:
+		default:
+			err = errors.Errorf("invalid op %s of %s", item.Op, item.Path)
+			return
+		}
+		_, err = lookup(*ops, rv.Elem(), item.Path, false)
+		if err != nil {
+			return
+		}
+	}
+	for _, item := range changes {
+		var field reflect.Value
+		field, err = lookup(*ops, rv.Elem(), item.Path, true)
+		if err != nil {
+			return
+		}
+		if item.Op == Remove {
+			field.Set(reflect.Zero(field.Type()))
+			continue
+		}
+		bs, _ := json.Marshal(item.Value)
+		v := reflect.New(field.Type())
+		err = json.Unmarshal(bs, v.Interface())
+		if err != nil {
+			err = errors.Wrapf(err, "invalid value of %s", item.Path)
+			return
+		}
+		field.Set(v.Elem())
+	}
+	return
+}
+
+func compare(ops Options, ov, nv reflect.Value, path, fieldPath, top string, rp *[]Change) {
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Tag.Get(ops.tag) == "-" {
+			continue
+		}
+		o, n := ov.Field(i), nv.Field(i)
+		if embedded(sf) {
+			if !indirect(o).IsValid() || !indirect(n).IsValid() {
+				// nil embedded pointer, compare as a whole
+				if sf.IsExported() {
+					tp := top
+					if tp == "" {
+						tp = sf.Name
+					}
+					compareValue(ops, o, n, path+"/"+escape(sf.Name), join(fieldPath, sf.Name, "."), tp, rp)
+				}
+				continue
+			}
+			// embedded struct(exported or not), flatten fields like json
+			compare(ops, indirect(o), indirect(n), path, fieldPath, top, rp)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		name, skip := jsonName(sf)
+		if skip {
+			continue
+		}
+		fp := join(fieldPath, sf.Name, ".")
+		if _, ok := ops.ignore[fp]; ok {
+			continue
+		}
+		tp := top
+		if tp == "" {
+			tp = sf.Name
+		}
+		p := path + "/" + escape(name)
+		compareValue(ops, o, n, p, fp, tp, rp)
+	}
+}
+
+func compareValue(ops Options, o, n reflect.Value, path, fieldPath, top string, rp *[]Change) {
+	switch o.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		switch {
+		case o.IsNil() && n.IsNil():
+			return
+		case o.IsNil():
+			*rp = append(*rp, Change{Op: Add, Path: path, Value: n.Interface(), field: top})
+			return
+		case n.IsNil():
+			*rp = append(*rp, Change{Op: Remove, Path: path, Old: o.Interface(), field: top})
+			return
+		}
+		if o.Kind() == reflect.Ptr && o.Elem().Kind() == reflect.Struct && !isTime(o.Elem()) {
+			compare(ops, o.Elem(), n.Elem(), path, fieldPath, top, rp)
+			return
+		}
+	case reflect.Struct:
+		if !isTime(o) {
+			compare(ops, o, n, path, fieldPath, top, rp)
+			return
+		}
+	}
+	if !equal(o, n) {
+		*rp = append(*rp, Change{Op: Replace, Path: path, Old: o.Interface(), Value: n.Interface(), field: top})
+	}
+}
+
+func equal(o, n reflect.Value) bool {
+	oi, ni := indirect(o), indirect(n)
+	// interface field may hold time in one side only
+	if oi.IsValid() && isTime(oi) && ni.IsValid() && isTime(ni) {
+		return oi.Interface().(time.Time).Equal(ni.Interface().(time.Time))
+	}
+	return reflect.DeepEqual(o.Interface(), n.Interface())
+}
+
+// lookup get settable field by json pointer, nil pointer is allocated only if alloc is true
+func lookup(ops Options, v reflect.Value, path string, alloc bool) (field reflect.Value, err error) {
+	if !strings.HasPrefix(path, "/") {
+		err = errors.Wrap(ErrPathInvalid, path)
+		return
+	}
+	field = v
+	var fieldPath string
+	for _, item := range strings.Split(path[1:], "/") {
+		name := unescape(item)
+		field = deref(field, alloc)
+		if field.Kind() != reflect.Struct {
+			err = errors.Wrap(ErrPathInvalid, path)
+			return
+		}
+		var ok bool
+		field, fieldPath, ok = fieldByJsonName(ops, field, name, fieldPath, alloc)
+		if !ok || !field.CanSet() {
+			err = errors.Wrap(ErrPathInvalid, path)
+			return
+		}
+		if _, ok = ops.ignore[fieldPath]; ok {
+			err = errors.Wrap(ErrPathInvalid, path)
+			return
+		}
+	}
+	return
+}
+
+// fieldByJsonName find field by json name like compare, fields with ignore tag are skipped
+func fieldByJsonName(ops Options, v reflect.Value, name, fieldPath string, alloc bool) (field reflect.Value, fp string, ok bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Tag.Get(ops.tag) == "-" {
+			continue
+		}
+		if embedded(sf) {
+			f := v.Field(i)
+			if f.Kind() == reflect.Ptr && f.IsNil() && alloc && !f.CanSet() {
+				continue
+			}
+			if field, fp, ok = fieldByJsonName(ops, deref(f, alloc), name, fieldPath, alloc); ok {
+				return
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if n, skip := jsonName(sf); !skip && n == name {
+			return v.Field(i), join(fieldPath, sf.Name, "."), true
+		}
+	}
+	return
+}
+
+// deref get struct of pointer, nil pointer is allocated if alloc is true, otherwise use a new zero value
+func deref(v reflect.Value, alloc bool) reflect.Value {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if !alloc {
+				return reflect.New(v.Type().Elem()).Elem()
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func jsonName(sf reflect.StructField) (name string, skip bool) {
+	tag := strings.SplitN(sf.Tag.Get("json"), ",", 2)[0]
+	switch tag {
+	case "-":
+		skip = true
+	case "":
+		name = sf.Name
+	default:
+		name = tag
+	}
+	return
+}
+
+// embedded struct without json tag, its fields are promoted like json
+func embedded(sf reflect.StructField) bool {
+	t := sf.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return sf.Anonymous && t.Kind() == reflect.Struct && sf.Tag.Get("json") == ""
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isTime(v reflect.Value) bool {
+	return v.Type() == reflect.TypeOf(time.Time{})
+}
+
+func join(prefix, name, sep string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + sep + name
+}
+
+var (
+	escaper   = strings.NewReplacer("~", "~0", "/", "~1")
+	unescaper = strings.NewReplacer("~1", "/", "~0", "~")
+)
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+func unescape(s string) string {
+	return unescaper.Replace(s)
+}
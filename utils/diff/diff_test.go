@@ -0,0 +1,168 @@
+package diff
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type base struct {
+	Id        uint64    `json:"id"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type profile struct {
+	Phone string `json:"phone"`
+	City  string `json:"city"`
+}
+
+type user struct {
+	base
+	Name     string            `json:"name"`
+	Password string            `json:"-"`
+	Secret   string            `json:"secret" diff:"-"`
+	Profile  profile           `json:"profile"`
+	Address  *profile          `json:"address,omitempty"`
+	Tags     []string          `json:"tags"`
+	Extra    map[string]string `json:"a/b"`
+	age      int
+}
+
+func TestCompare(t *testing.T) {
+	now := time.Now()
+	o := user{
+		base:     base{Id: 1, UpdatedAt: now},
+		Name:     "a",
+		Password: "1",
+		Secret:   "1",
+		Profile:  profile{Phone: "1", City: "x"},
+		Tags:     []string{"a"},
+		Extra:    map[string]string{"k": "v"},
+		age:      1,
+	}
+	n := o
+	n.UpdatedAt = now.UTC()
+	n.Name = "b"
+	n.Password = "2"
+	n.Secret = "2"
+	n.Profile.Phone = "2"
+	n.Address = &profile{City: "y"}
+	n.Tags = []string{"a", "b"}
+	n.Extra = nil
+	n.age = 2
+
+	changes, err := Compare(o, &n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, _ := json.Marshal(changes)
+	expect := `[{"op":"replace","path":"/name","old":"a","value":"b"},` +
+		`{"op":"replace","path":"/profile/phone","old":"1","value":"2"},` +
+		`{"op":"add","path":"/address","value":{"phone":"","city":"y"}},` +
+		`{"op":"replace","path":"/tags","old":["a"],"value":["a","b"]},` +
+		`{"op":"remove","path":"/a~1b","old":{"k":"v"}}]`
+	if string(bs) != expect {
+		t.Fatalf("unexpected changes %s", bs)
+	}
+
+	changes, _ = Compare(o, n, WithIgnore("Profile.Phone", "Tags", "Extra", "Address"))
+	if len(changes) != 1 || changes[0].Path != "/name" {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+
+	// interface field holds different types
+	type value struct {
+		X interface{} `json:"x"`
+	}
+	changes, err = Compare(value{X: now}, value{X: "x"})
+	if err != nil || len(changes) != 1 || changes[0].Path != "/x" {
+		t.Fatalf("unexpected changes %+v, error = %v", changes, err)
+	}
+	changes, _ = Compare(value{X: now}, value{X: now.UTC()})
+	if len(changes) != 0 {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+	changes, _ = Compare(value{}, value{X: now})
+	if len(changes) != 1 {
+		t.Fatalf("unexpected changes %+v", changes)
+	}
+
+	_, err = Compare(o, profile{})
+	if err != ErrTypeMismatch {
+		t.Fatalf("expect type mismatch but got %v", err)
+	}
+}
+
+func TestUpdates(t *testing.T) {
+	o := user{Name: "a", Profile: profile{Phone: "1", City: "x"}}
+	n := o
+	n.Id = 1
+	n.Profile.Phone = "2"
+	n.Profile.City = "y"
+	m, err := Updates(o, n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || m["Id"] != uint64(1) || m["Profile"] != n.Profile {
+		t.Fatalf("unexpected updates %+v", m)
+	}
+}
+
+func TestApply(t *testing.T) {
+	var changes []Change
+	_ = json.Unmarshal([]byte(`[
+		{"op":"replace","path":"/id","value":2},
+		{"op":"replace","path":"/profile/city","value":"y"},
+		{"op":"add","path":"/address/phone","value":"3"},
+		{"op":"remove","path":"/tags"}
+	]`), &changes)
+	u := user{Name: "a", Tags: []string{"a"}}
+	err := Apply(&u, changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Id != 2 || u.Profile.City != "y" || u.Address == nil || u.Address.Phone != "3" || u.Tags != nil || u.Name != "a" {
+		t.Fatalf("unexpected user %+v", u)
+	}
+
+	// round trip
+	o := user{Name: "a", Profile: profile{City: "x"}}
+	n := user{Name: "b", Profile: profile{City: "y"}, Tags: []string{"c"}}
+	changes, _ = Compare(o, n)
+	err = Apply(&o, changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes, _ = Compare(o, n); len(changes) != 0 {
+		t.Fatalf("unexpected changes after apply %+v", changes)
+	}
+
+	err = Apply(&u, []Change{{Op: Replace, Path: "/secret1", Value: "1"}})
+	if err == nil {
+		t.Fatal("expect invalid path")
+	}
+	err = Apply(&u, []Change{{Op: Replace, Path: "/id", Value: "x"}})
+	if err == nil {
+		t.Fatal("expect invalid value")
+	}
+
+	// ignored fields can not be patched, no change is applied if any path is invalid
+	u = user{Name: "a", Secret: "1"}
+	err = Apply(&u, []Change{{Op: Replace, Path: "/name", Value: "b"}, {Op: Replace, Path: "/secret", Value: "2"}})
+	if !errors.Is(err, ErrPathInvalid) || u.Name != "a" || u.Secret != "1" {
+		t.Fatalf("expect diff tag invalid path but got %v, user %+v", err, u)
+	}
+	err = Apply(&u, []Change{{Op: Add, Path: "/address/phone", Value: "1"}}, WithIgnore("Address.Phone"))
+	if !errors.Is(err, ErrPathInvalid) || u.Address != nil {
+		t.Fatalf("expect ignore invalid path but got %v, user %+v", err, u)
+	}
+	err = Apply(&u, []Change{{Op: Replace, Path: "/profile", Value: profile{Phone: "1"}}}, WithIgnore("Profile"))
+	if !errors.Is(err, ErrPathInvalid) {
+		t.Fatalf("expect ignore invalid path but got %v", err)
+	}
+	err = Apply(&u, []Change{{Op: Replace, Path: "/secret", Value: "2"}}, WithTag("patch"))
+	if err != nil || u.Secret != "2" {
+		t.Fatalf("unexpected custom tag error %v, user %+v", err, u)
+	}
+}
@@ -0,0 +1,42 @@
+module github.com/go-cinch/common/validate
+
+go 1.20
+
+replace (
+	github.com/go-cinch/common/constant => ../constant
+	github.com/go-cinch/common/i18n => ../i18n
+	github.com/go-cinch/common/middleware/i18n => ../middleware/i18n
+	github.com/go-cinch/common/middleware/locale => ../middleware/locale
+)
+
+require (
+	github.com/go-cinch/common/constant v1.0.3
+	github.com/go-cinch/common/i18n v1.0.6
+	github.com/go-cinch/common/middleware/i18n v1.0.4
+	github.com/go-cinch/common/middleware/locale v1.0.0
+	github.com/go-kratos/kratos/v2 v2.7.0
+	github.com/go-playground/validator/v10 v10.15.1
+	github.com/pkg/errors v0.9.1
+	golang.org/x/text v0.11.0
+)
+
+require (
+	github.com/BurntSushi/toml v1.3.2 // indirect
+	github.com/gabriel-vasile/mimetype v1.4.2 // indirect
+	github.com/go-kratos/aegis v0.2.0 // indirect
+	github.com/go-playground/form/v4 v4.2.1 // indirect
+	github.com/go-playground/locales v0.14.1 // indirect
+	github.com/go-playground/universal-translator v0.18.1 // indirect
+	github.com/golang/protobuf v1.5.3 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/gorilla/mux v1.8.0 // indirect
+	github.com/leodido/go-urn v1.2.4 // indirect
+	github.com/nicksnyder/go-i18n/v2 v2.2.1 // indirect
+	golang.org/x/crypto v0.10.0 // indirect
+	golang.org/x/net v0.11.0 // indirect
+	golang.org/x/sys v0.10.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20230629202037-9506855d4529 // indirect
+	google.golang.org/grpc v1.56.1 // indirect
+	google.golang.org/protobuf v1.31.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
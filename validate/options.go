@@ -0,0 +1,59 @@
+package validate
+
+import (
+	"embed"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
+)
+
+type Options struct {
+	language language.Tag
+	phone    string
+	rules    map[string]validator.Func
+	fs       []embed.FS
+}
+
+// WithLanguage default language when request has no translator
+func WithLanguage(lang language.Tag) func(*Options) {
+	return func(options *Options) {
+		if lang.String() != "und" {
+			getOptionsOrSetDefault(options).language = lang
+		}
+	}
+}
+
+// WithPhone regexp of phone rule, default is mobile phone number of chinese mainland
+func WithPhone(expr string) func(*Options) {
+	return func(options *Options) {
+		if expr != "" {
+			getOptionsOrSetDefault(options).phone = expr
+		}
+	}
+}
+
+// WithRule register custom rule, add message 'validate.{tag}' by WithFs or i18n middleware
+func WithRule(tag string, f validator.Func) func(*Options) {
+	return func(options *Options) {
+		if tag != "" && f != nil {
+			getOptionsOrSetDefault(options).rules[tag] = f
+		}
+	}
+}
+
+// WithFs add message files, they are preferred to built-in messages
+func WithFs(fs embed.FS) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).fs = append(getOptionsOrSetDefault(options).fs, fs)
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			language: language.English,
+			phone:    DefaultPhone,
+			rules:    make(map[string]validator.Func),
+		}
+	}
+	return options
+}
@@ -0,0 +1,54 @@
+package validate
+
+import (
+	"github.com/go-playground/validator/v10"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultPhone is mobile phone number of chinese mainland
+const DefaultPhone = `^1[3-9]\d{9}$`
+
+var (
+	idCardWeights = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	idCardCodes   = "10X98765432"
+	digits        = regexp.MustCompile(`^\d+$`)
+)
+
+func phone(re *regexp.Regexp) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return re.MatchString(fl.Field().String())
+	}
+}
+
+func idCard(fl validator.FieldLevel) bool {
+	return IdCard(fl.Field().String())
+}
+
+// IdCard check chinese resident id card number, 18 digits with checksum or 15 digits of old version
+func IdCard(s string) bool {
+	s = strings.ToUpper(s)
+	switch len(s) {
+	case 15:
+		if !digits.MatchString(s) {
+			return false
+		}
+		return birthday("19" + s[6:12])
+	case 18:
+		if !digits.MatchString(s[:17]) || !birthday(s[6:14]) {
+			return false
+		}
+		sum := 0
+		for i, w := range idCardWeights {
+			sum += int(s[i]-'0') * w
+		}
+		return idCardCodes[sum%11] == s[17]
+	}
+	return false
+}
+
+func birthday(s string) bool {
+	t, err := time.ParseInLocation("20060102", s, time.Local)
+	return err == nil && !t.After(time.Now())
+}
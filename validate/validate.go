@@ -0,0 +1,180 @@
+package validate
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"github.com/go-cinch/common/constant"
+	"github.com/go-cinch/common/i18n"
+	mi18n "github.com/go-cinch/common/middleware/i18n"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-playground/validator/v10"
+	pkgErrors "github.com/pkg/errors"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+//go:embed locales
+var locales embed.FS
+
+// Validator validate struct by go-playground/validator tags, violation messages are translated by request language
+type Validator struct {
+	ops      Options
+	validate *validator.Validate
+	i18n     *i18n.I18n
+}
+
+func New(options ...func(*Options)) (v *Validator) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	validate := validator.New()
+	// use json name as field name
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	_ = validate.RegisterValidation("phone", phone(regexp.MustCompile(ops.phone)))
+	_ = validate.RegisterValidation("idcard", idCard)
+	for tag, f := range ops.rules {
+		_ = validate.RegisterValidation(tag, f)
+	}
+	ii := i18n.New(
+		i18n.WithLanguage(ops.language),
+		i18n.WithFs(locales),
+	)
+	for _, item := range ops.fs {
+		ii.AddFs(item)
+	}
+	v = &Validator{
+		ops:      *ops,
+		validate: validate,
+		i18n:     ii,
+	}
+	return
+}
+
+// Engine get the underlying validator to register more features
+func (v *Validator) Engine() *validator.Validate {
+	return v.validate
+}
+
+// Struct validate struct, return kratos bad request error, metadata is field path and message of each violation
+func (v *Validator) Struct(ctx context.Context, s interface{}) (err error) {
+	err = v.validate.StructCtx(ctx, s)
+	return v.translate(ctx, err, "")
+}
+
+// Var validate single variable, name is used as field name
+func (v *Validator) Var(ctx context.Context, name string, field interface{}, tag string) (err error) {
+	err = v.validate.VarCtx(ctx, field, tag)
+	return v.translate(ctx, err, name)
+}
+
+// Server validate request if it is struct, use it after i18n middleware
+func (v *Validator) Server() middleware.Middleware {
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (rp interface{}, err error) {
+			if isStruct(req) {
+				err = v.Struct(ctx, req)
+				if err != nil {
+					return
+				}
+			}
+			return handler(ctx, req)
+		}
+	}
+}
+
+func (v *Validator) translate(ctx context.Context, err error, name string) error {
+	if err == nil {
+		return nil
+	}
+	var list validator.ValidationErrors
+	if !pkgErrors.As(err, &list) {
+		return pkgErrors.WithStack(err)
+	}
+	metadata := make(map[string]string, len(list))
+	var message string
+	for _, item := range list {
+		key := path(item)
+		if key == "" {
+			key = name
+		}
+		if _, ok := metadata[key]; ok {
+			continue
+		}
+		metadata[key] = v.message(ctx, item, key)
+		if message == "" {
+			message = metadata[key]
+		}
+	}
+	return errors.BadRequest(constant.IllegalParameter, message).WithMetadata(metadata)
+}
+
+// message translate violation, request translator is preferred, ids:
+// validate.{tag}.{string|items} > validate.{tag} > validate.default, field name is translated by field.{name}
+func (v *Validator) message(ctx context.Context, e validator.FieldError, key string) (rp string) {
+	app := mi18n.FromContext(ctx)
+	own := v.i18n.Select(app.Language())
+	field := key
+	if i := strings.LastIndex(key, "."); i >= 0 {
+		field = key[i+1:]
+	}
+	if id := "field." + field; app.Exists(id) {
+		field = app.T(id)
+	} else if own.Exists(id) {
+		field = own.T(id)
+	}
+	data := map[string]string{
+		"Field": field,
+		"Param": e.Param(),
+		"Value": fmt.Sprint(e.Value()),
+	}
+	ids := make([]string, 0, 3)
+	switch e.Kind() {
+	case reflect.String:
+		ids = append(ids, "validate."+e.Tag()+".string")
+	case reflect.Slice, reflect.Array, reflect.Map:
+		ids = append(ids, "validate."+e.Tag()+".items")
+	}
+	ids = append(ids, "validate."+e.Tag(), "validate.default")
+	for _, id := range ids {
+		if app.Exists(id) {
+			return app.Template(id, data)
+		}
+		if own.Exists(id) {
+			return own.Template(id, data)
+		}
+	}
+	return e.Error()
+}
+
+// path get field path without struct name, e.g. User.profile.phone => profile.phone
+func path(e validator.FieldError) string {
+	ns := e.Namespace()
+	if i := strings.Index(ns, "."); i >= 0 {
+		return ns[i+1:]
+	}
+	return e.Field()
+}
+
+func isStruct(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	return rv.Kind() == reflect.Struct
+}
@@ -0,0 +1,124 @@
+package validate
+
+import (
+	"context"
+	"github.com/go-cinch/common/i18n"
+	mi18n "github.com/go-cinch/common/middleware/i18n"
+	"github.com/go-cinch/common/middleware/locale"
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/text/language"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type profile struct {
+	Phone  string `json:"phone" validate:"required,phone"`
+	IdCard string `json:"idCard" validate:"omitempty,idcard"`
+}
+
+type createUser struct {
+	Username string   `json:"username" validate:"required,min=3"`
+	Age      int      `json:"age" validate:"gte=18"`
+	Tags     []string `json:"tags" validate:"max=2"`
+	Code     string   `json:"code,omitempty" validate:"omitempty,even"`
+	Profile  profile  `json:"profile"`
+}
+
+func newValidator() *Validator {
+	return New(WithRule("even", func(fl validator.FieldLevel) bool {
+		return len(fl.Field().String())%2 == 0
+	}))
+}
+
+func TestStruct(t *testing.T) {
+	v := newValidator()
+	req := createUser{
+		Username: "ab",
+		Age:      17,
+		Tags:     []string{"a", "b", "c"},
+		Code:     "abc",
+		Profile:  profile{Phone: "12345", IdCard: "110105194912310021"},
+	}
+	err := v.Struct(context.Background(), req)
+	e := errors.FromError(err)
+	if e.Code != 400 || e.Message != "username must be at least 3 characters" {
+		t.Fatalf("unexpected error %v", err)
+	}
+	expect := map[string]string{
+		"username":       "username must be at least 3 characters",
+		"age":            "age must be 18 or greater",
+		"tags":           "tags must contain at most 2 items",
+		"code":           "code is invalid",
+		"profile.phone":  "phone must be a valid phone number",
+		"profile.idCard": "idCard must be a valid id card number",
+	}
+	for k, item := range expect {
+		if e.Metadata[k] != item {
+			t.Fatalf("metadata %s = %s, want %s", k, e.Metadata[k], item)
+		}
+	}
+
+	req = createUser{
+		Username: "cinch",
+		Age:      18,
+		Profile:  profile{Phone: "13800000000", IdCard: "11010519491231002x"},
+	}
+	if err = v.Struct(context.Background(), &req); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	v := newValidator()
+	// language of locale middleware
+	ctx := locale.NewContext(context.Background(), language.SimplifiedChinese)
+	err := v.Struct(ctx, createUser{Username: "cinch", Age: 18})
+	if e := errors.FromError(err); e.Message != "phone不能为空" {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	// translator of i18n middleware is preferred
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "zh.yml"), []byte("field.phone: '手机号'\nvalidate.required: '请输入{{.Field}}'\n"), 0o644)
+	ii := i18n.New(i18n.WithFile(filepath.Join(dir, "zh.yml"))).Select(language.Chinese)
+	ctx = mi18n.NewContext(context.Background(), ii)
+	err = v.Struct(ctx, createUser{Username: "cinch", Age: 18})
+	if e := errors.FromError(err); e.Message != "请输入手机号" {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	err = v.Var(ctx, "email", "x", "email")
+	if e := errors.FromError(err); e.Message != "email必须是有效的邮箱" || e.Metadata["email"] == "" {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestServer(t *testing.T) {
+	v := New()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	if _, err := v.Server()(handler)(context.Background(), &profile{}); err == nil {
+		t.Fatal("expect validate error")
+	}
+	// not struct
+	if rp, err := v.Server()(handler)(context.Background(), "x"); err != nil || rp != "ok" {
+		t.Fatalf("unexpected reply %v %v", rp, err)
+	}
+}
+
+func TestIdCard(t *testing.T) {
+	for s, ok := range map[string]bool{
+		"11010519491231002X": true,
+		"110105194912310021": false,
+		"11010549123100":     false,
+		"110105491231002":    true,
+		"110105209912310020": false,
+	} {
+		if IdCard(s) != ok {
+			t.Fatalf("IdCard(%s) want %v", s, ok)
+		}
+	}
+}
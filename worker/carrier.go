@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// headerMagic mark task payload with header, payload without header keeps the origin format
+var headerMagic = []byte("\x00worker.header\x00")
+
+// Carrier carry values of ctx through task, such as request id or tenant id
+// Inject is called when task is enqueued, Extract is called before handler
+type Carrier interface {
+	Inject(ctx context.Context, header map[string]string)
+	Extract(ctx context.Context, header map[string]string) context.Context
+}
+
+type envelope struct {
+	Header  map[string]string `json:"header"`
+	Payload string            `json:"payload"`
+}
+
+func (wk Worker) inject(ctx context.Context) (header map[string]string) {
+	if ctx == nil || len(wk.ops.carriers) == 0 {
+		return
+	}
+	header = make(map[string]string)
+	for _, item := range wk.ops.carriers {
+		item.Inject(ctx, header)
+	}
+	if len(header) == 0 {
+		header = nil
+	}
+	return
+}
+
+func (wk Worker) extract(ctx context.Context, header map[string]string) context.Context {
+	if len(header) == 0 {
+		return ctx
+	}
+	for _, item := range wk.ops.carriers {
+		ctx = item.Extract(ctx, header)
+	}
+	return ctx
+}
+
+func encodePayload(header map[string]string, payload string) []byte {
+	if len(header) == 0 {
+		return []byte(payload)
+	}
+	bs, _ := json.Marshal(envelope{
+		Header:  header,
+		Payload: payload,
+	})
+	return append(append([]byte{}, headerMagic...), bs...)
+}
+
+func decodePayload(data []byte) (header map[string]string, payload string) {
+	if !bytes.HasPrefix(data, headerMagic) {
+		payload = string(data)
+		return
+	}
+	var e envelope
+	if err := json.Unmarshal(data[len(headerMagic):], &e); err != nil {
+		payload = string(data)
+		return
+	}
+	header = e.Header
+	payload = e.Payload
+	return
+}
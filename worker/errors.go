@@ -9,4 +9,5 @@ var (
 	ErrExprInvalid                   = fmt.Errorf("expr is invalid")
 	ErrSaveCron                      = fmt.Errorf("save cron failed")
 	ErrHttpCallbackInvalidStatusCode = fmt.Errorf("http callback invalid status code")
+	ErrNotInTask                     = fmt.Errorf("ctx is not of running task")
 )
@@ -20,6 +20,7 @@ type Options struct {
 	clearArchived     int
 	maxArchivedTime   int
 	timeout           int
+	carriers          []Carrier
 }
 
 func WithGroup(s string) func(*Options) {
@@ -106,6 +107,15 @@ func WithTimeout(second int) func(*Options) {
 	}
 }
 
+// WithCarrier carry ctx values to task handler, the values are stored in task payload
+func WithCarrier(c ...Carrier) func(*Options) {
+	return func(options *Options) {
+		if len(c) > 0 {
+			getOptionsOrSetDefault(options).carriers = append(getOptionsOrSetDefault(options).carriers, c...)
+		}
+	}
+}
+
 func getOptionsOrSetDefault(options *Options) *Options {
 	if options == nil {
 		return &Options{
@@ -125,13 +135,13 @@ type RunOptions struct {
 	uid             string
 	group           string
 	payload         string
-	expr            string          // only period task
-	in              *time.Duration  // only once task
-	at              *time.Time      // only once task
-	now             bool            // only once task
-	retention       int             // only once task
-	replace         bool            // only once task
-	ctx             context.Context // only once task
+	expr            string         // only period task
+	in              *time.Duration // only once task
+	at              *time.Time     // only once task
+	now             bool           // only once task
+	retention       int            // only once task
+	replace         bool           // only once task
+	ctx             context.Context
 	maxRetry        int
 	maxArchivedTime int
 	timeout         int
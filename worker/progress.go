@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+	"time"
+)
+
+// Progress is the progress of long-running task, Total is 0 if it is unknown
+type Progress struct {
+	Done    int64  `json:"done"`
+	Total   int64  `json:"total"`
+	Message string `json:"message,omitempty"`
+	Time    int64  `json:"time"` // update unix timestamp
+}
+
+type resultWriterCtx struct{}
+
+// SetProgress report progress in task handler, it is stored as task result,
+// keep it after task completed by WithRetention/WithRunRetention
+func SetProgress(ctx context.Context, done, total int64, message ...string) (err error) {
+	w, ok := ctx.Value(resultWriterCtx{}).(*asynq.ResultWriter)
+	if !ok {
+		err = ErrNotInTask
+		return
+	}
+	p := Progress{
+		Done:  done,
+		Total: total,
+		Time:  time.Now().Unix(),
+	}
+	if len(message) > 0 {
+		p.Message = message[0]
+	}
+	bs, _ := json.Marshal(p)
+	_, err = w.Write(bs)
+	err = errors.WithStack(err)
+	return
+}
+
+// Progress get the latest progress of task by uid
+func (wk Worker) Progress(uid string) (rp Progress, err error) {
+	info, err := wk.inspector.GetTaskInfo(wk.ops.group, uid)
+	if err != nil {
+		err = errors.WithStack(err)
+		return
+	}
+	if len(info.Result) > 0 {
+		err = json.Unmarshal(info.Result, &rp)
+		err = errors.WithStack(err)
+	}
+	return
+}
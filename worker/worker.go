@@ -4,16 +4,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"github.com/go-cinch/common/cron"
 	"github.com/go-cinch/common/log"
 	"github.com/go-cinch/common/nx"
 	"github.com/golang-module/carbon/v2"
 	"github.com/google/uuid"
-	"github.com/gorhill/cronexpr"
 	"github.com/hibiken/asynq"
 	"github.com/pkg/errors"
 	"github.com/redis/go-redis/v9"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -24,19 +25,23 @@ type Worker struct {
 	lock      *nx.Nx
 	client    *asynq.Client
 	inspector *asynq.Inspector
+	srv       *asynq.Server
+	done      chan struct{}
+	stop      *sync.Once
 	Error     error
 }
 
 type periodTask struct {
-	Expr            string `json:"expr"` // cron expr github.com/robfig/cron/v3
-	Group           string `json:"group"`
-	Uid             string `json:"uid"`
-	Payload         string `json:"payload"`
-	Next            int64  `json:"next"`      // next schedule unix timestamp
-	Processed       int64  `json:"processed"` // run times
-	MaxRetry        int    `json:"maxRetry"`
-	MaxArchivedTime int    `json:"maxArchivedTime"`
-	Timeout         int    `json:"timeout"`
+	Expr            string            `json:"expr"` // cron expr github.com/robfig/cron/v3
+	Group           string            `json:"group"`
+	Uid             string            `json:"uid"`
+	Payload         string            `json:"payload"`
+	Header          map[string]string `json:"header,omitempty"` // carried ctx values
+	Next            int64             `json:"next"`             // next schedule unix timestamp
+	Processed       int64             `json:"processed"`        // run times
+	MaxRetry        int               `json:"maxRetry"`
+	MaxArchivedTime int               `json:"maxArchivedTime"`
+	Timeout         int               `json:"timeout"`
 }
 
 func (p periodTask) String() (str string) {
@@ -55,9 +60,10 @@ type periodTaskHandler struct {
 }
 
 type Payload struct {
-	Group   string `json:"group"`
-	Uid     string `json:"uid"`
-	Payload string `json:"payload"`
+	Group   string            `json:"group"`
+	Uid     string            `json:"uid"`
+	Payload string            `json:"payload"`
+	Header  map[string]string `json:"header,omitempty"`
 }
 
 func (p Payload) String() (str string) {
@@ -69,11 +75,16 @@ func (p Payload) String() (str string) {
 func (p periodTaskHandler) ProcessTask(ctx context.Context, t *asynq.Task) (err error) {
 	uid := uuid.NewString()
 	group := strings.TrimSuffix(strings.TrimSuffix(t.Type(), ".once"), ".cron")
+	header, data := decodePayload(t.Payload())
 	payload := Payload{
 		Group:   group,
 		Uid:     t.ResultWriter().TaskID(),
-		Payload: string(t.Payload()),
+		Payload: data,
+		Header:  header,
 	}
+	// restore ctx values from header
+	ctx = p.tk.extract(ctx, header)
+	ctx = context.WithValue(ctx, resultWriterCtx{}, t.ResultWriter())
 	defer func() {
 		if err != nil {
 			log.
@@ -161,31 +172,34 @@ func New(options ...func(*Options)) (tk *Worker) {
 			RetryDelayFunc: ops.retryDelayFunc,
 		},
 	)
-	go func() {
-		var h periodTaskHandler
-		h.tk = *tk
-		if e := srv.Run(h); e != nil {
-			log.WithError(err).Error("run task handler failed")
-		}
-	}()
 	tk.ops = *ops
 	tk.redis = rd
 	tk.redisOpt = rs
 	tk.lock = nxLock
 	tk.client = client
 	tk.inspector = inspector
+	tk.srv = srv
+	tk.done = make(chan struct{})
+	tk.stop = &sync.Once{}
+	// start server without signal handler, it is shutdown by Stop
+	err = srv.Start(periodTaskHandler{tk: *tk})
+	if err != nil {
+		log.WithError(err).Error("start task handler failed")
+		client.Close()
+		inspector.Close()
+		tk.Error = errors.WithStack(err)
+		return
+	}
 	// initialize scanner
 	go func() {
-		for {
-			time.Sleep(time.Second)
+		for tk.sleep(time.Second) {
 			tk.scan()
 		}
 	}()
 	if tk.ops.clearArchived > 0 {
 		// initialize clear archived
 		go func() {
-			for {
-				time.Sleep(time.Duration(tk.ops.clearArchived) * time.Second)
+			for tk.sleep(time.Duration(tk.ops.clearArchived) * time.Second) {
 				tk.clearArchived()
 			}
 		}()
@@ -193,6 +207,29 @@ func New(options ...func(*Options)) (tk *Worker) {
 	return
 }
 
+// Stop shutdown server(wait active tasks), scanner and client, tasks can't be enqueued after stop
+func (wk Worker) Stop() {
+	if wk.stop == nil {
+		return
+	}
+	wk.stop.Do(func() {
+		close(wk.done)
+		wk.srv.Shutdown()
+		wk.client.Close()
+		wk.inspector.Close()
+	})
+}
+
+// sleep return false if worker is stopped
+func (wk Worker) sleep(d time.Duration) bool {
+	select {
+	case <-wk.done:
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
 func (wk Worker) Once(options ...func(*RunOptions)) (err error) {
 	ops := getRunOptionsOrSetDefault(nil)
 	for _, f := range options {
@@ -207,7 +244,7 @@ func (wk Worker) Once(options ...func(*RunOptions)) (err error) {
 		return
 	}
 	defer wk.lock.Unlock()
-	t := asynq.NewTask(strings.Join([]string{ops.group, "once"}, "."), []byte(ops.payload), asynq.TaskID(ops.uid))
+	t := asynq.NewTask(strings.Join([]string{ops.group, "once"}, "."), encodePayload(wk.inject(ops.ctx), ops.payload), asynq.TaskID(ops.uid))
 	taskOpts := []asynq.Option{
 		asynq.Queue(wk.ops.group),
 		asynq.MaxRetry(wk.ops.maxRetry),
@@ -269,6 +306,7 @@ func (wk Worker) Cron(options ...func(*RunOptions)) (err error) {
 		Group:    strings.Join([]string{ops.group, "cron"}, "."),
 		Uid:      ops.uid,
 		Payload:  ops.payload,
+		Header:   wk.inject(ops.ctx),
 		Next:     next,
 		MaxRetry: ops.maxRetry,
 		Timeout:  ops.timeout,
@@ -320,7 +358,7 @@ func (wk Worker) scan() {
 		var item periodTask
 		item.FromString(v)
 		next, _ := getNext(item.Expr, item.Next)
-		t := asynq.NewTask(item.Group, []byte(item.Payload), asynq.TaskID(item.Uid))
+		t := asynq.NewTask(item.Group, encodePayload(item.Header, item.Payload), asynq.TaskID(item.Uid))
 		taskOpts := []asynq.Option{
 			asynq.Queue(ops.group),
 			asynq.MaxRetry(ops.maxRetry),
@@ -397,8 +435,7 @@ func (wk Worker) getDefaultTimeoutCtx() context.Context {
 }
 
 func getNext(expr string, timestamp int64) (next int64, err error) {
-	var e *cronexpr.Expression
-	e, err = cronexpr.Parse(expr)
+	e, err := cron.Parse(expr)
 	if err != nil {
 		return
 	}
@@ -2,7 +2,9 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/alicebob/miniredis/v2"
 	"github.com/google/uuid"
 	"testing"
 	"time"
@@ -60,3 +62,58 @@ func TestWorker(t *testing.T) {
 
 	time.Sleep(time.Minute * 100)
 }
+
+func TestProgress(t *testing.T) {
+	s := miniredis.RunT(t)
+	done := make(chan error, 1)
+	wk := New(
+		WithRedisUri("redis://"+s.Addr()),
+		WithGroup("task.progress"),
+		WithRetention(60),
+		WithHandler(func(ctx context.Context, p Payload) error {
+			err := SetProgress(ctx, 1, 2, "half")
+			if err == nil {
+				err = SetProgress(ctx, 2, 2, p.Payload)
+			}
+			done <- err
+			return err
+		}),
+	)
+	if wk.Error != nil {
+		t.Fatal(wk.Error)
+	}
+	defer wk.Stop()
+	if err := SetProgress(context.Background(), 1, 1); !errors.Is(err, ErrNotInTask) {
+		t.Fatalf("expect not in task but got %v", err)
+	}
+	uid := uuid.NewString()
+	err := wk.Once(
+		WithRunUuid(uid),
+		WithRunGroup("progress"),
+		WithRunPayload("finished"),
+		WithRunNow(true),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err = <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("task is not processed")
+	}
+	// the result is kept after completed
+	var p Progress
+	for i := 0; i < 50; i++ {
+		p, err = wk.Progress(uid)
+		if err == nil && p.Done == 2 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	if err != nil || p.Done != 2 || p.Total != 2 || p.Message != "finished" || p.Time == 0 {
+		t.Fatalf("unexpected progress %+v, error %v", p, err)
+	}
+}
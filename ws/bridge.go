@@ -0,0 +1,65 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-cinch/common/log"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// envelope is the pub/sub message between instances
+type envelope struct {
+	Id    string   `json:"id"`
+	All   bool     `json:"all,omitempty"`
+	Users []string `json:"users,omitempty"`
+	Data  []byte   `json:"data"`
+}
+
+// subscribe receive messages from other instances and deliver to local connections
+func (h *Hub) subscribe() {
+	ctx := context.Background()
+	h.ps = h.ops.redis.Subscribe(ctx, h.ops.channel)
+	go func() {
+		for {
+			msg, err := h.ps.Receive(ctx)
+			if err != nil {
+				if err == redis.ErrClosed {
+					return
+				}
+				time.Sleep(time.Second)
+				continue
+			}
+			m, ok := msg.(*redis.Message)
+			if !ok {
+				continue
+			}
+			var item envelope
+			err = json.Unmarshal([]byte(m.Payload), &item)
+			if err != nil {
+				log.WithContext(ctx).WithError(err).Warn("invalid websocket hub message")
+				continue
+			}
+			// delivered locally before published
+			if item.Id == h.id {
+				continue
+			}
+			if !item.All && len(item.Users) == 0 {
+				continue
+			}
+			h.deliver(item.Data, item.Users...)
+		}
+	}()
+}
+
+func (h *Hub) publish(ctx context.Context, item envelope) {
+	if h.ps == nil {
+		return
+	}
+	item.Id = h.id
+	bs, _ := json.Marshal(item)
+	err := h.ops.redis.Publish(ctx, h.ops.channel, bs).Err()
+	if err != nil {
+		log.WithContext(ctx).WithError(err).Warn("publish websocket hub message failed")
+	}
+}
@@ -0,0 +1,126 @@
+package ws
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"sync"
+	"time"
+)
+
+// Conn is one websocket connection of user
+type Conn struct {
+	id     string
+	userId string
+	hub    *Hub
+	ws     *websocket.Conn
+	send   chan []byte
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+func newConn(h *Hub, ws *websocket.Conn, userId string) *Conn {
+	// request ctx will be canceled after upgraded
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Conn{
+		id:     uuid.NewString(),
+		userId: userId,
+		hub:    h,
+		ws:     ws,
+		send:   make(chan []byte, h.ops.sendBuffer),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (c *Conn) Id() string {
+	return c.id
+}
+
+func (c *Conn) UserId() string {
+	return c.userId
+}
+
+// Context is canceled when connection closed
+func (c *Conn) Context() context.Context {
+	return c.ctx
+}
+
+// Send push data to current connection, it never blocks, ErrSendTimeout will be returned if buffer is full
+func (c *Conn) Send(data []byte) (err error) {
+	select {
+	case <-c.ctx.Done():
+		err = ErrConnClosed
+		return
+	default:
+	}
+	select {
+	case c.send <- data:
+	case <-c.ctx.Done():
+		err = ErrConnClosed
+	default:
+		err = ErrSendTimeout
+	}
+	return
+}
+
+func (c *Conn) Close() {
+	c.once.Do(func() {
+		c.cancel()
+		_ = c.ws.Close()
+		c.hub.unregister(c)
+	})
+}
+
+func (c *Conn) readPump() {
+	defer c.Close()
+	ops := c.hub.ops
+	c.ws.SetReadLimit(ops.maxMessageSize)
+	_ = c.ws.SetReadDeadline(time.Now().Add(ops.pongTimeout))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(ops.pongTimeout))
+	})
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		// any message means the connection is alive
+		_ = c.ws.SetReadDeadline(time.Now().Add(ops.pongTimeout))
+		if ops.ping != "" && string(data) == ops.ping {
+			_ = c.Send([]byte(ops.pong))
+			continue
+		}
+		if ops.onMessage != nil {
+			ops.onMessage(c.ctx, c, data)
+		}
+	}
+}
+
+func (c *Conn) writePump() {
+	ops := c.hub.ops
+	ticker := time.NewTicker(ops.pingInterval)
+	defer func() {
+		ticker.Stop()
+		c.Close()
+	}()
+	for {
+		select {
+		case <-c.ctx.Done():
+			_ = c.ws.SetWriteDeadline(time.Now().Add(ops.writeTimeout))
+			_ = c.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		case data := <-c.send:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(ops.writeTimeout))
+			if err := c.ws.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			_ = c.ws.SetWriteDeadline(time.Now().Add(ops.writeTimeout))
+			if err := c.ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,10 @@
+package ws
+
+import "github.com/pkg/errors"
+
+var (
+	ErrUserIdNil   = errors.New("user id is empty")
+	ErrHubClosed   = errors.New("hub is closed")
+	ErrConnClosed  = errors.New("connection is closed")
+	ErrSendTimeout = errors.New("send buffer is full")
+)
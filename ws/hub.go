@@ -0,0 +1,207 @@
+package ws
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/go-cinch/common/log"
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"net/http"
+	"sync"
+)
+
+// Hub manage websocket connections of users, one user can have multiple connections(tabs/devices)
+type Hub struct {
+	ops      Options
+	id       string
+	upgrader websocket.Upgrader
+	lock     sync.RWMutex
+	users    map[string]map[*Conn]struct{}
+	closed   bool
+	ps       *redis.PubSub
+}
+
+func New(options ...func(*Options)) (h *Hub) {
+	ops := getOptionsOrSetDefault(nil)
+	for _, f := range options {
+		f(ops)
+	}
+	h = &Hub{
+		ops: *ops,
+		id:  instanceId(),
+		upgrader: websocket.Upgrader{
+			CheckOrigin: ops.checkOrigin,
+		},
+		users: make(map[string]map[*Conn]struct{}),
+	}
+	if ops.redis != nil {
+		h.subscribe()
+	}
+	return
+}
+
+// Handler upgrade http request, user is the authenticate func, return user id or error(401 will be responded)
+func (h *Hub) Handler(user func(r *http.Request) (string, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userId, err := user(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		_ = h.Serve(w, r, userId)
+	})
+}
+
+// Serve upgrade http request and register the connection to user, it returns when upgrade completed
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request, userId string) (err error) {
+	if userId == "" {
+		err = ErrUserIdNil
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	h.lock.RLock()
+	closed := h.closed
+	h.lock.RUnlock()
+	if closed {
+		err = ErrHubClosed
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// upgrader has replied error
+		err = errors.WithStack(err)
+		return
+	}
+	c := newConn(h, ws, userId)
+	h.register(c)
+	go c.writePump()
+	go c.readPump()
+	return
+}
+
+// Send push data to all connections of users on any instance
+func (h *Hub) Send(ctx context.Context, data []byte, userIds ...string) {
+	if len(userIds) == 0 {
+		return
+	}
+	h.deliver(data, userIds...)
+	h.publish(ctx, envelope{
+		Users: userIds,
+		Data:  data,
+	})
+}
+
+// Broadcast push data to all connections on any instance
+func (h *Hub) Broadcast(ctx context.Context, data []byte) {
+	h.deliver(data)
+	h.publish(ctx, envelope{
+		All:  true,
+		Data: data,
+	})
+}
+
+// Online check user has connection on current instance
+func (h *Hub) Online(userId string) bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return len(h.users[userId]) > 0
+}
+
+// Count get connection count on current instance
+func (h *Hub) Count() (rp int) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	for _, item := range h.users {
+		rp += len(item)
+	}
+	return
+}
+
+// Close stop bridge and close all connections
+func (h *Hub) Close() (err error) {
+	h.lock.Lock()
+	if h.closed {
+		h.lock.Unlock()
+		return
+	}
+	h.closed = true
+	conns := make([]*Conn, 0)
+	for _, item := range h.users {
+		for c := range item {
+			conns = append(conns, c)
+		}
+	}
+	h.lock.Unlock()
+	for _, c := range conns {
+		c.Close()
+	}
+	if h.ps != nil {
+		err = h.ps.Close()
+	}
+	return
+}
+
+// deliver send to local connections, all connections if userIds is empty
+func (h *Hub) deliver(data []byte, userIds ...string) {
+	h.lock.RLock()
+	conns := make([]*Conn, 0)
+	if len(userIds) == 0 {
+		for _, item := range h.users {
+			for c := range item {
+				conns = append(conns, c)
+			}
+		}
+	} else {
+		for _, id := range userIds {
+			for c := range h.users[id] {
+				conns = append(conns, c)
+			}
+		}
+	}
+	h.lock.RUnlock()
+	for _, c := range conns {
+		err := c.Send(data)
+		if errors.Is(err, ErrSendTimeout) {
+			log.WithError(err).WithFields(log.Fields{
+				"user": c.UserId(),
+				"conn": c.Id(),
+			}).Warn("slow websocket connection will be closed")
+			c.Close()
+		}
+	}
+}
+
+func (h *Hub) register(c *Conn) {
+	h.lock.Lock()
+	if h.users[c.userId] == nil {
+		h.users[c.userId] = make(map[*Conn]struct{})
+	}
+	h.users[c.userId][c] = struct{}{}
+	h.lock.Unlock()
+	if h.ops.onConnect != nil {
+		h.ops.onConnect(c.ctx, c)
+	}
+}
+
+func (h *Hub) unregister(c *Conn) {
+	h.lock.Lock()
+	if item, ok := h.users[c.userId]; ok {
+		delete(item, c)
+		if len(item) == 0 {
+			delete(h.users, c.userId)
+		}
+	}
+	h.lock.Unlock()
+	if h.ops.onDisconnect != nil {
+		h.ops.onDisconnect(c.ctx, c)
+	}
+}
+
+func instanceId() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
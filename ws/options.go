@@ -0,0 +1,140 @@
+package ws
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"net/http"
+	"time"
+)
+
+type Options struct {
+	redis          redis.UniversalClient
+	channel        string
+	pingInterval   time.Duration
+	pongTimeout    time.Duration
+	writeTimeout   time.Duration
+	sendBuffer     int
+	maxMessageSize int64
+	checkOrigin    func(r *http.Request) bool
+	ping           string
+	pong           string
+	onConnect      func(ctx context.Context, c *Conn)
+	onDisconnect   func(ctx context.Context, c *Conn)
+	onMessage      func(ctx context.Context, c *Conn, data []byte)
+}
+
+// WithRedis enable redis pub/sub bridge, messages will reach connections on any instance
+func WithRedis(rd redis.UniversalClient) func(*Options) {
+	return func(options *Options) {
+		if rd != nil {
+			getOptionsOrSetDefault(options).redis = rd
+		}
+	}
+}
+
+func WithChannel(channel string) func(*Options) {
+	return func(options *Options) {
+		if channel != "" {
+			getOptionsOrSetDefault(options).channel = channel
+		}
+	}
+}
+
+// WithPingInterval server send ping frame interval
+func WithPingInterval(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).pingInterval = time.Duration(second) * time.Second
+		}
+	}
+}
+
+// WithPongTimeout connection will be closed if no pong/message received in timeout, must be greater than ping interval
+func WithPongTimeout(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).pongTimeout = time.Duration(second) * time.Second
+		}
+	}
+}
+
+func WithWriteTimeout(second int) func(*Options) {
+	return func(options *Options) {
+		if second > 0 {
+			getOptionsOrSetDefault(options).writeTimeout = time.Duration(second) * time.Second
+		}
+	}
+}
+
+// WithSendBuffer buffered messages of each connection, slow connection will be closed if buffer is full
+func WithSendBuffer(size int) func(*Options) {
+	return func(options *Options) {
+		if size > 0 {
+			getOptionsOrSetDefault(options).sendBuffer = size
+		}
+	}
+}
+
+func WithMaxMessageSize(size int64) func(*Options) {
+	return func(options *Options) {
+		if size > 0 {
+			getOptionsOrSetDefault(options).maxMessageSize = size
+		}
+	}
+}
+
+func WithCheckOrigin(f func(r *http.Request) bool) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).checkOrigin = f
+		}
+	}
+}
+
+// WithHeartbeat application heartbeat for browsers can not send ping frame, reply pong text when receive ping text
+func WithHeartbeat(ping, pong string) func(*Options) {
+	return func(options *Options) {
+		getOptionsOrSetDefault(options).ping = ping
+		getOptionsOrSetDefault(options).pong = pong
+	}
+}
+
+func WithOnConnect(f func(ctx context.Context, c *Conn)) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).onConnect = f
+		}
+	}
+}
+
+func WithOnDisconnect(f func(ctx context.Context, c *Conn)) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).onDisconnect = f
+		}
+	}
+}
+
+func WithOnMessage(f func(ctx context.Context, c *Conn, data []byte)) func(*Options) {
+	return func(options *Options) {
+		if f != nil {
+			getOptionsOrSetDefault(options).onMessage = f
+		}
+	}
+}
+
+func getOptionsOrSetDefault(options *Options) *Options {
+	if options == nil {
+		return &Options{
+			channel:        "ws.hub",
+			pingInterval:   30 * time.Second,
+			pongTimeout:    60 * time.Second,
+			writeTimeout:   10 * time.Second,
+			sendBuffer:     256,
+			maxMessageSize: 64 * 1024,
+			ping:           "ping",
+			pong:           "pong",
+		}
+	}
+	return options
+}
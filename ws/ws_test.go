@@ -0,0 +1,137 @@
+package ws
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func dial(t *testing.T, srv *httptest.Server, user string) *websocket.Conn {
+	u := "ws" + strings.TrimPrefix(srv.URL, "http") + "?user=" + user
+	c, _, err := websocket.DefaultDialer.Dial(u, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func read(t *testing.T, c *websocket.Conn) string {
+	_ = c.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := c.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func serve(h *Hub) *httptest.Server {
+	return httptest.NewServer(h.Handler(func(r *http.Request) (string, error) {
+		return r.URL.Query().Get("user"), nil
+	}))
+}
+
+func waitCount(t *testing.T, h *Hub, n int) {
+	for i := 0; i < 100; i++ {
+		if h.Count() == n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("expect %d connections but got %d", n, h.Count())
+}
+
+func TestHub(t *testing.T) {
+	received := make(chan string, 1)
+	h := New(WithOnMessage(func(ctx context.Context, c *Conn, data []byte) {
+		received <- c.UserId() + ":" + string(data)
+	}))
+	defer h.Close()
+	srv := serve(h)
+	defer srv.Close()
+
+	// anonymous is rejected
+	_, rp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err == nil || rp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expect unauthorized but got %v", err)
+	}
+
+	u1a := dial(t, srv, "u1")
+	defer u1a.Close()
+	u1b := dial(t, srv, "u1")
+	defer u1b.Close()
+	u2 := dial(t, srv, "u2")
+	defer u2.Close()
+	waitCount(t, h, 3)
+	if !h.Online("u1") || h.Online("u3") {
+		t.Fatal("unexpected online status")
+	}
+
+	ctx := context.Background()
+	h.Send(ctx, []byte("to u1"), "u1")
+	if read(t, u1a) != "to u1" || read(t, u1b) != "to u1" {
+		t.Fatal("u1 should receive")
+	}
+	h.Broadcast(ctx, []byte("all"))
+	// u2 only receives broadcast
+	if read(t, u2) != "all" || read(t, u1a) != "all" {
+		t.Fatal("all should receive")
+	}
+
+	// heartbeat
+	_ = u2.WriteMessage(websocket.TextMessage, []byte("ping"))
+	if read(t, u2) != "pong" {
+		t.Fatal("expect pong")
+	}
+	_ = u2.WriteMessage(websocket.TextMessage, []byte("hello"))
+	if v := <-received; v != "u2:hello" {
+		t.Fatalf("unexpected message %s", v)
+	}
+
+	_ = u1b.Close()
+	waitCount(t, h, 2)
+	_ = h.Close()
+	waitCount(t, h, 0)
+}
+
+func TestHubBridge(t *testing.T) {
+	s := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: s.Addr()})
+	h1 := New(WithRedis(client))
+	defer h1.Close()
+	h2 := New(WithRedis(client))
+	defer h2.Close()
+	srv1 := serve(h1)
+	defer srv1.Close()
+	srv2 := serve(h2)
+	defer srv2.Close()
+
+	u1 := dial(t, srv1, "u1")
+	defer u1.Close()
+	u2 := dial(t, srv2, "u2")
+	defer u2.Close()
+	waitCount(t, h1, 1)
+	waitCount(t, h2, 1)
+	// wait subscriptions ready
+	time.Sleep(100 * time.Millisecond)
+
+	ctx := context.Background()
+	// u2 is connected to another instance
+	h1.Send(ctx, []byte("task done"), "u2")
+	if read(t, u2) != "task done" {
+		t.Fatal("u2 should receive")
+	}
+	h2.Broadcast(ctx, []byte("all"))
+	if read(t, u1) != "all" || read(t, u2) != "all" {
+		t.Fatal("all should receive once")
+	}
+	h1.Send(ctx, []byte("to u1"), "u1")
+	if read(t, u1) != "to u1" {
+		t.Fatal("u1 should receive once")
+	}
+}